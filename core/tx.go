@@ -24,12 +24,22 @@ type Tx struct {
 
 // BeginTx begin a transaction with option
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	return db.beginTx(ctx, opts, db.DB.BeginTx)
+}
+
+// BeginConnTx begins a transaction with option on the connection, it's for the statements
+// which should be executed on the same connection before or after the transaction
+func (db *DB) BeginConnTx(ctx context.Context, conn *sql.Conn, opts *sql.TxOptions) (*Tx, error) {
+	return db.beginTx(ctx, opts, conn.BeginTx)
+}
+
+func (db *DB) beginTx(ctx context.Context, opts *sql.TxOptions, begin func(context.Context, *sql.TxOptions) (*sql.Tx, error)) (*Tx, error) {
 	hookCtx := contexts.NewContextHook(ctx, "BEGIN TRANSACTION", nil)
 	ctx, err := db.beforeProcess(hookCtx)
 	if err != nil {
 		return nil, err
 	}
-	tx, err := db.DB.BeginTx(ctx, opts)
+	tx, err := begin(ctx, opts)
 	hookCtx.End(ctx, nil, err)
 	if err := db.afterProcess(hookCtx); err != nil {
 		return nil, err
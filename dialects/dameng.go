@@ -657,12 +657,31 @@ func (db *dameng) DropTableSQL(tableName string) (string, bool) {
 	return fmt.Sprintf("DROP TABLE %s", db.quoter.Quote(tableName)), false
 }
 
+// DropViewSQL returns drop view SQL
+func (db *dameng) DropViewSQL(viewName string, materialized bool) string {
+	return fmt.Sprintf("DROP VIEW %s", db.Quoter().Quote(viewName))
+}
+
 // ModifyColumnSQL returns a SQL to modify SQL
 func (db *dameng) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	s, _ := ColumnString(db.dialect, col, false, false)
 	return fmt.Sprintf("ALTER TABLE %s MODIFY %s", db.quoter.Quote(tableName), s)
 }
 
+func (db *dameng) AlterColumnTypeSQL(tableName string, col *schemas.Column) (string, error) {
+	quoter := db.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s MODIFY %s %s", quoter.Quote(tableName), quoter.Quote(col.Name), db.SQLType(col)), nil
+}
+
+func (db *dameng) AlterColumnNullableSQL(tableName string, col *schemas.Column) (string, error) {
+	quoter := db.Quoter()
+	nullable := "NOT NULL"
+	if col.Nullable {
+		nullable = "NULL"
+	}
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET %s", quoter.Quote(tableName), quoter.Quote(col.Name), nullable), nil
+}
+
 func (db *dameng) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
 	if tableName == "" {
 		tableName = table.Name
@@ -725,6 +744,9 @@ func (db *dameng) CreateTableSQL(ctx context.Context, queryer core.Queryer, tabl
 			return "", false, err
 		}
 	}
+	if _, err := b.WriteString(constraintsString(db, tableName, table)); err != nil {
+		return "", false, err
+	}
 	if _, err := b.WriteString(")"); err != nil {
 		return "", false, err
 	}
@@ -732,6 +754,25 @@ func (db *dameng) CreateTableSQL(ctx context.Context, queryer core.Queryer, tabl
 	return b.String(), false, nil
 }
 
+// GetChecks returns the CHECK constraints of the table except the NOT NULL ones generated by database
+func (db *dameng) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	s := "SELECT constraint_name, search_condition FROM user_constraints " +
+		"WHERE constraint_type = 'C' AND generated = 'USER NAME' AND table_name = ?"
+	return queryChecks(queryer, ctx, s, tableName)
+}
+
+// GetForeignKeys returns the foreign keys of the table
+func (db *dameng) GetForeignKeys(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.ForeignKey, error) {
+	s := "SELECT c.constraint_name, cc.column_name, rc.table_name, rcc.column_name, 'NO ACTION', c.delete_rule " +
+		"FROM user_constraints c " +
+		"INNER JOIN user_cons_columns cc ON cc.constraint_name = c.constraint_name " +
+		"INNER JOIN user_constraints rc ON rc.constraint_name = c.r_constraint_name " +
+		"INNER JOIN user_cons_columns rcc ON rcc.constraint_name = rc.constraint_name AND rcc.position = cc.position " +
+		"WHERE c.constraint_type = 'R' AND c.table_name = ? " +
+		"ORDER BY c.constraint_name, cc.position"
+	return queryForeignKeys(queryer, ctx, s, tableName)
+}
+
 func (db *dameng) SetQuotePolicy(quotePolicy QuotePolicy) {
 	switch quotePolicy {
 	case QuotePolicyNone:
@@ -1032,12 +1073,32 @@ func (db *dameng) GetTables(queryer core.Queryer, ctx context.Context) ([]*schem
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
-	return tables, nil
+
+	views, err := queryViews(queryer, ctx, false, "SELECT view_name, text FROM user_views")
+	if err != nil {
+		return nil, err
+	}
+	return append(tables, views...), nil
+}
+
+// CreateIndexSQL returns a SQL to create index, the index method could be BITMAP and
+// the predicate is ignored since Dameng has no partial indexes
+func (db *dameng) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	quoter := db.dialect.Quoter()
+	var kind string
+	if index.Type == schemas.UniqueType {
+		kind = " UNIQUE"
+	} else if strings.EqualFold(index.Method, "BITMAP") {
+		kind = " BITMAP"
+	}
+	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v)", kind,
+		quoter.Quote(index.XName(tableName)), quoter.Quote(tableName),
+		IndexKeysString(quoter, index))
 }
 
 func (db *dameng) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
 	args := []interface{}{tableName, tableName}
-	s := "SELECT t.column_name,i.uniqueness,i.index_name FROM user_ind_columns t,user_indexes i " +
+	s := "SELECT t.column_name,i.uniqueness,i.index_name,i.index_type FROM user_ind_columns t,user_indexes i " +
 		"WHERE t.index_name = i.index_name and t.table_name = i.table_name and t.table_name =?" +
 		" AND t.index_name not in (SELECT index_name FROM ALL_CONSTRAINTS WHERE CONSTRAINT_TYPE='P' AND table_name = ?)"
 
@@ -1050,9 +1111,9 @@ func (db *dameng) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 	indexes := make(map[string]*schemas.Index)
 	for rows.Next() {
 		var indexType int
-		var indexName, colName, uniqueness string
+		var indexName, colName, uniqueness, method string
 
-		err = rows.Scan(&colName, &uniqueness, &indexName)
+		err = rows.Scan(&colName, &uniqueness, &indexName, &method)
 		if err != nil {
 			return nil, err
 		}
@@ -1078,6 +1139,9 @@ func (db *dameng) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 			index.Type = indexType
 			index.Name = indexName
 			index.IsRegular = isRegular
+			if method == "BITMAP" {
+				index.Method = method
+			}
 			indexes[indexName] = index
 		}
 		index.AddColumn(colName)
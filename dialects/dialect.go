@@ -6,7 +6,10 @@ package dialects
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -47,6 +50,8 @@ const (
 // DialectFeatures represents a dialect parameters
 type DialectFeatures struct {
 	AutoincrMode int // 0 autoincrement column, 1 sequence
+	MaxParams    int // the max number of parameters in one statement, 0 means no limit
+	MaxRows      int // the max number of rows inserted by one statement, 0 means no limit
 }
 
 // Dialect represents a kind of database
@@ -75,6 +80,9 @@ type Dialect interface {
 	IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error)
 	CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error)
 	DropTableSQL(tableName string) (string, bool)
+	RenameTableSQL(oldName, newName string) string
+	CreateViewSQL(viewName string, view *schemas.Table) []string
+	DropViewSQL(viewName string, materialized bool) string
 
 	CreateSequenceSQL(ctx context.Context, queryer core.Queryer, seqName string) (string, error)
 	IsSequenceExist(ctx context.Context, queryer core.Queryer, seqName string) (bool, error)
@@ -84,11 +92,31 @@ type Dialect interface {
 	IsColumnExist(queryer core.Queryer, ctx context.Context, tableName string, colName string) (bool, error)
 	AddColumnSQL(tableName string, col *schemas.Column) string
 	ModifyColumnSQL(tableName string, col *schemas.Column) string
+	AlterColumnTypeSQL(tableName string, col *schemas.Column) (string, error)
+	AlterColumnNullableSQL(tableName string, col *schemas.Column) (string, error)
+	AlterColumnDefaultSQL(tableName string, col *schemas.Column) (string, error)
+	DropColumnSQL(tableName, colName string) string
+	RenameColumnSQL(tableName, oldName, newName string) string
+
+	GetForeignKeys(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.ForeignKey, error)
+	AddForeignKeySQL(tableName string, fk *schemas.ForeignKey) (string, error)
+	DropForeignKeySQL(tableName string, fk *schemas.ForeignKey) (string, error)
+
+	GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error)
+	AddCheckSQL(tableName string, check *schemas.Check) (string, error)
+	DropCheckSQL(tableName string, check *schemas.Check) (string, error)
 
 	Filters() []Filter
 	SetParams(params map[string]string)
 }
 
+var (
+	// ErrAlterColumnUnsupported represents the database cannot alter a column in place
+	ErrAlterColumnUnsupported = errors.New("alter column is not supported")
+	// ErrAlterConstraintUnsupported represents the database cannot add or drop a constraint of an existing table
+	ErrAlterConstraintUnsupported = errors.New("alter constraint is not supported")
+)
+
 // Base represents a basic dialect and all real dialects could embed this struct
 type Base struct {
 	dialect Dialect
@@ -147,6 +175,7 @@ func (db *Base) CreateTableSQL(ctx context.Context, queryer core.Queryer, table
 		b.WriteString(")")
 	}
 
+	b.WriteString(constraintsString(db.dialect, tableName, table))
 	b.WriteString(")")
 
 	return b.String(), false, nil
@@ -176,6 +205,19 @@ func (db *Base) DropTableSQL(tableName string) (string, bool) {
 	return fmt.Sprintf("DROP TABLE IF EXISTS %s", quote(tableName)), true
 }
 
+// CreateViewSQL returns the SQLs to create or replace the view, materialized views are
+// only supported by Postgres and will be created as normal views by other databases
+func (db *Base) CreateViewSQL(viewName string, view *schemas.Table) []string {
+	quote := db.dialect.Quoter().Quote
+	return []string{fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", quote(viewName), view.ViewDefinition)}
+}
+
+// DropViewSQL returns drop view SQL
+func (db *Base) DropViewSQL(viewName string, materialized bool) string {
+	quote := db.dialect.Quoter().Quote
+	return fmt.Sprintf("DROP VIEW IF EXISTS %s", quote(viewName))
+}
+
 // HasRecords returns true if the SQL has records returned
 func (db *Base) HasRecords(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (bool, error) {
 	rows, err := queryer.QueryContext(ctx, query, args...)
@@ -220,9 +262,16 @@ func (db *Base) CreateIndexSQL(tableName string, index *schemas.Index) string {
 		unique = " UNIQUE"
 	}
 	idxName = index.XName(tableName)
-	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v)", unique,
-		quoter.Quote(idxName), quoter.Quote(tableName),
-		quoter.Join(index.Cols, ","))
+	var using, where string
+	if index.Method != "" {
+		using = " USING " + index.Method
+	}
+	if index.Where != "" {
+		where = " WHERE " + index.Where
+	}
+	return fmt.Sprintf("CREATE%s INDEX %v ON %v%s (%v)%s", unique,
+		quoter.Quote(idxName), quoter.Quote(tableName), using,
+		IndexKeysString(quoter, index), where)
 }
 
 // DropIndexSQL returns a SQL to drop index
@@ -243,6 +292,78 @@ func (db *Base) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", db.quoter.Quote(tableName), s)
 }
 
+// AlterColumnTypeSQL returns a SQL to change the type of the column
+func (db *Base) AlterColumnTypeSQL(tableName string, col *schemas.Column) (string, error) {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s",
+		quoter.Quote(tableName), quoter.Quote(col.Name), db.dialect.SQLType(col)), nil
+}
+
+// AlterColumnNullableSQL returns a SQL to change the column to NULL or NOT NULL according col.Nullable
+func (db *Base) AlterColumnNullableSQL(tableName string, col *schemas.Column) (string, error) {
+	quoter := db.dialect.Quoter()
+	action := "SET NOT NULL"
+	if col.Nullable {
+		action = "DROP NOT NULL"
+	}
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s",
+		quoter.Quote(tableName), quoter.Quote(col.Name), action), nil
+}
+
+// AlterColumnDefaultSQL returns a SQL to set the default value of the column or drop it
+// if the column has no default
+func (db *Base) AlterColumnDefaultSQL(tableName string, col *schemas.Column) (string, error) {
+	quoter := db.dialect.Quoter()
+	action := "DROP DEFAULT"
+	if !col.DefaultIsEmpty {
+		action = "SET DEFAULT " + defaultValue(col)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s",
+		quoter.Quote(tableName), quoter.Quote(col.Name), action), nil
+}
+
+// DropColumnSQL returns a SQL to drop the column
+func (db *Base) DropColumnSQL(tableName, colName string) string {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoter.Quote(tableName), quoter.Quote(colName))
+}
+
+// RenameColumnSQL returns a SQL to rename the column
+func (db *Base) RenameColumnSQL(tableName, oldName, newName string) string {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", quoter.Quote(tableName), quoter.Quote(oldName), quoter.Quote(newName))
+}
+
+// RenameTableSQL returns a SQL to rename the table
+func (db *Base) RenameTableSQL(oldName, newName string) string {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoter.Quote(oldName), quoter.Quote(newName))
+}
+
+// AddForeignKeySQL returns a SQL to add the foreign key to the table
+func (db *Base) AddForeignKeySQL(tableName string, fk *schemas.ForeignKey) (string, error) {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s", db.dialect.Quoter().Quote(tableName),
+		ForeignKeyString(db.dialect, tableName, fk)), nil
+}
+
+// DropForeignKeySQL returns a SQL to drop the foreign key of the table
+func (db *Base) DropForeignKeySQL(tableName string, fk *schemas.ForeignKey) (string, error) {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", quoter.Quote(tableName), quoter.Quote(fk.XName(tableName))), nil
+}
+
+// AddCheckSQL returns a SQL to add the CHECK constraint to the table
+func (db *Base) AddCheckSQL(tableName string, check *schemas.Check) (string, error) {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s", db.dialect.Quoter().Quote(tableName),
+		CheckString(db.dialect, tableName, check)), nil
+}
+
+// DropCheckSQL returns a SQL to drop the CHECK constraint of the table
+func (db *Base) DropCheckSQL(tableName string, check *schemas.Check) (string, error) {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", quoter.Quote(tableName), quoter.Quote(check.XName(tableName))), nil
+}
+
 // SetParams set params
 func (db *Base) SetParams(params map[string]string) {
 }
@@ -310,6 +431,14 @@ func ColumnString(dialect Dialect, col *schemas.Column, includePrimaryKey, suppo
 		return "", err
 	}
 
+	// the type of a computed column of MSSQL is derived from the expression
+	if col.IsGenerated() && dialect.URI().DBType == schemas.MSSQL {
+		if _, err := bd.WriteString(generatedString(dialect, col)); err != nil {
+			return "", err
+		}
+		return bd.String(), nil
+	}
+
 	if _, err := bd.WriteString(dialect.SQLType(col)); err != nil {
 		return "", err
 	}
@@ -337,18 +466,19 @@ func ColumnString(dialect Dialect, col *schemas.Column, includePrimaryKey, suppo
 		}
 	}
 
-	if !col.DefaultIsEmpty {
+	if col.IsGenerated() {
+		if err := bd.WriteByte(' '); err != nil {
+			return "", err
+		}
+		if _, err := bd.WriteString(generatedString(dialect, col)); err != nil {
+			return "", err
+		}
+	} else if !col.DefaultIsEmpty {
 		if _, err := bd.WriteString(" DEFAULT "); err != nil {
 			return "", err
 		}
-		if col.Default == "" {
-			if _, err := bd.WriteString("''"); err != nil {
-				return "", err
-			}
-		} else {
-			if _, err := bd.WriteString(col.Default); err != nil {
-				return "", err
-			}
+		if _, err := bd.WriteString(defaultValue(col)); err != nil {
+			return "", err
 		}
 	}
 
@@ -364,3 +494,259 @@ func ColumnString(dialect Dialect, col *schemas.Column, includePrimaryKey, suppo
 
 	return bd.String(), nil
 }
+
+// generatedString returns the definition of a generated column according dialect
+func generatedString(dialect Dialect, col *schemas.Column) string {
+	switch dialect.URI().DBType {
+	case schemas.MSSQL:
+		if col.GeneratedStored {
+			if col.Nullable {
+				return fmt.Sprintf("AS (%s) PERSISTED", col.Generated)
+			}
+			return fmt.Sprintf("AS (%s) PERSISTED NOT NULL", col.Generated)
+		}
+		return fmt.Sprintf("AS (%s)", col.Generated)
+	case schemas.POSTGRES:
+		// only stored generated columns are supported
+		return fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", col.Generated)
+	case schemas.ORACLE, schemas.DAMENG:
+		// only virtual columns are supported
+		return fmt.Sprintf("GENERATED ALWAYS AS (%s) VIRTUAL", col.Generated)
+	}
+	if col.GeneratedStored {
+		return fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", col.Generated)
+	}
+	return fmt.Sprintf("GENERATED ALWAYS AS (%s) VIRTUAL", col.Generated)
+}
+
+// ForeignKeyString generate foreign key constraint description string according dialect
+func ForeignKeyString(dialect Dialect, tableName string, fk *schemas.ForeignKey) string {
+	quoter := dialect.Quoter()
+	var b strings.Builder
+	// the unnamed foreign keys read from database are kept unnamed
+	if fk.IsRegular || fk.Name != "" {
+		b.WriteString("CONSTRAINT ")
+		quoter.QuoteTo(&b, fk.XName(tableName))
+		b.WriteString(" ")
+	}
+	b.WriteString("FOREIGN KEY (")
+	b.WriteString(quoter.Join(fk.Cols, ","))
+	b.WriteString(") REFERENCES ")
+	quoter.QuoteTo(&b, fk.RefTable)
+	b.WriteString(" (")
+	b.WriteString(quoter.Join(fk.RefCols, ","))
+	b.WriteString(")")
+	if action := schemas.NormalizeAction(fk.OnDelete); action != schemas.NoAction {
+		b.WriteString(" ON DELETE ")
+		b.WriteString(action)
+	}
+	// Oracle and Dameng have no ON UPDATE clause
+	dbType := dialect.URI().DBType
+	if action := schemas.NormalizeAction(fk.OnUpdate); action != schemas.NoAction &&
+		dbType != schemas.ORACLE && dbType != schemas.DAMENG {
+		b.WriteString(" ON UPDATE ")
+		b.WriteString(action)
+	}
+	return b.String()
+}
+
+// IndexKeysString generate the keys of the index, the expressions will be wrapped with parentheses but not quoted
+func IndexKeysString(quoter schemas.Quoter, index *schemas.Index) string {
+	keys := make([]string, 0, len(index.Cols))
+	for _, col := range index.Cols {
+		var key string
+		if schemas.IsIndexExpr(col) {
+			key = "(" + col + ")"
+		} else {
+			key = quoter.Quote(col)
+		}
+		if index.IsDesc(col) {
+			key += " DESC"
+		}
+		keys = append(keys, key)
+	}
+	return strings.Join(keys, ",")
+}
+
+var (
+	indexUsingRegexp    = regexp.MustCompile(`(?i)\sUSING\s+(\w+)\s*$`)
+	indexWhereRegexp    = regexp.MustCompile(`(?is)^\s*WHERE\s+(.*?)[\s;]*$`)
+	indexKeyNullsRegexp = regexp.MustCompile(`(?i)\s+NULLS\s+(FIRST|LAST)$`)
+	indexKeyOrderRegexp = regexp.MustCompile(`(?i)\s+(ASC|DESC)$`)
+)
+
+// parseIndexSQL parses the method, keys and predicate from a CREATE INDEX statement into the index
+func parseIndexSQL(sql string, index *schemas.Index) {
+	onIdx := strings.Index(strings.ToUpper(sql), " ON ")
+	if onIdx < 0 {
+		return
+	}
+	start := strings.Index(sql[onIdx:], "(")
+	if start < 0 {
+		return
+	}
+	start += onIdx
+	if matches := indexUsingRegexp.FindStringSubmatch(sql[onIdx:start]); matches != nil {
+		if !strings.EqualFold(matches[1], "btree") {
+			index.Method = strings.ToUpper(matches[1])
+		}
+	}
+
+	end := len(sql)
+	var depth int
+	for i := start; i < len(sql); i++ {
+		if sql[i] == '(' {
+			depth++
+		} else if sql[i] == ')' {
+			depth--
+			if depth == 0 {
+				end = i
+				break
+			}
+		}
+	}
+
+	index.Cols = make([]string, 0)
+	for _, key := range splitTableDefinitions(sql[start+1 : end]) {
+		key = indexKeyNullsRegexp.ReplaceAllString(strings.TrimSpace(key), "")
+		var desc bool
+		if matches := indexKeyOrderRegexp.FindStringSubmatch(key); matches != nil {
+			desc = strings.EqualFold(matches[1], "DESC")
+			key = key[:len(key)-len(matches[0])]
+		}
+		if col := strings.Trim(key, "`[]\""); !schemas.IsIndexExpr(col) {
+			key = col
+		} else {
+			key = schemas.TrimOuterParens(key)
+		}
+		if desc {
+			index.AddDescColumn(key)
+		} else {
+			index.AddColumn(key)
+		}
+	}
+
+	if end < len(sql) {
+		if matches := indexWhereRegexp.FindStringSubmatch(sql[end+1:]); matches != nil {
+			index.Where = schemas.TrimOuterParens(matches[1])
+		}
+	}
+}
+
+var viewPrefixRegexp = regexp.MustCompile(`(?is)^\s*CREATE\s+.*?\bVIEW\s+.+?\s+AS\s+`)
+
+// queryViews reads the views from the query which returns the view name and definition, the
+// definition could be the SELECT statement or the whole CREATE VIEW statement
+func queryViews(queryer core.Queryer, ctx context.Context, materialized bool, query string, args ...interface{}) ([]*schemas.Table, error) {
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := make([]*schemas.Table, 0)
+	for rows.Next() {
+		var name string
+		var definition sql.NullString
+		if err = rows.Scan(&name, &definition); err != nil {
+			return nil, err
+		}
+		view := schemas.NewEmptyTable()
+		view.Name = name
+		view.IsView = true
+		view.IsMaterialized = materialized
+		view.ViewDefinition = strings.TrimRight(strings.TrimSpace(viewPrefixRegexp.ReplaceAllString(definition.String, "")), ";")
+		views = append(views, view)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return views, nil
+}
+
+// CheckString generate CHECK constraint description string according dialect
+func CheckString(dialect Dialect, tableName string, check *schemas.Check) string {
+	// the unnamed checks read from database are kept unnamed
+	if !check.IsRegular && check.Name == "" {
+		return fmt.Sprintf("CHECK (%s)", check.Expr)
+	}
+	return fmt.Sprintf("CONSTRAINT %s CHECK (%s)", dialect.Quoter().Quote(check.XName(tableName)), check.Expr)
+}
+
+// constraintsString returns the foreign key and CHECK constraints of the table which could
+// be appended to the column definitions of CREATE TABLE
+func constraintsString(dialect Dialect, tableName string, table *schemas.Table) string {
+	var b strings.Builder
+	for _, name := range table.ForeignKeyNames() {
+		b.WriteString(", ")
+		b.WriteString(ForeignKeyString(dialect, tableName, table.ForeignKeys[name]))
+	}
+	for _, name := range table.CheckNames() {
+		b.WriteString(", ")
+		b.WriteString(CheckString(dialect, tableName, table.Checks[name]))
+	}
+	return b.String()
+}
+
+// queryChecks reads the CHECK constraints from the query which returns the constraint name and expression
+func queryChecks(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (map[string]*schemas.Check, error) {
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make(map[string]*schemas.Check)
+	for rows.Next() {
+		var name, expr string
+		if err := rows.Scan(&name, &expr); err != nil {
+			return nil, err
+		}
+		check := schemas.NewCheck(name, expr)
+		check.IsRegular = false
+		checks[name] = check
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return checks, nil
+}
+
+// queryForeignKeys reads the foreign keys from the query which returns the constraint name, column,
+// referenced table, referenced column, update action and delete action ordered by the column position
+func queryForeignKeys(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (map[string]*schemas.ForeignKey, error) {
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fks := make(map[string]*schemas.ForeignKey)
+	for rows.Next() {
+		var name, colName, refTable, refColName, onUpdate, onDelete string
+		if err := rows.Scan(&name, &colName, &refTable, &refColName, &onUpdate, &onDelete); err != nil {
+			return nil, err
+		}
+		fk, ok := fks[name]
+		if !ok {
+			fk = schemas.NewForeignKey(name, refTable)
+			fk.IsRegular = false
+			fk.OnUpdate = schemas.NormalizeAction(onUpdate)
+			fk.OnDelete = schemas.NormalizeAction(onDelete)
+			fks[name] = fk
+		}
+		fk.AddColumn(colName, refColName)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return fks, nil
+}
+
+// defaultValue returns the default value of the column used in SQL
+func defaultValue(col *schemas.Column) string {
+	if col.Default == "" {
+		return "''"
+	}
+	return col.Default
+}
@@ -0,0 +1,322 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm/schemas"
+)
+
+func newTestDialect(t *testing.T, dbType schemas.DBType) Dialect {
+	dialect := QueryDialect(dbType)
+	assert.NotNil(t, dialect)
+	assert.NoError(t, dialect.Init(&URI{DBType: dbType}))
+	return dialect
+}
+
+func TestAlterColumnSQL(t *testing.T) {
+	col := schemas.NewColumn("name", "Name", schemas.SQLType{Name: schemas.Varchar}, 255, 0, false)
+	col.Default = "'a'"
+	col.DefaultIsEmpty = false
+
+	postgres := newTestDialect(t, schemas.POSTGRES)
+	sql, err := postgres.AlterColumnNullableSQL("user", col)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `ALTER TABLE "public"."user" ALTER COLUMN "name" SET NOT NULL`, sql)
+	sql, err = postgres.AlterColumnDefaultSQL("user", col)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `ALTER TABLE "public"."user" ALTER COLUMN "name" SET DEFAULT 'a'`, sql)
+	sql, err = postgres.AlterColumnTypeSQL("user", col)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `ALTER TABLE "public"."user" ALTER COLUMN "name" TYPE VARCHAR(255)`, sql)
+
+	mysql := newTestDialect(t, schemas.MYSQL)
+	sql, err = mysql.AlterColumnNullableSQL("user", col)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "ALTER TABLE `user` MODIFY COLUMN `name` VARCHAR(255) DEFAULT 'a' NOT NULL", sql)
+
+	col.Nullable = true
+	col.DefaultIsEmpty = true
+	sql, err = mysql.AlterColumnDefaultSQL("user", col)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "ALTER TABLE `user` ALTER COLUMN `name` DROP DEFAULT", sql)
+
+	mssql := newTestDialect(t, schemas.MSSQL)
+	sql, err = mssql.AlterColumnNullableSQL("user", col)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "ALTER TABLE [user] ALTER COLUMN [name] VARCHAR(255) NULL", sql)
+
+	oracle := newTestDialect(t, schemas.ORACLE)
+	sql, err = oracle.AlterColumnDefaultSQL("user", col)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `ALTER TABLE "user" MODIFY ("name" DEFAULT NULL)`, sql)
+
+	sqlite := newTestDialect(t, schemas.SQLITE)
+	_, err = sqlite.AlterColumnNullableSQL("user", col)
+	assert.ErrorIs(t, err, ErrAlterColumnUnsupported)
+}
+
+func TestDropColumnSQL(t *testing.T) {
+	assert.EqualValues(t, `ALTER TABLE "public"."user" DROP COLUMN "name"`,
+		newTestDialect(t, schemas.POSTGRES).DropColumnSQL("user", "name"))
+	assert.EqualValues(t, "ALTER TABLE `user` DROP COLUMN `name`",
+		newTestDialect(t, schemas.MYSQL).DropColumnSQL("user", "name"))
+	assert.EqualValues(t, "ALTER TABLE `user` DROP COLUMN `name`",
+		newTestDialect(t, schemas.SQLITE).DropColumnSQL("user", "name"))
+	assert.Contains(t, newTestDialect(t, schemas.MSSQL).DropColumnSQL("user", "name"),
+		"ALTER TABLE [user] DROP COLUMN [name]")
+}
+
+func TestForeignKeySQL(t *testing.T) {
+	table := schemas.NewEmptyTable()
+	table.Name = "order"
+	table.AddColumn(schemas.NewColumn("id", "Id", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false))
+	table.AddColumn(schemas.NewColumn("user_id", "UserId", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false))
+	fk := schemas.NewForeignKey("user_id", "user")
+	fk.AddColumn("user_id", "id")
+	fk.OnDelete = schemas.Cascade
+	fk.OnUpdate = schemas.SetNull
+	table.AddForeignKey(fk)
+
+	sqlite := newTestDialect(t, schemas.SQLITE)
+	sql, _, err := sqlite.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "CREATE TABLE IF NOT EXISTS `order` (`id` INTEGER NOT NULL, `user_id` INTEGER NOT NULL, "+
+		"CONSTRAINT `FK_order_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE ON UPDATE SET NULL)", sql)
+	_, err = sqlite.AddForeignKeySQL("order", fk)
+	assert.ErrorIs(t, err, ErrAlterConstraintUnsupported)
+
+	mysql := newTestDialect(t, schemas.MYSQL)
+	sql, err = mysql.AddForeignKeySQL("order", fk)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "ALTER TABLE `order` ADD CONSTRAINT `FK_order_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE ON UPDATE SET NULL", sql)
+	sql, err = mysql.DropForeignKeySQL("order", fk)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "ALTER TABLE `order` DROP FOREIGN KEY `FK_order_user_id`", sql)
+
+	postgres := newTestDialect(t, schemas.POSTGRES)
+	sql, err = postgres.DropForeignKeySQL("order", fk)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `ALTER TABLE "public"."order" DROP CONSTRAINT "FK_order_user_id"`, sql)
+
+	oracle := newTestDialect(t, schemas.ORACLE)
+	sql, err = oracle.AddForeignKeySQL("order", fk)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `ALTER TABLE "order" ADD CONSTRAINT "FK_order_user_id" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE`, sql)
+}
+
+func TestCheckSQL(t *testing.T) {
+	table := schemas.NewEmptyTable()
+	table.Name = "order"
+	table.AddColumn(schemas.NewColumn("price", "Price", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false))
+	check := schemas.NewCheck("price", "price >= 0")
+	table.AddCheck(check)
+
+	sqlite := newTestDialect(t, schemas.SQLITE)
+	sql, _, err := sqlite.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "CREATE TABLE IF NOT EXISTS `order` (`price` INTEGER NOT NULL, "+
+		"CONSTRAINT `CHK_order_price` CHECK (price >= 0))", sql)
+	_, err = sqlite.DropCheckSQL("order", check)
+	assert.ErrorIs(t, err, ErrAlterConstraintUnsupported)
+
+	mysql := newTestDialect(t, schemas.MYSQL)
+	sql, err = mysql.AddCheckSQL("order", check)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "ALTER TABLE `order` ADD CONSTRAINT `CHK_order_price` CHECK (price >= 0)", sql)
+	sql, err = mysql.DropCheckSQL("order", check)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "ALTER TABLE `order` DROP CHECK `CHK_order_price`", sql)
+
+	postgres := newTestDialect(t, schemas.POSTGRES)
+	sql, err = postgres.DropCheckSQL("order", check)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `ALTER TABLE "public"."order" DROP CONSTRAINT "CHK_order_price"`, sql)
+}
+
+func TestCreateIndexSQL(t *testing.T) {
+	index := schemas.NewIndex("email", schemas.IndexType)
+	index.AddColumn("tenant_id", "lower(email)")
+	index.AddDescColumn("created")
+	index.Where = "deleted IS NULL"
+
+	assert.EqualValues(t, "CREATE INDEX `IDX_user_email` ON `user` (`tenant_id`,(lower(email)),`created` DESC) WHERE deleted IS NULL",
+		newTestDialect(t, schemas.SQLITE).CreateIndexSQL("user", index))
+	assert.EqualValues(t, "CREATE INDEX `IDX_user_email` ON `user` (`tenant_id`,(lower(email)),`created` DESC)",
+		newTestDialect(t, schemas.MYSQL).CreateIndexSQL("user", index))
+
+	index.Method = "gin"
+	assert.EqualValues(t, `CREATE INDEX "IDX_user_email" ON "user" USING gin ("tenant_id",(lower(email)),"created" DESC) WHERE deleted IS NULL`,
+		newTestDialect(t, schemas.POSTGRES).CreateIndexSQL("user", index))
+	assert.EqualValues(t, "CREATE INDEX `IDX_user_email` ON `user` (`tenant_id`,(lower(email)),`created` DESC) WHERE deleted IS NULL",
+		newTestDialect(t, schemas.SQLITE).CreateIndexSQL("user", index))
+
+	fulltext := schemas.NewIndex("content", schemas.IndexType)
+	fulltext.AddColumn("content")
+	fulltext.Method = "FULLTEXT"
+	assert.EqualValues(t, "CREATE FULLTEXT INDEX `IDX_post_content` ON `post` (`content`)",
+		newTestDialect(t, schemas.MYSQL).CreateIndexSQL("post", fulltext))
+	fulltext.Method = "HASH"
+	assert.EqualValues(t, "CREATE INDEX `IDX_post_content` ON `post` (`content`) USING HASH",
+		newTestDialect(t, schemas.MYSQL).CreateIndexSQL("post", fulltext))
+
+	filtered := schemas.NewIndex("name", schemas.UniqueType)
+	filtered.AddColumn("name")
+	filtered.Where = "deleted IS NULL"
+	assert.EqualValues(t, "CREATE UNIQUE INDEX [UQE_user_name] ON [user] ([name]) WHERE deleted IS NULL",
+		newTestDialect(t, schemas.MSSQL).CreateIndexSQL("user", filtered))
+
+	bitmap := schemas.NewIndex("status", schemas.IndexType)
+	bitmap.AddColumn("status")
+	bitmap.Method = "BITMAP"
+	assert.EqualValues(t, `CREATE BITMAP INDEX "IDX_user_status" ON "user" ("status")`,
+		newTestDialect(t, schemas.ORACLE).CreateIndexSQL("user", bitmap))
+}
+
+func TestRenameSQL(t *testing.T) {
+	assert.EqualValues(t, "ALTER TABLE `user` RENAME COLUMN `name` TO `nickname`",
+		newTestDialect(t, schemas.MYSQL).RenameColumnSQL("user", "name", "nickname"))
+	col := schemas.NewColumn("nickname", "Nickname", schemas.SQLType{Name: schemas.Varchar}, 20, 0, true)
+	assert.EqualValues(t, "ALTER TABLE `user` CHANGE COLUMN `name` `nickname` VARCHAR(20) NULL",
+		newTestDialect(t, schemas.MYSQL).(*mysql).ChangeColumnSQL("user", "name", col))
+	assert.EqualValues(t, "ALTER TABLE `user` RENAME TO `member`",
+		newTestDialect(t, schemas.SQLITE).RenameTableSQL("user", "member"))
+	assert.EqualValues(t, `ALTER TABLE "public"."user" RENAME COLUMN "name" TO "nickname"`,
+		newTestDialect(t, schemas.POSTGRES).RenameColumnSQL("user", "name", "nickname"))
+	assert.EqualValues(t, `ALTER TABLE "public"."user" RENAME TO "member"`,
+		newTestDialect(t, schemas.POSTGRES).RenameTableSQL("user", "public.member"))
+	assert.EqualValues(t, "EXEC sp_rename 'user.name', 'nickname', 'COLUMN'",
+		newTestDialect(t, schemas.MSSQL).RenameColumnSQL("user", "name", "nickname"))
+	assert.EqualValues(t, "EXEC sp_rename 'user', 'member'",
+		newTestDialect(t, schemas.MSSQL).RenameTableSQL("user", "member"))
+}
+
+func TestViewSQL(t *testing.T) {
+	view := schemas.NewEmptyTable()
+	view.IsView = true
+	view.ViewDefinition = "SELECT id FROM user"
+
+	assert.EqualValues(t, []string{"DROP VIEW IF EXISTS `active_user`", "CREATE VIEW `active_user` AS SELECT id FROM user"},
+		newTestDialect(t, schemas.SQLITE).CreateViewSQL("active_user", view))
+	assert.EqualValues(t, []string{"CREATE OR REPLACE VIEW `active_user` AS SELECT id FROM user"},
+		newTestDialect(t, schemas.MYSQL).CreateViewSQL("active_user", view))
+	assert.EqualValues(t, []string{"CREATE OR ALTER VIEW [active_user] AS SELECT id FROM user"},
+		newTestDialect(t, schemas.MSSQL).CreateViewSQL("active_user", view))
+	assert.EqualValues(t, "DROP VIEW IF EXISTS `active_user`",
+		newTestDialect(t, schemas.MYSQL).DropViewSQL("active_user", false))
+
+	pg := newTestDialect(t, schemas.POSTGRES)
+	assert.EqualValues(t, []string{`CREATE OR REPLACE VIEW "public"."active_user" AS SELECT id FROM user`},
+		pg.CreateViewSQL("active_user", view))
+	view.IsMaterialized = true
+	assert.EqualValues(t, []string{`CREATE MATERIALIZED VIEW IF NOT EXISTS "public"."active_user" AS SELECT id FROM user`},
+		pg.CreateViewSQL("active_user", view))
+	assert.EqualValues(t, `DROP MATERIALIZED VIEW IF EXISTS "public"."active_user"`,
+		pg.DropViewSQL("active_user", true))
+	assert.EqualValues(t, `REFRESH MATERIALIZED VIEW CONCURRENTLY "public"."active_user"`,
+		pg.(*postgres).RefreshMaterializedViewSQL("active_user", true))
+}
+
+func TestGeneratedColumnSQL(t *testing.T) {
+	col := schemas.NewColumn("total", "Total", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false)
+	col.Generated = "price * qty"
+	col.GeneratedStored = true
+
+	s, err := ColumnString(newTestDialect(t, schemas.MYSQL), col, false, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "`total` BIGINT(20) GENERATED ALWAYS AS (price * qty) STORED NOT NULL", s)
+	s, err = ColumnString(newTestDialect(t, schemas.POSTGRES), col, false, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `"total" BIGINT GENERATED ALWAYS AS (price * qty) STORED NOT NULL`, s)
+	s, err = ColumnString(newTestDialect(t, schemas.MSSQL), col, false, true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "[total] AS (price * qty) PERSISTED NOT NULL", s)
+
+	col.GeneratedStored = false
+	col.Nullable = true
+	s, err = ColumnString(newTestDialect(t, schemas.SQLITE), col, false, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "`total` INTEGER GENERATED ALWAYS AS (price * qty) VIRTUAL NULL", s)
+	s, err = ColumnString(newTestDialect(t, schemas.MSSQL), col, false, true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "[total] AS (price * qty)", s)
+	s, err = ColumnString(newTestDialect(t, schemas.ORACLE), col, false, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `"total" NUMBER(20) GENERATED ALWAYS AS (price * qty) VIRTUAL NULL`, s)
+}
+
+func TestPartitionSQL(t *testing.T) {
+	table := schemas.NewEmptyTable()
+	table.Name = "event"
+	table.AddColumn(schemas.NewColumn("id", "Id", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false))
+	table.AddColumn(schemas.NewColumn("created", "Created", schemas.SQLType{Name: schemas.DateTime}, 0, 0, false))
+	table.Partitioning = schemas.NewPartitioning(schemas.RangePartition, "created")
+	table.Partitioning.AddPartition(schemas.NewRangePartition("event_2023", "'2023-01-01'", "'2024-01-01'"))
+
+	pg := newTestDialect(t, schemas.POSTGRES)
+	sql, _, err := pg.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `CREATE TABLE IF NOT EXISTS "public"."event" ("id" BIGINT NOT NULL, "created" TIMESTAMP NOT NULL) PARTITION BY RANGE ("created"); `+
+		`CREATE TABLE IF NOT EXISTS "public"."event_2023" PARTITION OF "public"."event" FOR VALUES FROM ('2023-01-01') TO ('2024-01-01'); `, sql)
+
+	partitionPG := pg.(PartitionDialect)
+	assert.EqualValues(t, `CREATE TABLE IF NOT EXISTS "public"."event_1" PARTITION OF "public"."event" FOR VALUES IN (1, 2)`,
+		partitionPG.CreatePartitionSQL("event", schemas.NewListPartition("event_1", "1", "2")))
+	assert.EqualValues(t, []string{`ALTER TABLE "public"."event" ATTACH PARTITION "public"."event_0" FOR VALUES WITH (MODULUS 4, REMAINDER 0)`},
+		partitionPG.AttachPartitionSQL("event", schemas.NewHashPartition("event_0", 4, 0)))
+	assert.EqualValues(t, []string{`ALTER TABLE "public"."event" DETACH PARTITION "public"."event_0"`},
+		partitionPG.DetachPartitionSQL("event", "event_0"))
+
+	mysql := newTestDialect(t, schemas.MYSQL)
+	sql, _, err = mysql.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "CREATE TABLE IF NOT EXISTS `event` (`id` BIGINT(20) NOT NULL, `created` DATETIME NOT NULL) "+
+		"PARTITION BY RANGE COLUMNS(`created`) (PARTITION `event_2023` VALUES LESS THAN ('2024-01-01'))", sql)
+
+	partitionMySQL := mysql.(PartitionDialect)
+	assert.EqualValues(t, []string{
+		"ALTER TABLE `event` ADD PARTITION (PARTITION `event_2024` VALUES LESS THAN (MAXVALUE))",
+		"ALTER TABLE `event` EXCHANGE PARTITION `event_2024` WITH TABLE `event_2024`",
+		"DROP TABLE `event_2024`",
+	}, partitionMySQL.AttachPartitionSQL("event", schemas.NewRangePartition("event_2024", "", "MAXVALUE")))
+	assert.EqualValues(t, []string{
+		"CREATE TABLE `event_2023` LIKE `event`",
+		"ALTER TABLE `event_2023` REMOVE PARTITIONING",
+		"ALTER TABLE `event` EXCHANGE PARTITION `event_2023` WITH TABLE `event_2023`",
+		"ALTER TABLE `event` DROP PARTITION `event_2023`",
+	}, partitionMySQL.DetachPartitionSQL("event", "event_2023"))
+
+	table.Partitioning = schemas.NewPartitioning(schemas.HashPartition, "year(created)")
+	sql, _, err = mysql.CreateTableSQL(context.Background(), nil, table, "")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "CREATE TABLE IF NOT EXISTS `event` (`id` BIGINT(20) NOT NULL, `created` DATETIME NOT NULL) "+
+		"PARTITION BY HASH ((year(created)))", sql)
+
+	_, ok := newTestDialect(t, schemas.SQLITE).(PartitionDialect)
+	assert.False(t, ok)
+}
+
+func TestLockSQL(t *testing.T) {
+	pg := newTestDialect(t, schemas.POSTGRES).(LockDialect)
+	query, args := pg.TryLockSQL("sync")
+	assert.EqualValues(t, "SELECT CASE WHEN pg_try_advisory_lock($1) THEN 1 ELSE 0 END", query)
+	_, unlockArgs := pg.UnlockSQL("sync")
+	assert.EqualValues(t, args, unlockArgs)
+	_, otherArgs := pg.TryLockSQL("migrate")
+	assert.NotEqualValues(t, args, otherArgs)
+
+	query, args = newTestDialect(t, schemas.MYSQL).(LockDialect).TryLockSQL("sync")
+	assert.EqualValues(t, "SELECT COALESCE(GET_LOCK(?, 0), 0)", query)
+	assert.EqualValues(t, []interface{}{"sync"}, args)
+
+	query, _ = newTestDialect(t, schemas.MSSQL).(LockDialect).UnlockSQL("sync")
+	assert.EqualValues(t, "EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'", query)
+
+	_, ok := newTestDialect(t, schemas.SQLITE).(LockDialect)
+	assert.False(t, ok)
+}
@@ -0,0 +1,27 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import "hash/fnv"
+
+// LockDialect represents a dialect supports named locks held by a connection, i.e. Postgres,
+// MySQL and MSSQL. The other dialects could use a lock table instead. The SQLs are executed on
+// the connection directly without the filters, so they should use the native placeholders.
+type LockDialect interface {
+	Dialect
+
+	// TryLockSQL returns a query to acquire the named lock without waiting,
+	// the query returns 1 if the lock is acquired, otherwise 0
+	TryLockSQL(name string) (string, []interface{})
+	// UnlockSQL returns a SQL to release the named lock
+	UnlockSQL(name string) (string, []interface{})
+}
+
+// lockKey returns the integer key of the named lock for the databases lock by numbers
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -295,6 +295,8 @@ func (db *mssql) Version(ctx context.Context, queryer core.Queryer) (*schemas.Ve
 func (db *mssql) Features() *DialectFeatures {
 	return &DialectFeatures{
 		AutoincrMode: IncrAutoincrMode,
+		MaxParams:    2098, // 2100 parameters of sp_executesql including the statement and the definitions
+		MaxRows:      1000, // the max number of row value expressions
 	}
 }
 
@@ -433,11 +435,86 @@ func (db *mssql) DropTableSQL(tableName string) (string, bool) {
 		"DROP TABLE \"%s\"", tableName, tableName), true
 }
 
+// CreateViewSQL returns the SQLs to create or alter the view
+func (db *mssql) CreateViewSQL(viewName string, view *schemas.Table) []string {
+	return []string{fmt.Sprintf("CREATE OR ALTER VIEW %s AS %s", db.Quoter().Quote(viewName), view.ViewDefinition)}
+}
+
+// DropViewSQL returns drop view SQL
+func (db *mssql) DropViewSQL(viewName string, materialized bool) string {
+	return fmt.Sprintf("IF OBJECT_ID(N'%s', N'V') IS NOT NULL DROP VIEW %s", viewName, db.Quoter().Quote(viewName))
+}
+
 func (db *mssql) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	s, _ := ColumnString(db.dialect, col, false, true)
 	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", db.quoter.Quote(tableName), s)
 }
 
+func (db *mssql) alterColumnSQL(tableName string, col *schemas.Column) string {
+	quoter := db.dialect.Quoter()
+	var b strings.Builder
+	b.WriteString("ALTER TABLE ")
+	quoter.QuoteTo(&b, tableName)
+	b.WriteString(" ALTER COLUMN ")
+	quoter.QuoteTo(&b, col.Name)
+	b.WriteString(" ")
+	b.WriteString(db.SQLType(col))
+	if col.Collation != "" {
+		b.WriteString(" COLLATE ")
+		b.WriteString(col.Collation)
+	}
+	if col.Nullable {
+		b.WriteString(" NULL")
+	} else {
+		b.WriteString(" NOT NULL")
+	}
+	return b.String()
+}
+
+func (db *mssql) AlterColumnTypeSQL(tableName string, col *schemas.Column) (string, error) {
+	return db.alterColumnSQL(tableName, col), nil
+}
+
+func (db *mssql) AlterColumnNullableSQL(tableName string, col *schemas.Column) (string, error) {
+	return db.alterColumnSQL(tableName, col), nil
+}
+
+// AlterColumnDefaultSQL returns a SQL to change the default of the column, defaults are
+// constraints on MSSQL so the old one has to be dropped by its generated name at first.
+func (db *mssql) AlterColumnDefaultSQL(tableName string, col *schemas.Column) (string, error) {
+	quoter := db.dialect.Quoter()
+	sql := db.dropDefaultConstraintSQL(tableName, col.Name)
+	if !col.DefaultIsEmpty {
+		sql += fmt.Sprintf("; ALTER TABLE %s ADD DEFAULT %s FOR %s",
+			quoter.Quote(tableName), defaultValue(col), quoter.Quote(col.Name))
+	}
+	return sql, nil
+}
+
+func (db *mssql) dropDefaultConstraintSQL(tableName, colName string) string {
+	return fmt.Sprintf("DECLARE @constraint sysname; "+
+		"SELECT @constraint = d.name FROM sys.default_constraints d "+
+		"JOIN sys.columns c ON d.parent_object_id = c.object_id AND d.parent_column_id = c.column_id "+
+		"WHERE d.parent_object_id = OBJECT_ID('%s') AND c.name = '%s'; "+
+		"IF @constraint IS NOT NULL EXEC('ALTER TABLE %s DROP CONSTRAINT ' + @constraint)",
+		tableName, colName, db.dialect.Quoter().Quote(tableName))
+}
+
+// DropColumnSQL returns a SQL to drop the column with its default constraint
+func (db *mssql) DropColumnSQL(tableName, colName string) string {
+	return db.dropDefaultConstraintSQL(tableName, colName) + "; " + db.Base.DropColumnSQL(tableName, colName)
+}
+
+// RenameColumnSQL returns a SQL to rename the column via sp_rename
+func (db *mssql) RenameColumnSQL(tableName, oldName, newName string) string {
+	return fmt.Sprintf("EXEC sp_rename '%s.%s', '%s', 'COLUMN'", tableName, oldName, newName)
+}
+
+// RenameTableSQL returns a SQL to rename the table via sp_rename
+func (db *mssql) RenameTableSQL(oldName, newName string) string {
+	return fmt.Sprintf("EXEC sp_rename '%s', '%s'", oldName, newName)
+}
+
 func (db *mssql) IndexCheckSQL(tableName, idxName string) (string, []interface{}) {
 	args := []interface{}{idxName}
 	sql := "select name from sysindexes where id=object_id('" + tableName + "') and name=?"
@@ -460,10 +537,12 @@ func (db *mssql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 	s := `select a.name as name, b.name as ctype,a.max_length,a.precision,a.scale,a.is_nullable as nullable,
 		  "default_is_null" = (CASE WHEN c.text is null THEN 1 ELSE 0 END),
 	      replace(replace(isnull(c.text,''),'(',''),')','') as vdefault,
-		  ISNULL(p.is_primary_key, 0), a.is_identity as is_identity, a.collation_name
+		  ISNULL(p.is_primary_key, 0), a.is_identity as is_identity, a.collation_name,
+		  cc.definition, ISNULL(cc.is_persisted, 0)
           from sys.columns a 
 		  left join sys.types b on a.user_type_id=b.user_type_id
           left join sys.syscomments c on a.default_object_id=c.id
+		  left join sys.computed_columns cc on cc.object_id = a.object_id AND cc.column_id = a.column_id
 		  LEFT OUTER JOIN (SELECT i.object_id, ic.column_id, i.is_primary_key
 			FROM sys.indexes i
 		  LEFT JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
@@ -481,10 +560,11 @@ func (db *mssql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 	colSeq := make([]string, 0)
 	for rows.Next() {
 		var name, ctype, vdefault string
-		var collation *string
+		var collation, generation *string
 		var maxLen, precision, scale int64
-		var nullable, isPK, defaultIsNull, isIncrement bool
-		err = rows.Scan(&name, &ctype, &maxLen, &precision, &scale, &nullable, &defaultIsNull, &vdefault, &isPK, &isIncrement, &collation)
+		var nullable, isPK, defaultIsNull, isIncrement, isPersisted bool
+		err = rows.Scan(&name, &ctype, &maxLen, &precision, &scale, &nullable, &defaultIsNull, &vdefault, &isPK, &isIncrement, &collation,
+			&generation, &isPersisted)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -499,6 +579,10 @@ func (db *mssql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 		}
 		col.IsPrimaryKey = isPK
 		col.IsAutoIncrement = isIncrement
+		if generation != nil {
+			col.Generated = schemas.TrimOuterParens(*generation)
+			col.GeneratedStored = isPersisted
+		}
 		ct := strings.ToUpper(ctype)
 		if ct == "DECIMAL" {
 			col.Length = precision
@@ -573,7 +657,30 @@ func (db *mssql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schema
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
-	return tables, nil
+
+	views, err := queryViews(queryer, ctx, false, "SELECT v.name, m.definition FROM sys.views v INNER JOIN sys.sql_modules m ON m.object_id = v.object_id")
+	if err != nil {
+		return nil, err
+	}
+	return append(tables, views...), nil
+}
+
+// CreateIndexSQL returns a SQL to create index, the index method could be CLUSTERED or NONCLUSTERED
+func (db *mssql) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	quoter := db.dialect.Quoter()
+	var unique, method, where string
+	if index.Type == schemas.UniqueType {
+		unique = " UNIQUE"
+	}
+	if index.Method != "" {
+		method = " " + strings.ToUpper(index.Method)
+	}
+	if index.Where != "" {
+		where = " WHERE " + index.Where
+	}
+	return fmt.Sprintf("CREATE%s%s INDEX %v ON %v (%v)%s", unique, method,
+		quoter.Quote(index.XName(tableName)), quoter.Quote(tableName),
+		IndexKeysString(quoter, index), where)
 }
 
 func (db *mssql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
@@ -581,13 +688,16 @@ func (db *mssql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName
 	s := `SELECT
 IXS.NAME                    AS  [INDEX_NAME],
 C.NAME                      AS  [COLUMN_NAME],
-IXS.is_unique AS [IS_UNIQUE]
+IXS.is_unique AS [IS_UNIQUE],
+IXCS.is_descending_key AS [IS_DESC],
+IXS.filter_definition AS [FILTER]
 FROM sys.indexes IXS
 INNER JOIN sys.index_columns IXCS
 ON IXS.OBJECT_ID=IXCS.OBJECT_ID  AND IXS.INDEX_ID = IXCS.INDEX_ID
 INNER   JOIN sys.columns C  ON IXS.OBJECT_ID=C.OBJECT_ID
 AND IXCS.COLUMN_ID=C.COLUMN_ID
 WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
+ORDER BY IXCS.key_ordinal
 `
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
@@ -600,8 +710,10 @@ WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
 	for rows.Next() {
 		var indexType int
 		var indexName, colName, isUnique string
+		var isDesc bool
+		var filter sql.NullString
 
-		err = rows.Scan(&indexName, &colName, &isUnique)
+		err = rows.Scan(&indexName, &colName, &isUnique, &isDesc, &filter)
 		if err != nil {
 			return nil, err
 		}
@@ -631,9 +743,14 @@ WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
 			index.Type = indexType
 			index.Name = indexName
 			index.IsRegular = isRegular
+			index.Where = schemas.TrimOuterParens(filter.String)
 			indexes[indexName] = index
 		}
-		index.AddColumn(colName)
+		if isDesc {
+			index.AddDescColumn(colName)
+		} else {
+			index.AddColumn(colName)
+		}
 	}
 	if rows.Err() != nil {
 		return nil, rows.Err()
@@ -641,6 +758,26 @@ WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
 	return indexes, nil
 }
 
+// GetForeignKeys returns the foreign keys of the table
+func (db *mssql) GetForeignKeys(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.ForeignKey, error) {
+	s := `SELECT FK.NAME, C.NAME, RT.NAME, RC.NAME,
+FK.UPDATE_REFERENTIAL_ACTION_DESC, FK.DELETE_REFERENTIAL_ACTION_DESC
+FROM sys.foreign_keys FK
+INNER JOIN sys.foreign_key_columns FKC ON FKC.CONSTRAINT_OBJECT_ID = FK.OBJECT_ID
+INNER JOIN sys.columns C ON C.OBJECT_ID = FKC.PARENT_OBJECT_ID AND C.COLUMN_ID = FKC.PARENT_COLUMN_ID
+INNER JOIN sys.tables RT ON RT.OBJECT_ID = FKC.REFERENCED_OBJECT_ID
+INNER JOIN sys.columns RC ON RC.OBJECT_ID = FKC.REFERENCED_OBJECT_ID AND RC.COLUMN_ID = FKC.REFERENCED_COLUMN_ID
+WHERE OBJECT_NAME(FK.PARENT_OBJECT_ID) = ?
+ORDER BY FK.NAME, FKC.CONSTRAINT_COLUMN_ID`
+	return queryForeignKeys(queryer, ctx, s, tableName)
+}
+
+// GetChecks returns the CHECK constraints of the table
+func (db *mssql) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	s := "SELECT NAME, DEFINITION FROM sys.check_constraints WHERE OBJECT_NAME(PARENT_OBJECT_ID) = ?"
+	return queryChecks(queryer, ctx, s, tableName)
+}
+
 func (db *mssql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
 	if tableName == "" {
 		tableName = table.Name
@@ -670,6 +807,7 @@ func (db *mssql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table
 		b.WriteString(")")
 	}
 
+	b.WriteString(constraintsString(db.dialect, tableName, table))
 	b.WriteString(")")
 
 	return b.String(), true, nil
@@ -737,3 +875,15 @@ func (p *odbcDriver) GenScanResult(colType string) (interface{}, error) {
 		return &r, nil
 	}
 }
+
+// TryLockSQL returns a query to acquire the application lock owned by the session
+func (db *mssql) TryLockSQL(name string) (string, []interface{}) {
+	return "DECLARE @result INT; " +
+		"EXEC @result = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = 0; " +
+		"SELECT CASE WHEN @result >= 0 THEN 1 ELSE 0 END", []interface{}{name}
+}
+
+// UnlockSQL returns a SQL to release the application lock owned by the session
+func (db *mssql) UnlockSQL(name string) (string, []interface{}) {
+	return "EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'", []interface{}{name}
+}
@@ -223,8 +223,9 @@ func (db *mysql) Version(ctx context.Context, queryer core.Queryer) (*schemas.Ve
 		}, nil
 	}
 
+	// 10.4.12-MariaDB-1:10.4.12+maria~bionic
 	var edition string
-	if len(fields) == 2 {
+	if len(fields) >= 2 {
 		edition = fields[1]
 	}
 
@@ -237,6 +238,7 @@ func (db *mysql) Version(ctx context.Context, queryer core.Queryer) (*schemas.Ve
 func (db *mysql) Features() *DialectFeatures {
 	return &DialectFeatures{
 		AutoincrMode: IncrAutoincrMode,
+		MaxParams:    65535,
 	}
 }
 
@@ -399,8 +401,8 @@ func (db *mysql) AddColumnSQL(tableName string, col *schemas.Column) string {
 	return b.String()
 }
 
-// ModifyColumnSQL returns a SQL to modify SQL
-func (db *mysql) ModifyColumnSQL(tableName string, col *schemas.Column) string {
+// columnDefinition returns the whole definition of the column including the name
+func (db *mysql) columnDefinition(col *schemas.Column) string {
 	s, _ := ColumnString(db.dialect, col, false, true)
 	if col.IsAutoIncrement {
 		s += " " + db.AutoIncrStr()
@@ -408,10 +410,44 @@ func (db *mysql) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	if col.Comment != "" {
 		s += fmt.Sprintf(" COMMENT '%s'", col.Comment)
 	}
-	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", db.quoter.Quote(tableName), s)
+	return s
+}
+
+// ModifyColumnSQL returns a SQL to modify SQL
+func (db *mysql) ModifyColumnSQL(tableName string, col *schemas.Column) string {
+	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", db.quoter.Quote(tableName), db.columnDefinition(col))
+}
+
+// ChangeColumnSQL returns a SQL to rename the column from oldName and restate its definition, it's
+// for MySQL before 8.0 and MariaDB before 10.5.2 which have no ALTER TABLE RENAME COLUMN
+func (db *mysql) ChangeColumnSQL(tableName, oldName string, col *schemas.Column) string {
+	return fmt.Sprintf("ALTER TABLE %s CHANGE COLUMN %s %s", db.quoter.Quote(tableName),
+		db.quoter.Quote(oldName), db.columnDefinition(col))
+}
+
+// AlterColumnTypeSQL returns a SQL to change the type of the column
+func (db *mysql) AlterColumnTypeSQL(tableName string, col *schemas.Column) (string, error) {
+	return db.ModifyColumnSQL(tableName, col), nil
+}
+
+// AlterColumnNullableSQL returns a SQL to change the nullability of the column,
+// MySQL has to restate the whole column definition.
+func (db *mysql) AlterColumnNullableSQL(tableName string, col *schemas.Column) (string, error) {
+	return db.ModifyColumnSQL(tableName, col), nil
 }
 
 func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
+	// generated columns are supported since MySQL 5.7 and MariaDB 10.2
+	generationCol := "NULL"
+	hasGeneration, err := db.HasRecords(queryer, ctx, "SELECT `COLUMN_NAME` FROM `INFORMATION_SCHEMA`.`COLUMNS` "+
+		"WHERE `TABLE_SCHEMA` = 'information_schema' AND `TABLE_NAME` = 'COLUMNS' AND `COLUMN_NAME` = 'GENERATION_EXPRESSION'")
+	if err != nil {
+		return nil, nil, err
+	}
+	if hasGeneration {
+		generationCol = "`GENERATION_EXPRESSION`"
+	}
+
 	args := []interface{}{db.uri.DBName, tableName}
 	alreadyQuoted := "(INSTR(VERSION(), 'maria') > 0 && " +
 		"(SUBSTRING_INDEX(VERSION(), '.', 1) > 10 || " +
@@ -421,7 +457,7 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 		"SUBSTRING_INDEX(SUBSTRING(VERSION(), 6), '-', 1) >= 7)))))"
 	s := "SELECT `COLUMN_NAME`, `IS_NULLABLE`, `COLUMN_DEFAULT`, `COLUMN_TYPE`," +
 		" `COLUMN_KEY`, `EXTRA`, `COLUMN_COMMENT`, `CHARACTER_MAXIMUM_LENGTH`, " +
-		alreadyQuoted + " AS NEEDS_QUOTE, `COLLATION_NAME` " +
+		alreadyQuoted + " AS NEEDS_QUOTE, `COLLATION_NAME`, " + generationCol + " " +
 		"FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ?" +
 		" ORDER BY `COLUMNS`.ORDINAL_POSITION ASC"
 
@@ -439,8 +475,8 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 
 		var columnName, nullableStr, colType, colKey, extra, comment string
 		var alreadyQuoted, isUnsigned bool
-		var colDefault, maxLength, collation *string
-		err = rows.Scan(&columnName, &nullableStr, &colDefault, &colType, &colKey, &extra, &comment, &maxLength, &alreadyQuoted, &collation)
+		var colDefault, maxLength, collation, generation *string
+		err = rows.Scan(&columnName, &nullableStr, &colDefault, &colType, &colKey, &extra, &comment, &maxLength, &alreadyQuoted, &collation, &generation)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -532,6 +568,16 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 			col.IsAutoIncrement = true
 		}
 
+		// EXTRA is VIRTUAL GENERATED or STORED GENERATED, and PERSISTENT on old MariaDB
+		upperExtra := strings.ToUpper(extra)
+		if generation != nil && *generation != "" &&
+			(strings.Contains(upperExtra, "VIRTUAL") || strings.Contains(upperExtra, "STORED") || strings.Contains(upperExtra, "PERSISTENT")) {
+			col.Generated = schemas.TrimOuterParens(*generation)
+			col.GeneratedStored = !strings.Contains(upperExtra, "VIRTUAL")
+			col.Default = ""
+			col.DefaultIsEmpty = true
+		}
+
 		if !col.DefaultIsEmpty {
 			if !alreadyQuoted && col.SQLType.IsText() {
 				col.Default = "'" + col.Default + "'"
@@ -580,7 +626,12 @@ func (db *mysql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schema
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
-	return tables, nil
+
+	views, err := queryViews(queryer, ctx, false, "SELECT `TABLE_NAME`, `VIEW_DEFINITION` FROM `INFORMATION_SCHEMA`.`VIEWS` WHERE `TABLE_SCHEMA` = ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	return append(tables, views...), nil
 }
 
 func (db *mysql) SetQuotePolicy(quotePolicy QuotePolicy) {
@@ -600,9 +651,40 @@ func (db *mysql) SetQuotePolicy(quotePolicy QuotePolicy) {
 	}
 }
 
+// CreateIndexSQL returns a SQL to create index, MySQL has no partial indexes so that the predicate is ignored
+func (db *mysql) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	quoter := db.dialect.Quoter()
+	var kind, using string
+	switch method := strings.ToUpper(index.Method); method {
+	case "FULLTEXT", "SPATIAL":
+		kind = " " + method
+	case "":
+	default:
+		using = " USING " + method
+	}
+	if kind == "" && index.Type == schemas.UniqueType {
+		kind = " UNIQUE"
+	}
+	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v)%s", kind,
+		quoter.Quote(index.XName(tableName)), quoter.Quote(tableName),
+		IndexKeysString(quoter, index), using)
+}
+
 func (db *mysql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
+	// functional key parts are supported since MySQL 8.0.13
+	exprCol := "NULL"
+	hasExpr, err := db.HasRecords(queryer, ctx, "SELECT `COLUMN_NAME` FROM `INFORMATION_SCHEMA`.`COLUMNS` "+
+		"WHERE `TABLE_SCHEMA` = 'information_schema' AND `TABLE_NAME` = 'STATISTICS' AND `COLUMN_NAME` = 'EXPRESSION'")
+	if err != nil {
+		return nil, err
+	}
+	if hasExpr {
+		exprCol = "`EXPRESSION`"
+	}
+
 	args := []interface{}{db.uri.DBName, tableName}
-	s := "SELECT `INDEX_NAME`, `NON_UNIQUE`, `COLUMN_NAME` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ? ORDER BY `SEQ_IN_INDEX`"
+	s := "SELECT `INDEX_NAME`, `NON_UNIQUE`, `COLUMN_NAME`, " + exprCol + ", `INDEX_TYPE`, `COLLATION` " +
+		"FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ? ORDER BY `SEQ_IN_INDEX`"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -613,8 +695,9 @@ func (db *mysql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName
 	indexes := make(map[string]*schemas.Index)
 	for rows.Next() {
 		var indexType int
-		var indexName, colName, nonUnique string
-		err = rows.Scan(&indexName, &nonUnique, &colName)
+		var indexName, nonUnique, method string
+		var colName, expr, collation sql.NullString
+		err = rows.Scan(&indexName, &nonUnique, &colName, &expr, &method, &collation)
 		if err != nil {
 			return nil, err
 		}
@@ -629,7 +712,10 @@ func (db *mysql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName
 			indexType = schemas.UniqueType
 		}
 
-		colName = strings.Trim(colName, "` ")
+		key := strings.Trim(colName.String, "` ")
+		if !colName.Valid {
+			key = schemas.TrimOuterParens(expr.String)
+		}
 		var isRegular bool
 		if strings.HasPrefix(indexName, "IDX_"+tableName) || strings.HasPrefix(indexName, "UQE_"+tableName) {
 			indexName = indexName[5+len(tableName):]
@@ -643,9 +729,16 @@ func (db *mysql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName
 			index.IsRegular = isRegular
 			index.Type = indexType
 			index.Name = indexName
+			if method != "BTREE" {
+				index.Method = method
+			}
 			indexes[indexName] = index
 		}
-		index.AddColumn(colName)
+		if collation.String == "D" {
+			index.AddDescColumn(key)
+		} else {
+			index.AddColumn(key)
+		}
 	}
 	if rows.Err() != nil {
 		return nil, rows.Err()
@@ -653,6 +746,47 @@ func (db *mysql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName
 	return indexes, nil
 }
 
+// GetForeignKeys returns the foreign keys of the table
+func (db *mysql) GetForeignKeys(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.ForeignKey, error) {
+	s := "SELECT `k`.`CONSTRAINT_NAME`, `k`.`COLUMN_NAME`, `k`.`REFERENCED_TABLE_NAME`, `k`.`REFERENCED_COLUMN_NAME`, " +
+		"`r`.`UPDATE_RULE`, `r`.`DELETE_RULE` FROM `INFORMATION_SCHEMA`.`KEY_COLUMN_USAGE` `k` " +
+		"INNER JOIN `INFORMATION_SCHEMA`.`REFERENTIAL_CONSTRAINTS` `r` ON `r`.`CONSTRAINT_SCHEMA` = `k`.`CONSTRAINT_SCHEMA` " +
+		"AND `r`.`CONSTRAINT_NAME` = `k`.`CONSTRAINT_NAME` " +
+		"WHERE `k`.`TABLE_SCHEMA` = ? AND `k`.`TABLE_NAME` = ? AND `k`.`REFERENCED_TABLE_NAME` IS NOT NULL " +
+		"ORDER BY `k`.`CONSTRAINT_NAME`, `k`.`ORDINAL_POSITION`"
+	return queryForeignKeys(queryer, ctx, s, db.uri.DBName, tableName)
+}
+
+// DropForeignKeySQL returns a SQL to drop the foreign key of the table
+func (db *mysql) DropForeignKeySQL(tableName string, fk *schemas.ForeignKey) (string, error) {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", quoter.Quote(tableName), quoter.Quote(fk.XName(tableName))), nil
+}
+
+// GetChecks returns the CHECK constraints of the table, MySQL supports them since 8.0.16
+func (db *mysql) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	exist, err := db.HasRecords(queryer, ctx, "SELECT `TABLE_NAME` FROM `INFORMATION_SCHEMA`.`TABLES` "+
+		"WHERE `TABLE_SCHEMA` = 'information_schema' AND `TABLE_NAME` = 'CHECK_CONSTRAINTS'")
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return make(map[string]*schemas.Check), nil
+	}
+
+	s := "SELECT `t`.`CONSTRAINT_NAME`, `c`.`CHECK_CLAUSE` FROM `INFORMATION_SCHEMA`.`TABLE_CONSTRAINTS` `t` " +
+		"INNER JOIN `INFORMATION_SCHEMA`.`CHECK_CONSTRAINTS` `c` ON `c`.`CONSTRAINT_SCHEMA` = `t`.`CONSTRAINT_SCHEMA` " +
+		"AND `c`.`CONSTRAINT_NAME` = `t`.`CONSTRAINT_NAME` " +
+		"WHERE `t`.`TABLE_SCHEMA` = ? AND `t`.`TABLE_NAME` = ? AND `t`.`CONSTRAINT_TYPE` = 'CHECK'"
+	return queryChecks(queryer, ctx, s, db.uri.DBName, tableName)
+}
+
+// DropCheckSQL returns a SQL to drop the CHECK constraint of the table
+func (db *mysql) DropCheckSQL(tableName string, check *schemas.Check) (string, error) {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s DROP CHECK %s", quoter.Quote(tableName), quoter.Quote(check.XName(tableName))), nil
+}
+
 func (db *mysql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
 	if tableName == "" {
 		tableName = table.Name
@@ -686,6 +820,7 @@ func (db *mysql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table
 		b.WriteString(")")
 	}
 
+	b.WriteString(constraintsString(db.dialect, tableName, table))
 	b.WriteString(")")
 
 	if table.StoreEngine != "" {
@@ -713,9 +848,127 @@ func (db *mysql) CreateTableSQL(ctx context.Context, queryer core.Queryer, table
 		b.WriteString("'")
 	}
 
+	if table.Partitioning != nil {
+		b.WriteString(" ")
+		b.WriteString(db.partitionByString(table.Partitioning))
+	}
+
 	return b.String(), true, nil
 }
 
+// partitionByString returns the PARTITION BY clause with the partitions, RANGE and LIST
+// partitioning on columns will be COLUMNS partitioning to support the non integer columns
+func (db *mysql) partitionByString(partitioning *schemas.Partitioning) string {
+	quoter := db.dialect.Quoter()
+	var b strings.Builder
+	b.WriteString("PARTITION BY ")
+	b.WriteString(string(partitioning.Type))
+
+	isColumns := partitioning.Type != schemas.HashPartition
+	for _, key := range partitioning.Keys {
+		if schemas.IsIndexExpr(key) {
+			isColumns = false
+			break
+		}
+	}
+	if isColumns {
+		b.WriteString(" COLUMNS(")
+		b.WriteString(quoter.Join(partitioning.Keys, ","))
+	} else {
+		b.WriteString(" (")
+		b.WriteString(partitionKeysString(quoter, partitioning))
+	}
+	b.WriteString(")")
+
+	if len(partitioning.Partitions) > 0 {
+		b.WriteString(" (")
+		for i, partition := range partitioning.Partitions {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(db.partitionString(partition))
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// partitionString returns the definition of the partition
+func (db *mysql) partitionString(partition *schemas.Partition) string {
+	name := db.dialect.Quoter().Quote(partition.Name)
+	switch {
+	case len(partition.Values) > 0:
+		return fmt.Sprintf("PARTITION %s VALUES IN (%s)", name, strings.Join(partition.Values, ", "))
+	case partition.To != "":
+		return fmt.Sprintf("PARTITION %s VALUES LESS THAN (%s)", name, partition.To)
+	}
+	return "PARTITION " + name
+}
+
+// CreatePartitionSQL returns a SQL to add the partition to the table
+func (db *mysql) CreatePartitionSQL(tableName string, partition *schemas.Partition) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD PARTITION (%s)", db.dialect.Quoter().Quote(tableName), db.partitionString(partition))
+}
+
+// AttachPartitionSQL returns the SQLs to add the partition and exchange the data with the table
+// named by the partition, the table which will be empty after exchanging is dropped
+func (db *mysql) AttachPartitionSQL(tableName string, partition *schemas.Partition) []string {
+	quoter := db.dialect.Quoter()
+	return []string{
+		db.CreatePartitionSQL(tableName, partition),
+		fmt.Sprintf("ALTER TABLE %s EXCHANGE PARTITION %s WITH TABLE %s", quoter.Quote(tableName),
+			quoter.Quote(partition.Name), quoter.Quote(partition.Name)),
+		fmt.Sprintf("DROP TABLE %s", quoter.Quote(partition.Name)),
+	}
+}
+
+// DetachPartitionSQL returns the SQLs to exchange the data of the partition with a new table
+// named by the partition and drop the partition
+func (db *mysql) DetachPartitionSQL(tableName, partitionName string) []string {
+	quoter := db.dialect.Quoter()
+	return []string{
+		fmt.Sprintf("CREATE TABLE %s LIKE %s", quoter.Quote(partitionName), quoter.Quote(tableName)),
+		fmt.Sprintf("ALTER TABLE %s REMOVE PARTITIONING", quoter.Quote(partitionName)),
+		fmt.Sprintf("ALTER TABLE %s EXCHANGE PARTITION %s WITH TABLE %s", quoter.Quote(tableName),
+			quoter.Quote(partitionName), quoter.Quote(partitionName)),
+		fmt.Sprintf("ALTER TABLE %s DROP PARTITION %s", quoter.Quote(tableName), quoter.Quote(partitionName)),
+	}
+}
+
+// GetPartitions returns the partitions of the partitioned table
+func (db *mysql) GetPartitions(queryer core.Queryer, ctx context.Context, tableName string) ([]*schemas.Partition, error) {
+	s := "SELECT `PARTITION_NAME`, `PARTITION_METHOD`, `PARTITION_DESCRIPTION` FROM `INFORMATION_SCHEMA`.`PARTITIONS` " +
+		"WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ? AND `PARTITION_NAME` IS NOT NULL ORDER BY `PARTITION_ORDINAL_POSITION`"
+	rows, err := queryer.QueryContext(ctx, s, db.uri.DBName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []*schemas.Partition
+	for rows.Next() {
+		var name, method string
+		var description *string
+		if err := rows.Scan(&name, &method, &description); err != nil {
+			return nil, err
+		}
+		partition := &schemas.Partition{Name: name}
+		if description != nil {
+			switch {
+			case strings.HasPrefix(method, string(schemas.RangePartition)):
+				partition.To = *description
+			case strings.HasPrefix(method, string(schemas.ListPartition)):
+				partition.Values = splitPartitionValues(*description)
+			}
+		}
+		partitions = append(partitions, partition)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return partitions, nil
+}
+
 func (db *mysql) Filters() []Filter {
 	return []Filter{}
 }
@@ -848,3 +1101,13 @@ func (p *mymysqlDriver) Parse(driverName, dataSourceName string) (*URI, error) {
 
 	return uri, nil
 }
+
+// TryLockSQL returns a query to acquire the named lock by GET_LOCK
+func (db *mysql) TryLockSQL(name string) (string, []interface{}) {
+	return "SELECT COALESCE(GET_LOCK(?, 0), 0)", []interface{}{name}
+}
+
+// UnlockSQL returns a query to release the named lock by RELEASE_LOCK
+func (db *mysql) UnlockSQL(name string) (string, []interface{}) {
+	return "SELECT RELEASE_LOCK(?)", []interface{}{name}
+}
@@ -554,6 +554,7 @@ func (db *oracle) Version(ctx context.Context, queryer core.Queryer) (*schemas.V
 func (db *oracle) Features() *DialectFeatures {
 	return &DialectFeatures{
 		AutoincrMode: SequenceAutoincrMode,
+		MaxParams:    999, // the max number of columns of all the INTO clauses of INSERT ALL
 	}
 }
 
@@ -624,6 +625,53 @@ func (db *oracle) DropTableSQL(tableName string) (string, bool) {
 	return fmt.Sprintf("DROP TABLE \"%s\"", tableName), false
 }
 
+// DropViewSQL returns drop view SQL
+func (db *oracle) DropViewSQL(viewName string, materialized bool) string {
+	return fmt.Sprintf("DROP VIEW %s", db.Quoter().Quote(viewName))
+}
+
+func (db *oracle) AlterColumnTypeSQL(tableName string, col *schemas.Column) (string, error) {
+	quoter := db.Quoter()
+	return fmt.Sprintf("ALTER TABLE %s MODIFY (%s %s)", quoter.Quote(tableName), quoter.Quote(col.Name), db.SQLType(col)), nil
+}
+
+func (db *oracle) AlterColumnNullableSQL(tableName string, col *schemas.Column) (string, error) {
+	quoter := db.Quoter()
+	nullable := "NOT NULL"
+	if col.Nullable {
+		nullable = "NULL"
+	}
+	return fmt.Sprintf("ALTER TABLE %s MODIFY (%s %s)", quoter.Quote(tableName), quoter.Quote(col.Name), nullable), nil
+}
+
+func (db *oracle) AlterColumnDefaultSQL(tableName string, col *schemas.Column) (string, error) {
+	quoter := db.Quoter()
+	def := "NULL"
+	if !col.DefaultIsEmpty {
+		def = defaultValue(col)
+	}
+	return fmt.Sprintf("ALTER TABLE %s MODIFY (%s DEFAULT %s)", quoter.Quote(tableName), quoter.Quote(col.Name), def), nil
+}
+
+// GetForeignKeys returns the foreign keys of the table
+func (db *oracle) GetForeignKeys(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.ForeignKey, error) {
+	s := `SELECT c.constraint_name, cc.column_name, rc.table_name, rcc.column_name, 'NO ACTION', c.delete_rule
+FROM user_constraints c
+INNER JOIN user_cons_columns cc ON cc.constraint_name = c.constraint_name
+INNER JOIN user_constraints rc ON rc.constraint_name = c.r_constraint_name
+INNER JOIN user_cons_columns rcc ON rcc.constraint_name = rc.constraint_name AND rcc.position = cc.position
+WHERE c.constraint_type = 'R' AND c.table_name = :1
+ORDER BY c.constraint_name, cc.position`
+	return queryForeignKeys(queryer, ctx, s, tableName)
+}
+
+// GetChecks returns the CHECK constraints of the table except the NOT NULL ones generated by database
+func (db *oracle) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	s := `SELECT constraint_name, search_condition_vc FROM user_constraints
+WHERE constraint_type = 'C' AND generated = 'USER NAME' AND table_name = :1`
+	return queryChecks(queryer, ctx, s, tableName)
+}
+
 func (db *oracle) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
 	sql := "CREATE TABLE "
 	if tableName == "" {
@@ -653,7 +701,7 @@ func (db *oracle) CreateTableSQL(ctx context.Context, queryer core.Queryer, tabl
 		sql += " ), "
 	}
 
-	sql = sql[:len(sql)-2] + ")"
+	sql = sql[:len(sql)-2] + constraintsString(db, tableName, table) + ")"
 	return sql, false, nil
 }
 
@@ -698,7 +746,7 @@ func (db *oracle) IsColumnExist(queryer core.Queryer, ctx context.Context, table
 func (db *oracle) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
 	args := []interface{}{tableName}
 	s := "SELECT column_name,data_default,data_type,data_length,data_precision,data_scale," +
-		"nullable FROM USER_TAB_COLUMNS WHERE table_name = :1"
+		"nullable,virtual_column FROM USER_TAB_COLUMNS WHERE table_name = :1"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -712,17 +760,20 @@ func (db *oracle) GetColumns(queryer core.Queryer, ctx context.Context, tableNam
 		col := new(schemas.Column)
 		col.Indexes = make(map[string]int)
 
-		var colName, colDefault, nullable, dataType, dataPrecision, dataScale *string
+		var colName, colDefault, nullable, dataType, dataPrecision, dataScale, virtual *string
 		var dataLen int64
 
 		err = rows.Scan(&colName, &colDefault, &dataType, &dataLen, &dataPrecision,
-			&dataScale, &nullable)
+			&dataScale, &nullable, &virtual)
 		if err != nil {
 			return nil, nil, err
 		}
 
 		col.Name = strings.Trim(*colName, `" `)
-		if colDefault != nil {
+		if virtual != nil && *virtual == "YES" && colDefault != nil {
+			// the expression of a virtual column is kept as the default
+			col.Generated = schemas.TrimOuterParens(*colDefault)
+		} else if colDefault != nil {
 			col.Default = *colDefault
 			col.DefaultIsEmpty = false
 		}
@@ -818,12 +869,32 @@ func (db *oracle) GetTables(queryer core.Queryer, ctx context.Context) ([]*schem
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
-	return tables, nil
+
+	views, err := queryViews(queryer, ctx, false, "SELECT view_name, text FROM user_views")
+	if err != nil {
+		return nil, err
+	}
+	return append(tables, views...), nil
+}
+
+// CreateIndexSQL returns a SQL to create index, the index method could be BITMAP and
+// the predicate is ignored since Oracle has no partial indexes
+func (db *oracle) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	quoter := db.dialect.Quoter()
+	var kind string
+	if index.Type == schemas.UniqueType {
+		kind = " UNIQUE"
+	} else if strings.EqualFold(index.Method, "BITMAP") {
+		kind = " BITMAP"
+	}
+	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v)", kind,
+		quoter.Quote(index.XName(tableName)), quoter.Quote(tableName),
+		IndexKeysString(quoter, index))
 }
 
 func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
 	args := []interface{}{tableName}
-	s := "SELECT t.column_name,i.uniqueness,i.index_name FROM user_ind_columns t,user_indexes i " +
+	s := "SELECT t.column_name,i.uniqueness,i.index_name,i.index_type FROM user_ind_columns t,user_indexes i " +
 		"WHERE t.index_name = i.index_name and t.table_name = i.table_name and t.table_name =:1"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
@@ -835,9 +906,9 @@ func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 	indexes := make(map[string]*schemas.Index)
 	for rows.Next() {
 		var indexType int
-		var indexName, colName, uniqueness string
+		var indexName, colName, uniqueness, method string
 
-		err = rows.Scan(&colName, &uniqueness, &indexName)
+		err = rows.Scan(&colName, &uniqueness, &indexName, &method)
 		if err != nil {
 			return nil, err
 		}
@@ -863,6 +934,9 @@ func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 			index.Type = indexType
 			index.Name = indexName
 			index.IsRegular = isRegular
+			if method == "BITMAP" {
+				index.Method = method
+			}
 			indexes[indexName] = index
 		}
 		index.AddColumn(colName)
@@ -0,0 +1,52 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"strings"
+
+	"xorm.io/xorm/core"
+	"xorm.io/xorm/schemas"
+)
+
+// PartitionDialect represents a dialect supports table partitioning, i.e. Postgres and MySQL
+type PartitionDialect interface {
+	Dialect
+
+	// CreatePartitionSQL returns a SQL to create a new partition of the partitioned table
+	CreatePartitionSQL(tableName string, partition *schemas.Partition) string
+	// AttachPartitionSQL returns the SQLs to attach the table named by the partition as a partition
+	AttachPartitionSQL(tableName string, partition *schemas.Partition) []string
+	// DetachPartitionSQL returns the SQLs to detach the partition, the data of the partition
+	// will be kept in a table named by the partition
+	DetachPartitionSQL(tableName, partitionName string) []string
+	// GetPartitions returns the partitions of the partitioned table
+	GetPartitions(queryer core.Queryer, ctx context.Context, tableName string) ([]*schemas.Partition, error)
+}
+
+// partitionKeysString returns the partition keys, the columns are quoted and the expressions are wrapped
+func partitionKeysString(quoter schemas.Quoter, partitioning *schemas.Partitioning) string {
+	keys := make([]string, 0, len(partitioning.Keys))
+	for _, key := range partitioning.Keys {
+		if schemas.IsIndexExpr(key) {
+			keys = append(keys, "("+key+")")
+		} else {
+			keys = append(keys, quoter.Quote(key))
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// splitPartitionValues splits the values of a partition bound by the commas which are not in brackets or quotes
+func splitPartitionValues(s string) []string {
+	var values []string
+	for _, v := range splitTableDefinitions(s) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
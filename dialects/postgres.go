@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -971,6 +972,7 @@ func (db *postgres) SQLType(c *schemas.Column) string {
 func (db *postgres) Features() *DialectFeatures {
 	return &DialectFeatures{
 		AutoincrMode: IncrAutoincrMode,
+		MaxParams:    65535,
 	}
 }
 
@@ -1051,6 +1053,68 @@ func (db *postgres) ModifyColumnSQL(tableName string, col *schemas.Column) strin
 	return modifyColumnSQL + commentSQL
 }
 
+func (db *postgres) tableNameWithSchema(tableName string) string {
+	if len(db.getSchema()) != 0 && !strings.Contains(tableName, ".") {
+		return db.getSchema() + "." + tableName
+	}
+	return tableName
+}
+
+func (db *postgres) AlterColumnTypeSQL(tableName string, col *schemas.Column) (string, error) {
+	return db.Base.AlterColumnTypeSQL(db.tableNameWithSchema(tableName), col)
+}
+
+func (db *postgres) AlterColumnNullableSQL(tableName string, col *schemas.Column) (string, error) {
+	return db.Base.AlterColumnNullableSQL(db.tableNameWithSchema(tableName), col)
+}
+
+func (db *postgres) AlterColumnDefaultSQL(tableName string, col *schemas.Column) (string, error) {
+	return db.Base.AlterColumnDefaultSQL(db.tableNameWithSchema(tableName), col)
+}
+
+func (db *postgres) DropColumnSQL(tableName, colName string) string {
+	return db.Base.DropColumnSQL(db.tableNameWithSchema(tableName), colName)
+}
+
+func (db *postgres) RenameColumnSQL(tableName, oldName, newName string) string {
+	return db.Base.RenameColumnSQL(db.tableNameWithSchema(tableName), oldName, newName)
+}
+
+// CreateViewSQL returns the SQLs to create the view, a materialized view will only be created if
+// not exists since it cannot be replaced
+func (db *postgres) CreateViewSQL(viewName string, view *schemas.Table) []string {
+	if view.IsMaterialized {
+		return []string{fmt.Sprintf("CREATE MATERIALIZED VIEW IF NOT EXISTS %s AS %s",
+			db.Quoter().Quote(db.tableNameWithSchema(viewName)), view.ViewDefinition)}
+	}
+	return db.Base.CreateViewSQL(db.tableNameWithSchema(viewName), view)
+}
+
+// DropViewSQL returns drop view SQL
+func (db *postgres) DropViewSQL(viewName string, materialized bool) string {
+	if materialized {
+		return fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s", db.Quoter().Quote(db.tableNameWithSchema(viewName)))
+	}
+	return db.Base.DropViewSQL(db.tableNameWithSchema(viewName), false)
+}
+
+// RefreshMaterializedViewSQL returns a SQL to refresh the materialized view
+func (db *postgres) RefreshMaterializedViewSQL(viewName string, concurrently bool) string {
+	var s string
+	if concurrently {
+		s = " CONCURRENTLY"
+	}
+	return fmt.Sprintf("REFRESH MATERIALIZED VIEW%s %s", s, db.Quoter().Quote(db.tableNameWithSchema(viewName)))
+}
+
+// RenameTableSQL returns a SQL to rename the table, the new name should not be qualified by the schema
+func (db *postgres) RenameTableSQL(oldName, newName string) string {
+	if idx := strings.LastIndex(newName, "."); idx >= 0 {
+		newName = newName[idx+1:]
+	}
+	return db.Base.RenameTableSQL(db.tableNameWithSchema(oldName), newName)
+}
+
 func (db *postgres) DropIndexSQL(tableName string, index *schemas.Index) string {
 	idxName := index.Name
 
@@ -1096,7 +1160,8 @@ func (db *postgres) GetColumns(queryer core.Queryer, ctx context.Context, tableN
 	args := []interface{}{tableName}
 	s := `SELECT column_name, column_default, is_nullable, data_type, character_maximum_length, description,
     CASE WHEN p.contype = 'p' THEN true ELSE false END AS primarykey,
-    CASE WHEN p.contype = 'u' THEN true ELSE false END AS uniquekey
+    CASE WHEN p.contype = 'u' THEN true ELSE false END AS uniquekey,
+    s.generation_expression
 FROM pg_attribute f
     JOIN pg_class c ON c.oid = f.attrelid JOIN pg_type t ON t.oid = f.atttypid
     LEFT JOIN pg_attrdef d ON d.adrelid = c.oid AND d.adnum = f.attnum
@@ -1105,7 +1170,7 @@ FROM pg_attribute f
     LEFT JOIN pg_constraint p ON p.conrelid = c.oid AND f.attnum = ANY (p.conkey)
     LEFT JOIN pg_class AS g ON p.confrelid = g.oid
     LEFT JOIN INFORMATION_SCHEMA.COLUMNS s ON s.column_name=f.attname AND c.relname=s.table_name
-WHERE n.nspname= s.table_schema AND c.relkind = 'r' AND c.relname = $1%s AND f.attnum > 0 ORDER BY f.attnum;`
+WHERE n.nspname= s.table_schema AND c.relkind IN ('r', 'p') AND c.relname = $1%s AND f.attnum > 0 ORDER BY f.attnum;`
 
 	schema := db.getSchema()
 	if schema != "" {
@@ -1129,9 +1194,9 @@ WHERE n.nspname= s.table_schema AND c.relkind = 'r' AND c.relname = $1%s AND f.a
 		col.Indexes = make(map[string]int)
 
 		var colName, isNullable, dataType string
-		var maxLenStr, colDefault, description *string
+		var maxLenStr, colDefault, description, generation *string
 		var isPK, isUnique bool
-		err = rows.Scan(&colName, &colDefault, &isNullable, &dataType, &maxLenStr, &description, &isPK, &isUnique)
+		err = rows.Scan(&colName, &colDefault, &isNullable, &dataType, &maxLenStr, &description, &isPK, &isUnique, &generation)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1181,6 +1246,14 @@ WHERE n.nspname= s.table_schema AND c.relkind = 'r' AND c.relname = $1%s AND f.a
 			col.Comment = *description
 		}
 
+		// generated columns are supported since Postgres 12 and they are always stored
+		if generation != nil && *generation != "" {
+			col.Generated = schemas.TrimOuterParens(*generation)
+			col.GeneratedStored = true
+			col.Default = ""
+			col.DefaultIsEmpty = true
+		}
+
 		if isPK {
 			col.IsPrimaryKey = true
 		}
@@ -1274,18 +1347,51 @@ func (db *postgres) GetTables(queryer core.Queryer, ctx context.Context) ([]*sch
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
-	return tables, nil
-}
-
-func getIndexColName(indexdef string) []string {
-	var colNames []string
-
-	cs := strings.Split(indexdef, "(")
-	for _, v := range strings.Split(strings.Split(cs[1], ")")[0], ",") {
-		colNames = append(colNames, strings.Split(strings.TrimLeft(v, " "), " ")[0])
+	rows.Close()
+
+	// the partitions are tables inherit the partitioned tables
+	partitionS := `SELECT c.relname, p.relname FROM pg_inherits i
+    JOIN pg_class c ON c.oid = i.inhrelid
+    JOIN pg_class p ON p.oid = i.inhparent
+    JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE p.relkind = 'p'`
+	if schema != "" {
+		partitionS += " AND n.nspname = $1"
+	}
+	partitionRows, err := queryer.QueryContext(ctx, partitionS, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer partitionRows.Close()
+	for partitionRows.Next() {
+		var name, parent string
+		if err := partitionRows.Scan(&name, &parent); err != nil {
+			return nil, err
+		}
+		for _, table := range tables {
+			if table.Name == name {
+				table.PartitionOf = parent
+			}
+		}
+	}
+	if partitionRows.Err() != nil {
+		return nil, partitionRows.Err()
 	}
 
-	return colNames
+	cond := " WHERE schemaname NOT IN ('pg_catalog', 'information_schema')"
+	if schema != "" {
+		cond = " WHERE schemaname = $1"
+	}
+	views, err := queryViews(queryer, ctx, false, "SELECT viewname, definition FROM pg_views"+cond, args...)
+	if err != nil {
+		return nil, err
+	}
+	matViews, err := queryViews(queryer, ctx, true, "SELECT matviewname, definition FROM pg_matviews"+cond, args...)
+	if err != nil {
+		return nil, err
+	}
+	views = append(views, matViews...)
+	return append(tables, views...), nil
 }
 
 func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
@@ -1306,7 +1412,6 @@ func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableN
 	for rows.Next() {
 		var indexType int
 		var indexName, indexdef string
-		var colNames []string
 		err = rows.Scan(&indexName, &indexdef)
 		if err != nil {
 			return nil, err
@@ -1325,10 +1430,11 @@ func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableN
 		} else {
 			indexType = schemas.IndexType
 		}
-		colNames = getIndexColName(indexdef)
+		index := &schemas.Index{Type: indexType}
+		parseIndexSQL(indexdef, index)
 
 		// Oid It's a special index. You can't put it in. TODO: This is not perfect.
-		if indexName == tableName+"_oid_index" && len(colNames) == 1 && colNames[0] == "oid" {
+		if indexName == tableName+"_oid_index" && len(index.Cols) == 1 && index.Cols[0] == "oid" {
 			continue
 		}
 
@@ -1341,12 +1447,7 @@ func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableN
 			}
 		}
 
-		index := &schemas.Index{Name: indexName, Type: indexType, Cols: make([]string, 0)}
-		for _, colName := range colNames {
-			col := strings.TrimSpace(strings.Replace(colName, `"`, "", -1))
-			fields := strings.Split(col, " ")
-			index.Cols = append(index.Cols, fields[0])
-		}
+		index.Name = indexName
 		index.IsRegular = isRegular
 		indexes[index.Name] = index
 	}
@@ -1356,8 +1457,80 @@ func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableN
 	return indexes, nil
 }
 
+// GetForeignKeys returns the foreign keys of the table
+func (db *postgres) GetForeignKeys(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.ForeignKey, error) {
+	args := []interface{}{tableName}
+	s := `SELECT con.conname, att.attname, ref.relname, refatt.attname,
+CASE con.confupdtype WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END,
+CASE con.confdeltype WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL' WHEN 'd' THEN 'SET DEFAULT' WHEN 'r' THEN 'RESTRICT' ELSE 'NO ACTION' END
+FROM pg_constraint con
+INNER JOIN pg_class cls ON cls.oid = con.conrelid
+INNER JOIN pg_namespace ns ON ns.oid = cls.relnamespace
+INNER JOIN pg_class ref ON ref.oid = con.confrelid
+CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+INNER JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = k.attnum
+INNER JOIN pg_attribute refatt ON refatt.attrelid = con.confrelid AND refatt.attnum = k.refattnum
+WHERE con.contype = 'f' AND cls.relname = $1`
+	if len(db.getSchema()) != 0 {
+		args = append(args, db.getSchema())
+		s += " AND ns.nspname = $2"
+	}
+	s += " ORDER BY con.conname, k.ord"
+	return queryForeignKeys(queryer, ctx, s, args...)
+}
+
+// GetChecks returns the CHECK constraints of the table
+func (db *postgres) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	args := []interface{}{tableName}
+	s := `SELECT con.conname, pg_get_constraintdef(con.oid) FROM pg_constraint con
+INNER JOIN pg_class cls ON cls.oid = con.conrelid
+INNER JOIN pg_namespace ns ON ns.oid = cls.relnamespace
+WHERE con.contype = 'c' AND cls.relname = $1`
+	if len(db.getSchema()) != 0 {
+		args = append(args, db.getSchema())
+		s += " AND ns.nspname = $2"
+	}
+
+	checks, err := queryChecks(queryer, ctx, s, args...)
+	if err != nil {
+		return nil, err
+	}
+	// the definitions are like CHECK ((price > 0))
+	for _, check := range checks {
+		expr := strings.TrimSpace(strings.TrimPrefix(check.Expr, "CHECK"))
+		if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+			expr = expr[1 : len(expr)-1]
+		}
+		check.Expr = expr
+	}
+	return checks, nil
+}
+
+// AddCheckSQL returns a SQL to add the CHECK constraint to the table
+func (db *postgres) AddCheckSQL(tableName string, check *schemas.Check) (string, error) {
+	return db.Base.AddCheckSQL(db.tableNameWithSchema(tableName), check)
+}
+
+// DropCheckSQL returns a SQL to drop the CHECK constraint of the table
+func (db *postgres) DropCheckSQL(tableName string, check *schemas.Check) (string, error) {
+	return db.Base.DropCheckSQL(db.tableNameWithSchema(tableName), check)
+}
+
+// AddForeignKeySQL returns a SQL to add the foreign key to the table
+func (db *postgres) AddForeignKeySQL(tableName string, fk *schemas.ForeignKey) (string, error) {
+	return db.Base.AddForeignKeySQL(db.tableNameWithSchema(tableName), fk)
+}
+
+// DropForeignKeySQL returns a SQL to drop the foreign key of the table
+func (db *postgres) DropForeignKeySQL(tableName string, fk *schemas.ForeignKey) (string, error) {
+	return db.Base.DropForeignKeySQL(db.tableNameWithSchema(tableName), fk)
+}
+
 func (db *postgres) CreateTableSQL(ctx context.Context, queryer core.Queryer, table *schemas.Table, tableName string) (string, bool, error) {
 	quoter := db.dialect.Quoter()
+	if tableName == "" {
+		tableName = table.Name
+	}
 	if len(db.getSchema()) != 0 && !strings.Contains(tableName, ".") {
 		tableName = fmt.Sprintf("%s.%s", db.getSchema(), tableName)
 	}
@@ -1366,6 +1539,10 @@ func (db *postgres) CreateTableSQL(ctx context.Context, queryer core.Queryer, ta
 	if err != nil {
 		return "", ok, err
 	}
+	if table.Partitioning != nil {
+		createTableSQL += fmt.Sprintf(" PARTITION BY %s (%s)", table.Partitioning.Type,
+			partitionKeysString(quoter, table.Partitioning))
+	}
 
 	commentSQL := "; "
 	if table.Comment != "" {
@@ -1381,7 +1558,111 @@ func (db *postgres) CreateTableSQL(ctx context.Context, queryer core.Queryer, ta
 		}
 	}
 
-	return createTableSQL + commentSQL, true, nil
+	// the partitions are tables which should be created after the partitioned table
+	var partitionSQL string
+	if table.Partitioning != nil {
+		for _, partition := range table.Partitioning.Partitions {
+			partitionSQL += db.CreatePartitionSQL(tableName, partition) + "; "
+		}
+	}
+
+	return createTableSQL + commentSQL + partitionSQL, true, nil
+}
+
+// postgresPartitionBound returns the bound of the partition
+func postgresPartitionBound(partition *schemas.Partition) string {
+	switch {
+	case partition.IsDefault:
+		return "DEFAULT"
+	case len(partition.Values) > 0:
+		return fmt.Sprintf("FOR VALUES IN (%s)", strings.Join(partition.Values, ", "))
+	case partition.Modulus > 0:
+		return fmt.Sprintf("FOR VALUES WITH (MODULUS %d, REMAINDER %d)", partition.Modulus, partition.Remainder)
+	}
+	return fmt.Sprintf("FOR VALUES FROM (%s) TO (%s)", partition.From, partition.To)
+}
+
+// CreatePartitionSQL returns a SQL to create the partition as a table
+func (db *postgres) CreatePartitionSQL(tableName string, partition *schemas.Partition) string {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s %s",
+		quoter.Quote(db.tableNameWithSchema(partition.Name)), quoter.Quote(db.tableNameWithSchema(tableName)),
+		postgresPartitionBound(partition))
+}
+
+// AttachPartitionSQL returns a SQL to attach the table as a partition
+func (db *postgres) AttachPartitionSQL(tableName string, partition *schemas.Partition) []string {
+	quoter := db.dialect.Quoter()
+	return []string{fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s %s",
+		quoter.Quote(db.tableNameWithSchema(tableName)), quoter.Quote(db.tableNameWithSchema(partition.Name)),
+		postgresPartitionBound(partition))}
+}
+
+// DetachPartitionSQL returns a SQL to detach the partition, which will be a standalone table
+func (db *postgres) DetachPartitionSQL(tableName, partitionName string) []string {
+	quoter := db.dialect.Quoter()
+	return []string{fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s",
+		quoter.Quote(db.tableNameWithSchema(tableName)), quoter.Quote(db.tableNameWithSchema(partitionName)))}
+}
+
+var (
+	postgresRangeBoundRegexp = regexp.MustCompile(`(?is)^\s*FOR\s+VALUES\s+FROM\s*\((.*)\)\s*TO\s*\((.*)\)\s*$`)
+	postgresListBoundRegexp  = regexp.MustCompile(`(?is)^\s*FOR\s+VALUES\s+IN\s*\((.*)\)\s*$`)
+	postgresHashBoundRegexp  = regexp.MustCompile(`(?is)^\s*FOR\s+VALUES\s+WITH\s*\(\s*MODULUS\s+(\d+)\s*,\s*REMAINDER\s+(\d+)\s*\)\s*$`)
+)
+
+// parsePartitionBound parses the bound returned by pg_get_expr into the partition
+func parsePartitionBound(bound string, partition *schemas.Partition) {
+	if strings.EqualFold(strings.TrimSpace(bound), "DEFAULT") {
+		partition.IsDefault = true
+	} else if matches := postgresRangeBoundRegexp.FindStringSubmatch(bound); matches != nil {
+		partition.From = strings.TrimSpace(matches[1])
+		partition.To = strings.TrimSpace(matches[2])
+	} else if matches := postgresListBoundRegexp.FindStringSubmatch(bound); matches != nil {
+		partition.Values = splitPartitionValues(matches[1])
+	} else if matches := postgresHashBoundRegexp.FindStringSubmatch(bound); matches != nil {
+		partition.Modulus, _ = strconv.Atoi(matches[1])
+		partition.Remainder, _ = strconv.Atoi(matches[2])
+	}
+}
+
+// GetPartitions returns the partitions of the partitioned table
+func (db *postgres) GetPartitions(queryer core.Queryer, ctx context.Context, tableName string) ([]*schemas.Partition, error) {
+	args := []interface{}{tableName}
+	s := `SELECT c.relname, pg_get_expr(c.relpartbound, c.oid) FROM pg_inherits i
+    JOIN pg_class c ON c.oid = i.inhrelid
+    JOIN pg_class p ON p.oid = i.inhparent
+    JOIN pg_namespace n ON n.oid = p.relnamespace
+WHERE p.relname = $1`
+	if schema := db.getSchema(); schema != "" {
+		args = append(args, schema)
+		s += " AND n.nspname = $2"
+	}
+	s += " ORDER BY c.relname"
+
+	rows, err := queryer.QueryContext(ctx, s, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partitions []*schemas.Partition
+	for rows.Next() {
+		var name string
+		var bound *string
+		if err := rows.Scan(&name, &bound); err != nil {
+			return nil, err
+		}
+		partition := &schemas.Partition{Name: name}
+		if bound != nil {
+			parsePartitionBound(*bound, partition)
+		}
+		partitions = append(partitions, partition)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return partitions, nil
 }
 
 func (db *postgres) Filters() []Filter {
@@ -1581,3 +1862,13 @@ func QueryDefaultPostgresSchema(ctx context.Context, queryer core.Queryer) (stri
 
 	return "", errors.New("no default schema")
 }
+
+// TryLockSQL returns a query to acquire the advisory lock keyed by the hash of the name
+func (db *postgres) TryLockSQL(name string) (string, []interface{}) {
+	return "SELECT CASE WHEN pg_try_advisory_lock($1) THEN 1 ELSE 0 END", []interface{}{lockKey(name)}
+}
+
+// UnlockSQL returns a query to release the advisory lock
+func (db *postgres) UnlockSQL(name string) (string, []interface{}) {
+	return "SELECT pg_advisory_unlock($1)", []interface{}{lockKey(name)}
+}
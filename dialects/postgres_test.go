@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm/schemas"
 )
 
 func TestParsePostgres(t *testing.T) {
@@ -83,36 +84,74 @@ func TestParsePgx(t *testing.T) {
 	}
 }
 
-func TestGetIndexColName(t *testing.T) {
+func TestParseIndexSQL(t *testing.T) {
+	parse := func(s string) *schemas.Index {
+		index := new(schemas.Index)
+		parseIndexSQL(s, index)
+		return index
+	}
+
 	t.Run("Index", func(t *testing.T) {
 		s := "CREATE INDEX test2_mm_idx ON test2 (major);"
-		colNames := getIndexColName(s)
-		assert.Equal(t, []string{"major"}, colNames)
+		assert.Equal(t, []string{"major"}, parse(s).Cols)
 	})
 
 	t.Run("Multicolumn indexes", func(t *testing.T) {
 		s := "CREATE INDEX test2_mm_idx ON test2 (major, minor);"
-		colNames := getIndexColName(s)
-		assert.Equal(t, []string{"major", "minor"}, colNames)
+		assert.Equal(t, []string{"major", "minor"}, parse(s).Cols)
 	})
 
 	t.Run("Indexes and ORDER BY", func(t *testing.T) {
 		s := "CREATE INDEX test2_mm_idx ON test2 (major  NULLS FIRST, minor DESC NULLS LAST);"
-		colNames := getIndexColName(s)
-		assert.Equal(t, []string{"major", "minor"}, colNames)
+		index := parse(s)
+		assert.Equal(t, []string{"major", "minor"}, index.Cols)
+		assert.False(t, index.IsDesc("major"))
+		assert.True(t, index.IsDesc("minor"))
 	})
 
 	t.Run("Combining Multiple Indexes", func(t *testing.T) {
 		s := "CREATE INDEX test2_mm_cm_idx ON public.test2 USING btree (major, minor) WHERE ((major <> 5) AND (minor <> 6))"
-		colNames := getIndexColName(s)
-		assert.Equal(t, []string{"major", "minor"}, colNames)
+		index := parse(s)
+		assert.Equal(t, []string{"major", "minor"}, index.Cols)
+		assert.Equal(t, "", index.Method)
+		assert.Equal(t, "(major <> 5) AND (minor <> 6)", index.Where)
 	})
 
 	t.Run("unique", func(t *testing.T) {
 		s := "CREATE UNIQUE INDEX test2_mm_uidx ON test2 (major);"
-		colNames := getIndexColName(s)
-		assert.Equal(t, []string{"major"}, colNames)
+		assert.Equal(t, []string{"major"}, parse(s).Cols)
+	})
+
+	t.Run("Indexes on Expressions", func(t *testing.T) {
+		s := `CREATE INDEX test1_lower_col1_idx ON public.test1 USING gin (lower((col1)::text), "col2" DESC)`
+		index := parse(s)
+		assert.Equal(t, []string{"lower((col1)::text)", "col2"}, index.Cols)
+		assert.Equal(t, "GIN", index.Method)
+		assert.True(t, index.IsDesc("col2"))
+		assert.True(t, index.Equal(&schemas.Index{
+			Cols:   []string{"lower(col1)", "col2"},
+			Method: "gin",
+			Desc:   map[string]bool{"col2": true},
+		}))
 	})
+}
+
+func TestParsePartitionBound(t *testing.T) {
+	var partition schemas.Partition
+	parsePartitionBound("FOR VALUES FROM ('2023-01-01 00:00:00') TO ('2024-01-01 00:00:00')", &partition)
+	assert.EqualValues(t, "'2023-01-01 00:00:00'", partition.From)
+	assert.EqualValues(t, "'2024-01-01 00:00:00'", partition.To)
+
+	partition = schemas.Partition{}
+	parsePartitionBound("FOR VALUES IN ('a', 'b,c')", &partition)
+	assert.EqualValues(t, []string{"'a'", "'b,c'"}, partition.Values)
+
+	partition = schemas.Partition{}
+	parsePartitionBound("FOR VALUES WITH (modulus 4, remainder 1)", &partition)
+	assert.EqualValues(t, 4, partition.Modulus)
+	assert.EqualValues(t, 1, partition.Remainder)
 
-	t.Run("Indexes on Expressions", func(t *testing.T) {})
+	partition = schemas.Partition{}
+	parsePartitionBound("DEFAULT", &partition)
+	assert.True(t, partition.IsDefault)
 }
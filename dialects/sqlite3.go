@@ -188,6 +188,7 @@ func (db *sqlite3) Version(ctx context.Context, queryer core.Queryer) (*schemas.
 func (db *sqlite3) Features() *DialectFeatures {
 	return &DialectFeatures{
 		AutoincrMode: IncrAutoincrMode,
+		MaxParams:    999, // SQLITE_MAX_VARIABLE_NUMBER before 3.32.0
 	}
 }
 
@@ -277,6 +278,22 @@ func (db *sqlite3) IsTableExist(queryer core.Queryer, ctx context.Context, table
 	return db.HasRecords(queryer, ctx, "SELECT name FROM sqlite_master WHERE type='table' and name = ?", tableName)
 }
 
+// CreateViewSQL returns the SQLs to create the view, SQLite cannot replace a view
+func (db *sqlite3) CreateViewSQL(viewName string, view *schemas.Table) []string {
+	quote := db.Quoter().Quote
+	return []string{
+		fmt.Sprintf("DROP VIEW IF EXISTS %s", quote(viewName)),
+		fmt.Sprintf("CREATE VIEW %s AS %s", quote(viewName), view.ViewDefinition),
+	}
+}
+
+// CreateIndexSQL returns a SQL to create index, SQLite has no index methods
+func (db *sqlite3) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	idx := *index
+	idx.Method = ""
+	return db.Base.CreateIndexSQL(tableName, &idx)
+}
+
 func (db *sqlite3) DropIndexSQL(tableName string, index *schemas.Index) string {
 	// var unique string
 	idxName := index.Name
@@ -292,6 +309,18 @@ func (db *sqlite3) DropIndexSQL(tableName string, index *schemas.Index) string {
 	return fmt.Sprintf("DROP INDEX %v", db.Quoter().Quote(idxName))
 }
 
+func (db *sqlite3) AlterColumnTypeSQL(tableName string, col *schemas.Column) (string, error) {
+	return "", ErrAlterColumnUnsupported
+}
+
+func (db *sqlite3) AlterColumnNullableSQL(tableName string, col *schemas.Column) (string, error) {
+	return "", ErrAlterColumnUnsupported
+}
+
+func (db *sqlite3) AlterColumnDefaultSQL(tableName string, col *schemas.Column) (string, error) {
+	return "", ErrAlterColumnUnsupported
+}
+
 func (db *sqlite3) IsColumnExist(queryer core.Queryer, ctx context.Context, tableName, colName string) (bool, error) {
 	query := "SELECT * FROM " + tableName + " LIMIT 0"
 	rows, err := queryer.QueryContext(ctx, query)
@@ -342,12 +371,115 @@ func splitColStr(colStr string) []string {
 	return results
 }
 
+// splitTableDefinitions splits the column definitions and table constraints of
+// CREATE TABLE by the commas which are not in brackets or quotes
+func splitTableDefinitions(s string) []string {
+	var (
+		results []string
+		depth   int
+		quote   rune
+		lastIdx int
+	)
+	for i, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			results = append(results, s[lastIdx:i])
+			lastIdx = i + 1
+		}
+	}
+	return append(results, s[lastIdx:])
+}
+
+// isTableConstraint returns true if the definition of CREATE TABLE is a constraint but not a column
+func isTableConstraint(def string) bool {
+	fields := strings.Fields(def)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "CONSTRAINT", "FOREIGN", "CHECK", "UNIQUE":
+		return true
+	}
+	return false
+}
+
+var sqliteGeneratedRegexp = regexp.MustCompile(`(?i)\s(GENERATED\s+ALWAYS\s+)?AS\s*\(`)
+
+// parseGenerated returns the column definition without the generated clause, and the
+// expression of the generated column, which is blank if the column is not generated
+func parseGenerated(colStr string) (string, string, bool) {
+	// mask the quoted strings so that they will not be matched
+	masked := []byte(colStr)
+	var quote byte
+	for i := 0; i < len(masked); i++ {
+		switch {
+		case quote != 0:
+			if masked[i] == quote {
+				quote = 0
+			} else {
+				masked[i] = '_'
+			}
+		case masked[i] == '\'' || masked[i] == '"' || masked[i] == '`':
+			quote = masked[i]
+		}
+	}
+
+	loc := sqliteGeneratedRegexp.FindIndex(masked)
+	if loc == nil {
+		return colStr, "", false
+	}
+	start := loc[1] - 1
+	end := -1
+	var depth int
+	for i := start; i < len(masked) && end < 0; i++ {
+		switch masked[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end < 0 {
+		return colStr, "", false
+	}
+
+	expr := strings.TrimSpace(colStr[start+1 : end])
+	rest := strings.TrimSpace(colStr[end+1:])
+	var stored bool
+	if fields := strings.Fields(rest); len(fields) > 0 {
+		switch strings.ToUpper(fields[0]) {
+		case "STORED":
+			stored = true
+			rest = strings.TrimSpace(rest[len(fields[0]):])
+		case "VIRTUAL":
+			rest = strings.TrimSpace(rest[len(fields[0]):])
+		}
+	}
+	return strings.TrimSpace(colStr[:loc[0]] + " " + rest), expr, stored
+}
+
 func parseString(colStr string) (*schemas.Column, error) {
+	colStr, generated, stored := parseGenerated(colStr)
 	fields := splitColStr(colStr)
 	col := new(schemas.Column)
 	col.Indexes = make(map[string]int)
 	col.Nullable = true
 	col.DefaultIsEmpty = true
+	col.Generated = generated
+	col.GeneratedStored = stored
 
 	for idx, field := range fields {
 		if idx == 0 {
@@ -376,13 +508,14 @@ func parseString(colStr string) (*schemas.Column, error) {
 	return col, nil
 }
 
-func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
+// getTableSQL returns the CREATE TABLE SQL of the table
+func (db *sqlite3) getTableSQL(queryer core.Queryer, ctx context.Context, tableName string) (string, error) {
 	args := []interface{}{tableName}
 	s := "SELECT sql FROM sqlite_master WHERE type='table' and name = ?"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
-		return nil, nil, err
+		return "", err
 	}
 	defer rows.Close()
 
@@ -390,29 +523,38 @@ func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableNa
 	if rows.Next() {
 		err = rows.Scan(&name)
 		if err != nil {
-			return nil, nil, err
+			return "", err
 		}
 	}
 	if rows.Err() != nil {
-		return nil, nil, rows.Err()
+		return "", rows.Err()
 	}
 
 	if name == "" {
-		return nil, nil, errors.New("no table named " + tableName)
+		return "", errors.New("no table named " + tableName)
 	}
 
-	name = strings.ReplaceAll(name, "\n", " ")
+	return strings.ReplaceAll(name, "\n", " "), nil
+}
+
+func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
+	name, err := db.getTableSQL(queryer, ctx, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	nStart := strings.Index(name, "(")
 	nEnd := strings.LastIndex(name, ")")
-	reg := regexp.MustCompile(`[^\(,\)]*(\([^\(]*\))?`)
-	colCreates := reg.FindAllString(name[nStart+1:nEnd], -1)
+	colCreates := splitTableDefinitions(name[nStart+1 : nEnd])
 	cols := make(map[string]*schemas.Column)
 	colSeq := make([]string, 0)
 
 	for _, colStr := range colCreates {
-		reg = regexp.MustCompile(`,\s`)
+		reg := regexp.MustCompile(`,\s`)
 		colStr = reg.ReplaceAllString(colStr, ",")
+		if isTableConstraint(colStr) {
+			continue
+		}
 		if strings.HasPrefix(strings.TrimSpace(colStr), "PRIMARY KEY") {
 			parts := strings.Split(strings.TrimSpace(colStr), "(")
 			if len(parts) == 2 {
@@ -437,6 +579,145 @@ func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableNa
 	return colSeq, cols, nil
 }
 
+var (
+	sqliteForeignKeyRegexp       = regexp.MustCompile("(?is)^\\s*CONSTRAINT\\s+[`\"\\[]?([^`\"\\]\\s]+)[`\"\\]]?\\s+FOREIGN\\s+KEY\\s*\\(([^)]*)\\)")
+	sqliteColumnForeignKeyRegexp = regexp.MustCompile("(?is)^\\s*[`\"\\[]?([^`\"\\]\\s]+)[`\"\\]]?\\s.*\\bCONSTRAINT\\s+[`\"\\[]?([^`\"\\]\\s]+)[`\"\\]]?\\s+REFERENCES\\b")
+)
+
+// GetForeignKeys returns the foreign keys of the table, since SQLite doesn't keep the names of
+// the foreign keys, they are parsed from the SQL of the table. The unnamed foreign keys have empty
+// names and are keyed by the columns.
+func (db *sqlite3) GetForeignKeys(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.ForeignKey, error) {
+	s := "SELECT `id`, `from`, `table`, `to`, `on_update`, `on_delete` FROM pragma_foreign_key_list(?) ORDER BY `id`, `seq`"
+	fks, err := queryForeignKeys(queryer, ctx, s, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(fks) == 0 {
+		return map[string]*schemas.ForeignKey{}, nil
+	}
+
+	tableSQL, err := db.getTableSQL(queryer, ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string)
+	nStart := strings.Index(tableSQL, "(")
+	nEnd := strings.LastIndex(tableSQL, ")")
+	for _, def := range splitTableDefinitions(tableSQL[nStart+1 : nEnd]) {
+		if matches := sqliteForeignKeyRegexp.FindStringSubmatch(def); matches != nil {
+			cols := strings.Split(matches[2], ",")
+			for i, col := range cols {
+				cols[i] = strings.Trim(strings.TrimSpace(col), "`\"[]")
+			}
+			names[strings.ToLower(strings.Join(cols, ","))] = matches[1]
+		} else if matches := sqliteColumnForeignKeyRegexp.FindStringSubmatch(def); matches != nil {
+			names[strings.ToLower(matches[1])] = matches[2]
+		}
+	}
+
+	namedFks := make(map[string]*schemas.ForeignKey, len(fks))
+	for _, fk := range fks {
+		fk.Name = names[strings.ToLower(strings.Join(fk.Cols, ","))]
+		if fk.Name != "" {
+			namedFks[fk.Name] = fk
+		} else {
+			namedFks[strings.Join(fk.Cols, "_")] = fk
+		}
+	}
+	return namedFks, nil
+}
+
+var sqliteCheckRegexp = regexp.MustCompile("(?is)(?:\\bCONSTRAINT\\s+[`\"\\[]?([^`\"\\]\\s]+)[`\"\\]]?\\s+)?\\bCHECK\\s*\\(")
+
+// GetChecks returns the CHECK constraints of the table, including the ones of the columns. The
+// unnamed constraints have empty names and are keyed by the expressions.
+func (db *sqlite3) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	name, err := db.getTableSQL(queryer, ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make(map[string]*schemas.Check)
+	nStart := strings.Index(name, "(")
+	nEnd := strings.LastIndex(name, ")")
+	for _, def := range splitTableDefinitions(name[nStart+1 : nEnd]) {
+		for _, check := range parseChecks(def) {
+			if check.Name != "" {
+				checks[check.Name] = check
+			} else {
+				checks[check.Expr] = check
+			}
+		}
+	}
+	return checks, nil
+}
+
+// parseChecks returns the CHECK constraints of a column definition or a table constraint
+func parseChecks(def string) []*schemas.Check {
+	var checks []*schemas.Check
+	for _, loc := range sqliteCheckRegexp.FindAllStringSubmatchIndex(def, -1) {
+		exprEnd := closingBracketIndex(def, loc[1]-1)
+		if exprEnd < 0 {
+			continue
+		}
+		check := schemas.NewCheck("", strings.TrimSpace(def[loc[1]:exprEnd]))
+		check.IsRegular = false
+		if loc[2] >= 0 {
+			check.Name = def[loc[2]:loc[3]]
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// closingBracketIndex returns the index of the bracket closing the one at start, the brackets
+// in quotes are skipped, -1 will be returned if it's not closed
+func closingBracketIndex(s string, start int) int {
+	var (
+		depth int
+		quote rune
+	)
+	for i, c := range s[start:] {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return start + i
+			}
+		}
+	}
+	return -1
+}
+
+// AddCheckSQL returns ErrAlterConstraintUnsupported since SQLite cannot add constraints to an existing table
+func (db *sqlite3) AddCheckSQL(tableName string, check *schemas.Check) (string, error) {
+	return "", ErrAlterConstraintUnsupported
+}
+
+// DropCheckSQL returns ErrAlterConstraintUnsupported since SQLite cannot drop constraints of an existing table
+func (db *sqlite3) DropCheckSQL(tableName string, check *schemas.Check) (string, error) {
+	return "", ErrAlterConstraintUnsupported
+}
+
+// AddForeignKeySQL returns ErrAlterConstraintUnsupported since SQLite cannot add foreign keys to an existing table
+func (db *sqlite3) AddForeignKeySQL(tableName string, fk *schemas.ForeignKey) (string, error) {
+	return "", ErrAlterConstraintUnsupported
+}
+
+// DropForeignKeySQL returns ErrAlterConstraintUnsupported since SQLite cannot drop foreign keys of an existing table
+func (db *sqlite3) DropForeignKeySQL(tableName string, fk *schemas.ForeignKey) (string, error) {
+	return "", ErrAlterConstraintUnsupported
+}
+
 func (db *sqlite3) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
 	args := []interface{}{}
 	s := "SELECT name FROM sqlite_master WHERE type='table'"
@@ -462,7 +743,12 @@ func (db *sqlite3) GetTables(queryer core.Queryer, ctx context.Context) ([]*sche
 	if rows.Err() != nil {
 		return nil, rows.Err()
 	}
-	return tables, nil
+
+	views, err := queryViews(queryer, ctx, false, "SELECT name, sql FROM sqlite_master WHERE type='view'")
+	if err != nil {
+		return nil, err
+	}
+	return append(tables, views...), nil
 }
 
 func (db *sqlite3) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
@@ -510,14 +796,7 @@ func (db *sqlite3) GetIndexes(queryer core.Queryer, ctx context.Context, tableNa
 			index.Type = schemas.IndexType
 		}
 
-		nStart := strings.Index(sql, "(")
-		nEnd := strings.Index(sql, ")")
-		colIndexes := strings.Split(sql[nStart+1:nEnd], ",")
-
-		index.Cols = make([]string, 0)
-		for _, col := range colIndexes {
-			index.Cols = append(index.Cols, strings.Trim(col, "` []"))
-		}
+		parseIndexSQL(sql, index)
 		index.IsRegular = isRegular
 		indexes[index.Name] = index
 	}
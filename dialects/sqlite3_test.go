@@ -40,3 +40,55 @@ primary key autoincrement`,
 		assert.EqualValues(t, kase.fields, splitColStr(kase.colStr))
 	}
 }
+
+func TestSplitTableDefinitions(t *testing.T) {
+	defs := splitTableDefinitions("`id` INTEGER PRIMARY KEY, `price` NUMERIC(10,2) DEFAULT 0 NOT NULL, " +
+		"`name` TEXT DEFAULT 'a,b' NULL, CONSTRAINT `FK_t_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE")
+	assert.EqualValues(t, 4, len(defs))
+	assert.EqualValues(t, " `price` NUMERIC(10,2) DEFAULT 0 NOT NULL", defs[1])
+	assert.EqualValues(t, " `name` TEXT DEFAULT 'a,b' NULL", defs[2])
+	assert.False(t, isTableConstraint(defs[2]))
+	assert.True(t, isTableConstraint(defs[3]))
+
+	checks := parseChecks(" CONSTRAINT `CHK_t_price` CHECK (price >= 0 AND (price < 10))")
+	assert.Len(t, checks, 1)
+	assert.EqualValues(t, "CHK_t_price", checks[0].Name)
+	assert.EqualValues(t, "price >= 0 AND (price < 10)", checks[0].Expr)
+	assert.False(t, checks[0].IsRegular)
+	assert.Empty(t, parseChecks(" CONSTRAINT `FK_t_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)"))
+
+	// the checks of columns and the unnamed checks
+	checks = parseChecks(" `price` INTEGER NOT NULL CHECK (price > 0) CONSTRAINT price_max CHECK (price < ')')")
+	assert.Len(t, checks, 2)
+	assert.EqualValues(t, "", checks[0].Name)
+	assert.EqualValues(t, "price > 0", checks[0].Expr)
+	assert.EqualValues(t, "price_max", checks[1].Name)
+	assert.EqualValues(t, "price < ')'", checks[1].Expr)
+
+	matches := sqliteForeignKeyRegexp.FindStringSubmatch(" CONSTRAINT `FK_t_user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)")
+	assert.EqualValues(t, []string{"FK_t_user_id", "`user_id`"}, matches[1:])
+	matches = sqliteColumnForeignKeyRegexp.FindStringSubmatch(" `user_id` INTEGER CONSTRAINT fk_user REFERENCES `user` (`id`)")
+	assert.EqualValues(t, []string{"user_id", "fk_user"}, matches[1:])
+	assert.Nil(t, sqliteColumnForeignKeyRegexp.FindStringSubmatch(" `user_id` INTEGER REFERENCES `user` (`id`)"))
+}
+
+func TestParseGenerated(t *testing.T) {
+	col, err := parseString("`total` INTEGER GENERATED ALWAYS AS (price * (qty + 1)) STORED NOT NULL")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "total", col.Name)
+	assert.EqualValues(t, "INTEGER", col.SQLType.Name)
+	assert.EqualValues(t, "price * (qty + 1)", col.Generated)
+	assert.True(t, col.GeneratedStored)
+	assert.False(t, col.Nullable)
+
+	col, err = parseString("`title` TEXT AS (upper(name)) NULL")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "upper(name)", col.Generated)
+	assert.False(t, col.GeneratedStored)
+	assert.True(t, col.Nullable)
+
+	col, err = parseString("`name` TEXT DEFAULT ' AS (a)' NULL")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "", col.Generated)
+	assert.EqualValues(t, "' AS (a)'", col.Default)
+}
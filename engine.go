@@ -47,6 +47,8 @@ type Engine struct {
 	DatabaseTZ *time.Location // The timezone of the database
 
 	logSessionID bool // create session id
+
+	lockTTL time.Duration // the time to live of the named locks kept in the lock table
 }
 
 // NewEngine new a db manager according to the parameter. Currently support four
@@ -87,6 +89,7 @@ func newEngine(driverName, dataSourceName string, dialect dialects.Dialect, db *
 		dataSourceName: dataSourceName,
 		db:             db,
 		logSessionID:   false,
+		lockTTL:        DefaultLockTTL,
 	}
 
 	if dialect.URI().DBType == schemas.SQLITE {
@@ -367,6 +370,11 @@ func (engine *Engine) NoAutoCondition(no ...bool) *Session {
 }
 
 func (engine *Engine) loadTableInfo(ctx context.Context, table *schemas.Table) error {
+	// the columns of views are not loaded
+	if table.IsView {
+		return nil
+	}
+
 	colSeq, cols, err := engine.dialect.GetColumns(engine.db, ctx, table.Name)
 	if err != nil {
 		return err
@@ -380,9 +388,24 @@ func (engine *Engine) loadTableInfo(ctx context.Context, table *schemas.Table) e
 	}
 	table.Indexes = indexes
 
+	foreignKeys, err := engine.dialect.GetForeignKeys(engine.db, ctx, table.Name)
+	if err != nil {
+		return err
+	}
+	table.ForeignKeys = foreignKeys
+
+	checks, err := engine.dialect.GetChecks(engine.db, ctx, table.Name)
+	if err != nil {
+		return err
+	}
+	table.Checks = checks
+
 	var seq int
 	for _, index := range indexes {
 		for _, name := range index.Cols {
+			if schemas.IsIndexExpr(name) {
+				continue
+			}
 			parts := strings.Split(strings.TrimSpace(name), " ")
 			if len(parts) > 1 {
 				if parts[1] == "DESC" {
@@ -503,7 +526,23 @@ func (engine *Engine) dumpTables(ctx context.Context, tables []*schemas.Table, w
 		}
 	}
 
-	for i, table := range tables {
+	// the referenced tables should be created and filled before the tables reference them,
+	// and the views should be created after all the tables
+	sortedTables := make([]*schemas.Table, 0, len(tables))
+	var views []*schemas.Table
+	for _, i := range schemas.SortTablesByForeignKeys(tables) {
+		if tables[i].PartitionOf != "" {
+			// the data of the partitions will be dumped with the partitioned tables
+			continue
+		}
+		if tables[i].IsView {
+			views = append(views, tables[i])
+		} else {
+			sortedTables = append(sortedTables, tables[i])
+		}
+	}
+
+	for i, table := range sortedTables {
 		dstTable := table
 		if table.Type != nil {
 			dstTable, err = dstTableCache.Parse(reflect.New(table.Type).Elem())
@@ -830,6 +869,21 @@ func (engine *Engine) dumpTables(ctx context.Context, tables []*schemas.Table, w
 		rows.Close()
 		sess.Close()
 	}
+
+	for _, view := range views {
+		viewName := view.Name
+		if dstDialect.URI().Schema != "" {
+			viewName = fmt.Sprintf("%s.%s", dstDialect.URI().Schema, view.Name)
+		}
+		if _, err = io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+		for _, s := range dstDialect.CreateViewSQL(viewName, view) {
+			if _, err = io.WriteString(w, s+";\n"); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -958,6 +1012,20 @@ func (engine *Engine) NotIn(column string, args ...interface{}) *Session {
 	return session.NotIn(column, args...)
 }
 
+// WhereExists will generate "EXISTS (SELECT ...)" with the query of another session
+func (engine *Engine) WhereExists(query *Session) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereExists(query)
+}
+
+// WhereNotExists will generate "NOT EXISTS (SELECT ...)" with the query of another session
+func (engine *Engine) WhereNotExists(query *Session) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WhereNotExists(query)
+}
+
 // Incr provides a update string like "column = column + ?"
 func (engine *Engine) Incr(column string, arg ...interface{}) *Session {
 	session := engine.NewSession()
@@ -979,6 +1047,28 @@ func (engine *Engine) SetExpr(column string, expression interface{}) *Session {
 	return session.SetExpr(column, expression)
 }
 
+// OnConflict makes the following inserts upsert, see Session.OnConflict
+func (engine *Engine) OnConflict(cols ...string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.OnConflict(cols...)
+}
+
+// Returning makes the following insert, update or delete return the columns, see Session.Returning
+func (engine *Engine) Returning(cols ...string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Returning(cols...)
+}
+
+// ReturningInto appends the returned rows of the following update or delete to the slice,
+// see Session.ReturningInto
+func (engine *Engine) ReturningInto(rowsSlicePtr interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.ReturningInto(rowsSlicePtr)
+}
+
 // Table temporarily change the Get, Find, Update's table
 func (engine *Engine) Table(tableNameOrBean interface{}) *Session {
 	session := engine.NewSession()
@@ -1039,6 +1129,48 @@ func (engine *Engine) Join(joinOperator string, tablename interface{}, condition
 	return session.Join(joinOperator, tablename, condition, args...)
 }
 
+// With adds a common table expression before the query, see Session.With
+func (engine *Engine) With(name string, query interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.With(name, query)
+}
+
+// WithRecursive adds a recursive common table expression before the query, see Session.WithRecursive
+func (engine *Engine) WithRecursive(name string, query interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WithRecursive(name, query)
+}
+
+// Union combines the rows of the queries and removes the duplicated rows, see Session.Union
+func (engine *Engine) Union(queries ...*Session) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Union(queries...)
+}
+
+// UnionAll combines the rows of the queries and keeps the duplicated rows, see Session.Union
+func (engine *Engine) UnionAll(queries ...*Session) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.UnionAll(queries...)
+}
+
+// Intersect returns the rows returned by all the queries, see Session.Union
+func (engine *Engine) Intersect(queries ...*Session) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Intersect(queries...)
+}
+
+// Except returns the rows returned by the first query but not by the others, see Session.Union
+func (engine *Engine) Except(queries ...*Session) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Except(queries...)
+}
+
 // GroupBy generate group by statement
 func (engine *Engine) GroupBy(keys string) *Session {
 	session := engine.NewSession()
@@ -1078,6 +1210,41 @@ func (engine *Engine) IsTableExist(beanOrTableName interface{}) (bool, error) {
 	return session.IsTableExist(beanOrTableName)
 }
 
+// RefreshMaterializedView refreshes the data of a materialized view, it's only supported by Postgres
+func (engine *Engine) RefreshMaterializedView(beanOrViewName interface{}, concurrently bool) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.RefreshMaterializedView(beanOrViewName, concurrently)
+}
+
+// CreatePartition creates a new partition of the partitioned table
+func (engine *Engine) CreatePartition(beanOrTableName interface{}, partition *schemas.Partition) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.CreatePartition(beanOrTableName, partition)
+}
+
+// AttachPartition attaches the table named by the partition to the partitioned table
+func (engine *Engine) AttachPartition(beanOrTableName interface{}, partition *schemas.Partition) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.AttachPartition(beanOrTableName, partition)
+}
+
+// DetachPartition detaches the partition from the partitioned table as a standalone table
+func (engine *Engine) DetachPartition(beanOrTableName interface{}, partitionName string) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.DetachPartition(beanOrTableName, partitionName)
+}
+
+// GetPartitions returns the partitions of the partitioned table
+func (engine *Engine) GetPartitions(beanOrTableName interface{}) ([]*schemas.Partition, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.GetPartitions(beanOrTableName)
+}
+
 // TableName returns table name with schema prefix if has
 func (engine *Engine) TableName(bean interface{}, includeSchema ...bool) string {
 	return dialects.FullTableName(engine.dialect, engine.GetTableMapper(), bean, includeSchema...)
@@ -1136,16 +1303,86 @@ func (engine *Engine) CreateTables(beans ...interface{}) error {
 		return err
 	}
 
-	for _, bean := range beans {
-		err = session.createTable(bean)
+	beans, circularFKs, err := engine.sortBeansByDependencies(beans)
+	if err != nil {
+		_ = session.Rollback()
+		return err
+	}
+
+	var addFKSQLs []string
+	for i, bean := range beans {
+		err = session.createTable(bean, circularFKs[i]...)
 		if err != nil {
 			_ = session.Rollback()
 			return err
 		}
+		if len(circularFKs[i]) == 0 {
+			continue
+		}
+		table, err := engine.tagParser.ParseWithCache(utils.ReflectValue(bean))
+		if err != nil {
+			_ = session.Rollback()
+			return err
+		}
+		for _, name := range circularFKs[i] {
+			sql, err := engine.dialect.AddForeignKeySQL(engine.TableName(bean, true), table.ForeignKeys[name])
+			if err != nil {
+				_ = session.Rollback()
+				return err
+			}
+			addFKSQLs = append(addFKSQLs, sql)
+		}
+	}
+
+	// the circular foreign keys are added after all the tables created
+	for _, sql := range addFKSQLs {
+		if _, err = session.exec(sql); err != nil {
+			_ = session.Rollback()
+			return err
+		}
 	}
 	return session.Commit()
 }
 
+// sortBeansByDependencies sorts the beans so that the referenced tables are in front of
+// the tables reference them, and the views are behind all the tables. The names of the
+// circular foreign keys of the sorted beans are returned too, they should be added after
+// all the tables created, see schemas.CircularForeignKeys.
+func (engine *Engine) sortBeansByDependencies(beans []interface{}) ([]interface{}, [][]string, error) {
+	tables := make([]*schemas.Table, 0, len(beans))
+	for _, bean := range beans {
+		table, err := engine.tagParser.ParseWithCache(utils.ReflectValue(bean))
+		if err != nil {
+			return nil, nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	order := schemas.SortTablesByForeignKeys(tables)
+	circularFKs := schemas.CircularForeignKeys(tables, order)
+	// the databases cannot add foreign keys to existing tables, i.e. SQLite, allow creating
+	// the foreign keys reference the tables not created
+	if _, err := engine.dialect.AddForeignKeySQL("", schemas.NewForeignKey("", "")); err == dialects.ErrAlterConstraintUnsupported {
+		circularFKs = nil
+	}
+
+	sorted := make([]interface{}, 0, len(beans))
+	sortedFKs := make([][]string, 0, len(beans))
+	var views []interface{}
+	for _, i := range order {
+		if tables[i].IsView {
+			views = append(views, beans[i])
+		} else {
+			sorted = append(sorted, beans[i])
+			sortedFKs = append(sortedFKs, circularFKs[i])
+		}
+	}
+	for range views {
+		sortedFKs = append(sortedFKs, nil)
+	}
+	return append(sorted, views...), sortedFKs, nil
+}
+
 // DropTables drop specify tables
 func (engine *Engine) DropTables(beans ...interface{}) error {
 	session := engine.NewSession()
@@ -1275,6 +1512,13 @@ func (engine *Engine) FindAndCount(rowsSlicePtr interface{}, condiBean ...interf
 	return session.FindAndCount(rowsSlicePtr, condiBean...)
 }
 
+// FindPage finds a page of rows after the cursor by keyset pagination, see Session.FindPage
+func (engine *Engine) FindPage(rowsSlicePtr interface{}, size int, cursor *Cursor, condiBean ...interface{}) (*Cursor, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.FindPage(rowsSlicePtr, size, cursor, condiBean...)
+}
+
 // Iterate record by record handle records from table, bean's non-empty fields
 // are conditions.
 func (engine *Engine) Iterate(bean interface{}, fun IterFunc) error {
@@ -1407,6 +1651,13 @@ func (engine *Engine) Context(ctx context.Context) *Session {
 	return session.Context(ctx)
 }
 
+// SetLockTTL sets the time to live of the named locks kept in the lock table, the lock not refreshed
+// in the TTL is regarded as held by an exited process and will be taken over by others. The lock is
+// refreshed every third of the TTL while it's held, and it never expires if the TTL is not positive.
+func (engine *Engine) SetLockTTL(ttl time.Duration) {
+	engine.lockTTL = ttl
+}
+
 // SetDefaultContext set the default context
 func (engine *Engine) SetDefaultContext(ctx context.Context) {
 	engine.defaultContext = ctx
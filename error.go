@@ -23,4 +23,12 @@ var (
 	ErrCacheFailed = errors.New("Cache failed")
 	// ErrConditionType condition type unsupported
 	ErrConditionType = errors.New("Unsupported condition type")
+	// ErrViewReadOnly the table of the bean is a view and cannot be written
+	ErrViewReadOnly = errors.New("View is read only")
+	// ErrMaterializedViewUnsupported the database has no materialized views
+	ErrMaterializedViewUnsupported = errors.New("Materialized view is not supported")
+	// ErrPartitionUnsupported the database dialect has no partitioning support
+	ErrPartitionUnsupported = errors.New("Partition is not supported")
+	// ErrLockTimeout the named lock is not acquired before the deadline
+	ErrLockTimeout = errors.New("Lock timeout")
 )
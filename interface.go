@@ -34,16 +34,19 @@ type Interface interface {
 	Truncate(...interface{}) (int64, error)
 	Distinct(columns ...string) *Session
 	DropIndexes(bean interface{}) error
+	Except(queries ...*Session) *Session
 	Exec(sqlOrArgs ...interface{}) (sql.Result, error)
 	Exist(bean ...interface{}) (bool, error)
 	Find(interface{}, ...interface{}) error
 	FindAndCount(interface{}, ...interface{}) (int64, error)
+	FindPage(rowsSlicePtr interface{}, size int, cursor *Cursor, condiBean ...interface{}) (*Cursor, error)
 	Get(...interface{}) (bool, error)
 	GroupBy(keys string) *Session
 	ID(interface{}) *Session
 	In(string, ...interface{}) *Session
 	Incr(column string, arg ...interface{}) *Session
 	Insert(...interface{}) (int64, error)
+	Intersect(queries ...*Session) *Session
 	InsertOne(interface{}) (int64, error)
 	IsTableEmpty(bean interface{}) (bool, error)
 	IsTableExist(beanOrTableName interface{}) (bool, error)
@@ -53,6 +56,7 @@ type Interface interface {
 	NoAutoCondition(...bool) *Session
 	NotIn(string, ...interface{}) *Session
 	Nullable(...string) *Session
+	OnConflict(cols ...string) *Session
 	Join(joinOperator string, tablename interface{}, condition interface{}, args ...interface{}) *Session
 	Omit(columns ...string) *Session
 	OrderBy(order interface{}, args ...interface{}) *Session
@@ -60,6 +64,8 @@ type Interface interface {
 	Query(sqlOrArgs ...interface{}) (resultsSlice []map[string][]byte, err error)
 	QueryInterface(sqlOrArgs ...interface{}) ([]map[string]interface{}, error)
 	QueryString(sqlOrArgs ...interface{}) ([]map[string]string, error)
+	Returning(cols ...string) *Session
+	ReturningInto(rowsSlicePtr interface{}) *Session
 	Rows(bean interface{}) (*Rows, error)
 	SetExpr(string, interface{}) *Session
 	Select(string) *Session
@@ -69,23 +75,32 @@ type Interface interface {
 	Sums(bean interface{}, colNames ...string) ([]float64, error)
 	SumsInt(bean interface{}, colNames ...string) ([]int64, error)
 	Table(tableNameOrBean interface{}) *Session
+	Union(queries ...*Session) *Session
+	UnionAll(queries ...*Session) *Session
 	Unscoped() *Session
 	Update(bean interface{}, condiBeans ...interface{}) (int64, error)
 	UseBool(...string) *Session
 	Where(interface{}, ...interface{}) *Session
+	WhereExists(query *Session) *Session
+	WhereNotExists(query *Session) *Session
+	With(name string, query interface{}) *Session
+	WithRecursive(name string, query interface{}) *Session
 }
 
 // EngineInterface defines the interface which Engine, EngineGroup will implementate.
 type EngineInterface interface {
 	Interface
 
+	AttachPartition(beanOrTableName interface{}, partition *schemas.Partition) error
 	Before(func(interface{})) *Session
 	Charset(charset string) *Session
 	ClearCache(...interface{}) error
 	Context(context.Context) *Session
+	CreatePartition(beanOrTableName interface{}, partition *schemas.Partition) error
 	CreateTables(...interface{}) error
 	DBMetas() ([]*schemas.Table, error)
 	DBVersion() (*schemas.Version, error)
+	DetachPartition(beanOrTableName interface{}, partitionName string) error
 	Dialect() dialects.Dialect
 	DriverName() string
 	DropTables(...interface{}) error
@@ -93,20 +108,24 @@ type EngineInterface interface {
 	GetCacher(string) caches.Cacher
 	GetColumnMapper() names.Mapper
 	GetDefaultCacher() caches.Cacher
+	GetPartitions(beanOrTableName interface{}) ([]*schemas.Partition, error)
 	GetTableMapper() names.Mapper
 	GetTZDatabase() *time.Location
 	GetTZLocation() *time.Location
 	ImportFile(fp string) ([]sql.Result, error)
+	Lock(ctx context.Context, name string) (*NamedLock, error)
 	MapCacher(interface{}, caches.Cacher) error
 	NewSession() *Session
 	NoAutoTime() *Session
 	Prepare() *Session
 	Quote(string) string
+	RefreshMaterializedView(beanOrViewName interface{}, concurrently bool) error
 	SetCacher(string, caches.Cacher)
 	SetConnMaxLifetime(time.Duration)
 	SetColumnMapper(names.Mapper)
 	SetTagIdentifier(string)
 	SetDefaultCacher(caches.Cacher)
+	SetLockTTL(time.Duration)
 	SetLogger(logger interface{})
 	SetLogLevel(log.LogLevel)
 	SetMapper(names.Mapper)
@@ -0,0 +1,137 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"fmt"
+	"strings"
+
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+// the operators of compound queries
+const (
+	CompoundUnion     = "UNION"
+	CompoundUnionAll  = "UNION ALL"
+	CompoundIntersect = "INTERSECT"
+	CompoundExcept    = "EXCEPT"
+)
+
+// compoundAlias is the alias of the combined rows when they are queried as a table
+const compoundAlias = "xc"
+
+type compoundQuery struct {
+	op    string
+	query *Statement
+}
+
+// Compound combines the rows of the query with the previous queries by the operator, the
+// operator of the first query is ignored. The conditions, columns, orders and pagination of
+// the statement itself are applied to the combined rows.
+func (statement *Statement) Compound(op string, query *Statement) *Statement {
+	statement.compounds = append(statement.compounds, compoundQuery{
+		op:    op,
+		query: query,
+	})
+	return statement
+}
+
+// IsCompound returns true if the statement combines the rows of other queries
+func (statement *Statement) IsCompound() bool {
+	return len(statement.compounds) > 0
+}
+
+// isBareCompound returns true if the compound query could be written without being queried as
+// a table, i.e. only orders and pagination are applied to the combined rows
+func (statement *Statement) isBareCompound() bool {
+	return statement.IsCompound() &&
+		!statement.cond.IsValid() &&
+		len(statement.joins) == 0 &&
+		statement.GroupByStr == "" &&
+		statement.HavingStr == "" &&
+		statement.SelectStr == "" &&
+		statement.ColumnStr() == "" &&
+		!statement.IsDistinct &&
+		!statement.IsForUpdate &&
+		!statement.isUsingLegacyLimitOffset()
+}
+
+// compoundOperator returns the operator in the dialect
+func (statement *Statement) compoundOperator(op string) string {
+	if op == CompoundExcept && statement.dialect.URI().DBType == schemas.ORACLE {
+		return "MINUS"
+	}
+	return op
+}
+
+// writeCompound writes the combined queries. The queries with orders or pagination are queried
+// as tables because not all the databases support parentheses around them, and the previous
+// queries are queried as a table if the operator changes, so the queries are always combined
+// from left to right whatever the precedences of the operators in the database are.
+func (statement *Statement) writeCompound(w *builder.BytesWriter) error {
+	var buf strings.Builder
+	var args []interface{}
+	for i, compound := range statement.compounds {
+		sql, queryArgs, err := compound.query.GenSubQuerySQL()
+		if err != nil {
+			return err
+		}
+		if compound.query.isSubQueryOrdered() || compound.query.LimitN != nil || compound.query.Start > 0 {
+			sql = fmt.Sprintf("SELECT * FROM (%s) %s%d", sql, compoundAlias, i)
+		}
+
+		if i > 0 {
+			if i > 1 && compound.op != statement.compounds[i-1].op {
+				prev := buf.String()
+				buf.Reset()
+				fmt.Fprintf(&buf, "SELECT * FROM (%s) %s%d", prev, compoundAlias, i-1)
+			}
+			fmt.Fprint(&buf, " ", statement.compoundOperator(compound.op), " ")
+		}
+		buf.WriteString(sql)
+		args = append(args, queryArgs...)
+	}
+
+	if _, err := w.WriteString(buf.String()); err != nil {
+		return err
+	}
+	w.Append(args...)
+	return nil
+}
+
+// writeCompoundTable writes the combined queries as a table, the alias will be the table name
+// of the bean if there is no alias
+func (statement *Statement) writeCompoundTable(w *builder.BytesWriter) error {
+	if _, err := w.WriteString("("); err != nil {
+		return err
+	}
+	if err := statement.writeCompound(w); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(")"); err != nil {
+		return err
+	}
+	if statement.TableAlias != "" {
+		// the alias will be written by writeAlias
+		return nil
+	}
+
+	alias := compoundAlias
+	if tableName := statement.TableName(); tableName != "" {
+		fields := strings.Split(tableName, ".")
+		alias = statement.dialect.Quoter().Trim(fields[len(fields)-1])
+	}
+	return statement.writeTableAlias(w, alias)
+}
+
+// writeCompoundSelect writes the combined queries with the orders and pagination
+func (statement *Statement) writeCompoundSelect(w *builder.BytesWriter) error {
+	return statement.writeMultiple(w,
+		statement.writeCompound,
+		statement.writeSelectOrderBys(false),
+		statement.writePagination,
+	)
+}
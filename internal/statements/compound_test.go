@@ -0,0 +1,81 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+func TestCompoundSQL(t *testing.T) {
+	newQuery := func(dbType schemas.DBType, name string) *Statement {
+		query := newUpsertStatement(t, dbType)
+		query.Where("name = ?", name)
+		return query
+	}
+
+	kases := []struct {
+		dbType   schemas.DBType
+		expected string
+	}{
+		{
+			schemas.MSSQL,
+			"SELECT * FROM (SELECT [id], [name], [email], [created], [updated], [version] FROM [upsert_user] WHERE (name = ?) UNION ALL SELECT [id], [name], [email], [created], [updated], [version] FROM [upsert_user] WHERE (name = ?)) xc1 EXCEPT SELECT [id], [name], [email], [created], [updated], [version] FROM [upsert_user] WHERE (name = ?) ORDER BY [id] ASC OFFSET 5 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			schemas.ORACLE,
+			`SELECT * FROM (SELECT "id", "name", "email", "created", "updated", "version" FROM "upsert_user" WHERE (name = ?) UNION ALL SELECT "id", "name", "email", "created", "updated", "version" FROM "upsert_user" WHERE (name = ?)) xc1 MINUS SELECT "id", "name", "email", "created", "updated", "version" FROM "upsert_user" WHERE (name = ?) OFFSET 5 ROWS FETCH NEXT 10 ROWS ONLY`,
+		},
+	}
+
+	for _, kase := range kases {
+		t.Run(string(kase.dbType), func(t *testing.T) {
+			statement := newUpsertStatement(t, kase.dbType)
+			statement.Compound(CompoundUnionAll, newQuery(kase.dbType, "a")).
+				Compound(CompoundUnionAll, newQuery(kase.dbType, "b")).
+				Compound(CompoundExcept, newQuery(kase.dbType, "c")).
+				Limit(10, 5)
+			sql, args, err := statement.GenFindSQL(builder.NewCond())
+			assert.NoError(t, err)
+			assert.EqualValues(t, kase.expected, sql)
+			assert.EqualValues(t, []interface{}{"a", "b", "c"}, args)
+		})
+	}
+
+	// the conditions are applied to the combined rows
+	statement := newUpsertStatement(t, schemas.POSTGRES)
+	statement.Compound(CompoundUnion, newQuery(schemas.POSTGRES, "a")).
+		Compound(CompoundUnion, newQuery(schemas.POSTGRES, "b").Limit(1)).
+		Where("email = ?", "x")
+	assert.NoError(t, statement.SetRefValue(reflect.ValueOf(UpsertUser{})))
+	sql, args, err := statement.GenCountSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `SELECT count(*) FROM (SELECT "id", "name", "email", "created", "updated", "version" FROM "upsert_user" WHERE (name = ?) UNION SELECT * FROM (SELECT "id", "name", "email", "created", "updated", "version" FROM "upsert_user" WHERE (name = ?) LIMIT 1) xc1) AS "upsert_user" WHERE (email = ?)`, sql)
+	assert.EqualValues(t, []interface{}{"a", "b", "x"}, args)
+
+	// the orders without pagination are dropped in the subqueries on MSSQL
+	compound := newUpsertStatement(t, schemas.MSSQL)
+	compound.Compound(CompoundUnion, newQuery(schemas.MSSQL, "a").OrderBy("id")).
+		Compound(CompoundUnion, newQuery(schemas.MSSQL, "b").OrderBy("id").Limit(1)).
+		OrderBy("name")
+	statement = newUpsertStatement(t, schemas.MSSQL)
+	assert.NoError(t, statement.SetTable(compound))
+	sql, args, err = statement.GenFindSQL(builder.NewCond())
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT [id], [name], [email], [created], [updated], [version] FROM (SELECT [id], [name], [email], [created], [updated], [version] FROM [upsert_user] WHERE (name = ?) UNION SELECT * FROM (SELECT [id], [name], [email], [created], [updated], [version] FROM [upsert_user] WHERE (name = ?) ORDER BY id OFFSET 0 ROWS FETCH NEXT 1 ROWS ONLY) xc1) AS [upsert_user]", sql)
+	assert.EqualValues(t, []interface{}{"a", "b"}, args)
+
+	// the common table expressions of the subqueries are not supported on MSSQL
+	withQuery := newQuery(schemas.MSSQL, "a")
+	withQuery.With("sub", false, builder.Select("id").From("upsert_user"))
+	statement = newUpsertStatement(t, schemas.MSSQL)
+	statement.Compound(CompoundUnion, newQuery(schemas.MSSQL, "b")).Compound(CompoundUnion, withQuery)
+	_, _, err = statement.GenFindSQL(builder.NewCond())
+	assert.EqualValues(t, ErrSubQueryCTEUnsupported, err)
+}
@@ -82,15 +82,27 @@ func (statement *Statement) Or(query interface{}, args ...interface{}) *Statemen
 	return statement
 }
 
-// In generate "Where column IN (?) " statement
+// In generate "Where column IN (?) " statement, the argument could be a subquery statement
 func (statement *Statement) In(column string, args ...interface{}) *Statement {
+	if len(args) == 1 {
+		if query, ok := args[0].(*Statement); ok {
+			statement.cond = statement.cond.And(subQueryCond{prefix: statement.quote(column) + " IN", query: query})
+			return statement
+		}
+	}
 	in := builder.In(statement.quote(column), args...)
 	statement.cond = statement.cond.And(in)
 	return statement
 }
 
-// NotIn generate "Where column NOT IN (?) " statement
+// NotIn generate "Where column NOT IN (?) " statement, the argument could be a subquery statement
 func (statement *Statement) NotIn(column string, args ...interface{}) *Statement {
+	if len(args) == 1 {
+		if query, ok := args[0].(*Statement); ok {
+			statement.cond = statement.cond.And(subQueryCond{prefix: statement.quote(column) + " NOT IN", query: query})
+			return statement
+		}
+	}
 	notIn := builder.NotIn(statement.quote(column), args...)
 	statement.cond = statement.cond.And(notIn)
 	return statement
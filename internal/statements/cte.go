@@ -0,0 +1,201 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+var (
+	// ErrUnsupportedCTEQuery represents the query of a common table expression is not supported
+	ErrUnsupportedCTEQuery = errors.New("Unsupported query of common table expression")
+	// ErrSubQueryCTEUnsupported represents the subquery has common table expressions which are
+	// not supported by the database, i.e. MSSQL
+	ErrSubQueryCTEUnsupported = errors.New("Common table expressions of subqueries are not supported by the database")
+)
+
+// cte represents a common table expression
+type cte struct {
+	name      string
+	recursive bool
+	query     interface{}
+}
+
+// With adds a common table expression which could be used as a table by Table or Join, the
+// query should be a *Statement or a *builder.Builder. The name could be followed by the column
+// list like tree(id,parent_id), which is required by Oracle for recursive queries.
+func (statement *Statement) With(name string, recursive bool, query interface{}) *Statement {
+	switch query.(type) {
+	case *Statement, *builder.Builder:
+		statement.ctes = append(statement.ctes, cte{
+			name:      name,
+			recursive: recursive,
+			query:     query,
+		})
+	default:
+		statement.LastError = ErrUnsupportedCTEQuery
+	}
+	return statement
+}
+
+// HasCTE returns true if there are common table expressions
+func (statement *Statement) HasCTE() bool {
+	return len(statement.ctes) > 0
+}
+
+// cteName returns the name of the common table expression without the column list
+func cteName(name string) string {
+	if idx := strings.IndexByte(name, '('); idx > -1 {
+		return strings.TrimSpace(name[:idx])
+	}
+	return name
+}
+
+// IsCTE returns true if the table name is the name of a common table expression
+func (statement *Statement) IsCTE(tableName string) bool {
+	tableName = schemas.CommonQuoter.Trim(statement.dialect.Quoter().Trim(tableName))
+	for _, cte := range statement.ctes {
+		if cteName(cte.name) == tableName {
+			return true
+		}
+	}
+	return false
+}
+
+// isCTETable returns true if the table of Table or Join is a common table expression
+func (statement *Statement) isCTETable(tableNameOrBean interface{}) bool {
+	switch t := tableNameOrBean.(type) {
+	case string:
+		fields := strings.Fields(t)
+		return len(fields) > 0 && statement.IsCTE(fields[0])
+	case []string:
+		return len(t) > 0 && statement.IsCTE(t[0])
+	case []interface{}:
+		if len(t) > 0 {
+			name, ok := t[0].(string)
+			return ok && statement.IsCTE(name)
+		}
+	}
+	return false
+}
+
+// writeCTEName writes the quoted name and column list of the common table expression
+func (statement *Statement) writeCTEName(w *builder.BytesWriter, name string) error {
+	idx := strings.IndexByte(name, '(')
+	if idx < 0 {
+		_, err := fmt.Fprint(w, statement.quote(name))
+		return err
+	}
+	cols := strings.TrimSuffix(strings.TrimSpace(name[idx+1:]), ")")
+	_, err := fmt.Fprint(w, statement.quote(strings.TrimSpace(name[:idx])), "(", statement.quoteColumnStr(cols), ")")
+	return err
+}
+
+// writeWith writes the common table expressions before the SELECT
+func (statement *Statement) writeWith(w *builder.BytesWriter) error {
+	if len(statement.ctes) == 0 {
+		return nil
+	}
+
+	var recursive bool
+	for _, cte := range statement.ctes {
+		recursive = recursive || cte.recursive
+	}
+	if _, err := w.WriteString("WITH "); err != nil {
+		return err
+	}
+	// MSSQL, Oracle and Dameng detect recursive queries without the keyword
+	if recursive {
+		switch statement.dialect.URI().DBType {
+		case schemas.MSSQL, schemas.ORACLE, schemas.DAMENG:
+		default:
+			if _, err := w.WriteString("RECURSIVE "); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, cte := range statement.ctes {
+		if i > 0 {
+			if _, err := w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		if err := statement.writeCTEName(w, cte.name); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(" AS ("); err != nil {
+			return err
+		}
+		switch query := cte.query.(type) {
+		case *Statement:
+			sql, args, err := query.GenSubQuerySQL()
+			if err != nil {
+				return err
+			}
+			if _, err := w.WriteString(sql); err != nil {
+				return err
+			}
+			w.Append(args...)
+		case *builder.Builder:
+			if err := query.WriteTo(statement.QuoteReplacer(w)); err != nil {
+				return err
+			}
+		}
+		if _, err := w.WriteString(")"); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString(" ")
+	return err
+}
+
+// GenSubQuerySQL generates the SELECT SQL of the statement to be used in another query, the
+// soft deleted rows are excluded as Find does. The statement is not changed. On MSSQL, the orders
+// are dropped if there is no pagination, and the common table expressions are not supported.
+func (statement *Statement) GenSubQuerySQL() (string, []interface{}, error) {
+	if statement.LastError != nil {
+		return "", nil, statement.LastError
+	}
+	if statement.dialect.URI().DBType == schemas.MSSQL && statement.HasCTE() {
+		return "", nil, ErrSubQueryCTEUnsupported
+	}
+	if statement.RawSQL != "" {
+		return statement.GenRawSQL(), statement.RawParams, nil
+	}
+	if len(statement.TableName()) == 0 && !statement.IsCompound() {
+		return "", nil, ErrTableNotFound
+	}
+
+	cond, orderBy := statement.cond, statement.orderBy
+	defer func() {
+		statement.cond, statement.orderBy = cond, orderBy
+	}()
+	if !statement.isSubQueryOrdered() {
+		statement.orderBy = nil
+	}
+	if err := statement.ProcessIDParam(); err != nil {
+		return "", nil, err
+	}
+	if statement.RefTable != nil {
+		if col := statement.RefTable.DeletedColumn(); col != nil && !statement.GetUnscoped() {
+			statement.cond = statement.cond.And(statement.CondDeleted(col))
+		}
+	}
+
+	buf := builder.NewWriter()
+	if err := statement.writeWith(buf); err != nil {
+		return "", nil, err
+	}
+	if err := statement.writeSelect(buf, statement.genSelectColumnStr(), false); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), buf.Args(), nil
+}
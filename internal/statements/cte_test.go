@@ -0,0 +1,55 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+	"xorm.io/xorm/caches"
+	"xorm.io/xorm/dialects"
+	"xorm.io/xorm/names"
+	"xorm.io/xorm/schemas"
+	"xorm.io/xorm/tags"
+)
+
+func TestWithSQL(t *testing.T) {
+	newStatement := func(dbType schemas.DBType, schema string) *Statement {
+		dialect := dialects.QueryDialect(dbType)
+		assert.NoError(t, dialect.Init(&dialects.URI{DBType: dbType, Schema: schema}))
+		parser := tags.NewParser("xorm", dialect, names.SnakeMapper{}, names.SnakeMapper{}, caches.NewManager())
+		return NewStatement(dialect, parser, time.Local)
+	}
+
+	tree := builder.Select("id").From("upsert_user").Where(builder.Eq{"name": "a"})
+
+	statement := newStatement(schemas.POSTGRES, "public")
+	statement.With("tree(id)", true, tree)
+	assert.NoError(t, statement.SetTable("tree"))
+	assert.NoError(t, statement.SetRefValue(reflect.ValueOf(UpsertUser{})))
+	statement.Join("INNER", "tree t2", "t2.id = tree.id")
+	sql, args, err := statement.GenCountSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `WITH RECURSIVE "tree"("id") AS (SELECT id FROM upsert_user WHERE name=?) SELECT count(*) FROM "tree" INNER JOIN "tree" "t2" ON t2.id = tree.id`, sql)
+	assert.EqualValues(t, []interface{}{"a"}, args)
+
+	sub := newStatement(schemas.MSSQL, "")
+	assert.NoError(t, sub.SetTable(UpsertUser{}))
+	sub.Where("name = ?", "a")
+	statement = newStatement(schemas.MSSQL, "")
+	statement.With("sub", false, sub).With("tree(id)", true, tree)
+	assert.NoError(t, statement.SetTable("sub"))
+	sql, args, err = statement.GenFindSQL(builder.Eq{"id": 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH [sub] AS (SELECT [id], [name], [email], [created], [updated], [version] FROM [upsert_user] WHERE (name = ?)), [tree]([id]) AS (SELECT id FROM upsert_user WHERE name=?) SELECT * FROM [sub] WHERE id=?", sql)
+	assert.EqualValues(t, []interface{}{"a", "a", 1}, args)
+
+	statement = newStatement(schemas.SQLITE, "")
+	statement.With("sub", false, "SELECT 1")
+	assert.EqualValues(t, ErrUnsupportedCTEQuery, statement.LastError)
+}
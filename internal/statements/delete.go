@@ -101,7 +101,24 @@ func (statement *Statement) WriteDelete(realSQLWriter, deleteSQLWriter *builder.
 	}
 
 	if statement.GetUnscoped() || table == nil || table.DeletedColumn() == nil { // tag "deleted" is disabled
-		return utils.WriteBuilder(realSQLWriter, deleteSQLWriter, orderCondWriter)
+		if !statement.IsReturning() {
+			return utils.WriteBuilder(realSQLWriter, deleteSQLWriter, orderCondWriter)
+		}
+
+		returningCols := statement.ReturningColumns()
+		if _, err := fmt.Fprint(realSQLWriter, "DELETE FROM ", tableName); err != nil {
+			return err
+		}
+		if err := statement.writeReturningOutput(realSQLWriter.Builder, "DELETED", returningCols); err != nil {
+			return err
+		}
+		if err := statement.writeWhere(realSQLWriter); err != nil {
+			return err
+		}
+		if err := utils.WriteBuilder(realSQLWriter, orderCondWriter); err != nil {
+			return err
+		}
+		return statement.writeReturning(realSQLWriter, returningCols)
 	}
 
 	deletedColumn := table.DeletedColumn()
@@ -117,9 +134,22 @@ func (statement *Statement) WriteDelete(realSQLWriter, deleteSQLWriter *builder.
 	}
 	realSQLWriter.Append(val)
 
+	returningCols := statement.ReturningColumns()
+	if statement.IsReturning() {
+		if err := statement.writeReturningOutput(realSQLWriter.Builder, "INSERTED", returningCols); err != nil {
+			return err
+		}
+	}
+
 	if err := statement.writeWhere(realSQLWriter); err != nil {
 		return err
 	}
 
-	return utils.WriteBuilder(realSQLWriter, orderCondWriter)
+	if err := utils.WriteBuilder(realSQLWriter, orderCondWriter); err != nil {
+		return err
+	}
+	if statement.IsReturning() {
+		return statement.writeReturning(realSQLWriter, returningCols)
+	}
+	return nil
 }
@@ -15,6 +15,9 @@ import (
 )
 
 func (statement *Statement) writeInsertOutput(buf *strings.Builder, table *schemas.Table) error {
+	if statement.IsReturning() {
+		return statement.writeReturningOutput(buf, "INSERTED", statement.insertReturningColumns())
+	}
 	if statement.dialect.URI().DBType == schemas.MSSQL && len(table.AutoIncrement) > 0 {
 		if _, err := buf.WriteString(" OUTPUT Inserted."); err != nil {
 			return err
@@ -35,6 +38,15 @@ func (statement *Statement) GenInsertSQL(colNames []string, args []interface{})
 		tableName = statement.TableName()
 	)
 
+	if statement.Upsert != nil {
+		if statement.Conds().IsValid() {
+			return "", nil, ErrUpsertWithWhere
+		}
+		if statement.IsMergeUpsert() {
+			return statement.genMergeUpsertSQL(colNames, [][]interface{}{args})
+		}
+	}
+
 	if _, err := buf.WriteString("INSERT INTO "); err != nil {
 		return "", nil, err
 	}
@@ -164,7 +176,15 @@ func (statement *Statement) GenInsertSQL(colNames []string, args []interface{})
 		}
 	}
 
-	if len(table.AutoIncrement) > 0 && statement.dialect.URI().DBType == schemas.POSTGRES {
+	if err := statement.writeUpsert(buf, tableName, append(colNames, exprs.ColNames()...)); err != nil {
+		return "", nil, err
+	}
+
+	if statement.IsReturning() {
+		if err := statement.writeReturning(buf, statement.insertReturningColumns()); err != nil {
+			return "", nil, err
+		}
+	} else if len(table.AutoIncrement) > 0 && statement.dialect.URI().DBType == schemas.POSTGRES {
 		if _, err := buf.WriteString(" RETURNING "); err != nil {
 			return "", nil, err
 		}
@@ -184,6 +204,15 @@ func (statement *Statement) GenInsertMapSQL(columns []string, args []interface{}
 		tableName = statement.TableName()
 	)
 
+	if statement.Upsert != nil {
+		if statement.Conds().IsValid() {
+			return "", nil, ErrUpsertWithWhere
+		}
+		if statement.IsMergeUpsert() {
+			return statement.genMergeUpsertSQL(columns, [][]interface{}{args})
+		}
+	}
+
 	if _, err := buf.WriteString(fmt.Sprintf("INSERT INTO %s (", statement.quote(tableName))); err != nil {
 		return "", nil, err
 	}
@@ -239,6 +268,10 @@ func (statement *Statement) GenInsertMapSQL(columns []string, args []interface{}
 		}
 	}
 
+	if err := statement.writeUpsert(buf, tableName, append(columns, exprs.ColNames()...)); err != nil {
+		return "", nil, err
+	}
+
 	return buf.String(), buf.Args(), nil
 }
 
@@ -249,6 +282,15 @@ func (statement *Statement) GenInsertMultipleMapSQL(columns []string, argss [][]
 		tableName = statement.TableName()
 	)
 
+	// if insert where
+	if statement.Conds().IsValid() {
+		return "", nil, errors.New("batch insert don't support with where")
+	}
+
+	if statement.IsMergeUpsert() {
+		return statement.genMergeUpsertSQL(columns, argss)
+	}
+
 	if _, err := buf.WriteString(fmt.Sprintf("INSERT INTO %s (", statement.quote(tableName))); err != nil {
 		return "", nil, err
 	}
@@ -257,11 +299,6 @@ func (statement *Statement) GenInsertMultipleMapSQL(columns []string, argss [][]
 		return "", nil, err
 	}
 
-	// if insert where
-	if statement.Conds().IsValid() {
-		return "", nil, errors.New("batch insert don't support with where")
-	}
-
 	if _, err := buf.WriteString(") VALUES "); err != nil {
 		return "", nil, err
 	}
@@ -291,6 +328,10 @@ func (statement *Statement) GenInsertMultipleMapSQL(columns []string, argss [][]
 		}
 	}
 
+	if err := statement.writeUpsert(buf, tableName, append(columns, exprs.ColNames()...)); err != nil {
+		return "", nil, err
+	}
+
 	return buf.String(), buf.Args(), nil
 }
 
@@ -355,10 +396,26 @@ func (statement *Statement) oracleWriteInsertMultiple(w *builder.BytesWriter, ta
 }
 
 func (statement *Statement) WriteInsertMultiple(w *builder.BytesWriter, tableName string, colNames []string, colMultiPlaces []string) error {
+	if statement.IsMergeUpsert() {
+		return statement.writeMultipleMergeUpsert(w, tableName, colNames, colMultiPlaces)
+	}
+	if statement.IsReturningInto() {
+		// RETURNING INTO cannot return multiple rows
+		return ErrReturningNotSupported
+	}
 	if statement.dialect.URI().DBType == schemas.ORACLE {
 		return statement.oracleWriteInsertMultiple(w, tableName, colNames, colMultiPlaces)
 	}
-	return statement.plainWriteInsertMultiple(w, tableName, colNames, colMultiPlaces)
+	if err := statement.plainWriteInsertMultiple(w, tableName, colNames, colMultiPlaces); err != nil {
+		return err
+	}
+	if err := statement.writeUpsert(w, tableName, colNames); err != nil {
+		return err
+	}
+	if statement.IsReturning() {
+		return statement.writeReturning(w, statement.insertReturningColumns())
+	}
+	return nil
 }
 
 func (statement *Statement) plainWriteInsertMultiple(w *builder.BytesWriter, tableName string, colNames []string, colMultiPlaces []string) error {
@@ -374,7 +431,15 @@ func (statement *Statement) plainWriteInsertMultiple(w *builder.BytesWriter, tab
 	if err := statement.writeColumns(w, colNames); err != nil {
 		return err
 	}
-	if _, err := fmt.Fprint(w, ") VALUES ("); err != nil {
+	if _, err := fmt.Fprint(w, ")"); err != nil {
+		return err
+	}
+	if statement.IsReturning() {
+		if err := statement.writeReturningOutput(w.Builder, "INSERTED", statement.insertReturningColumns()); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprint(w, " VALUES ("); err != nil {
 		return err
 	}
 	for i, cols := range colMultiPlaces {
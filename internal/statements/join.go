@@ -66,8 +66,14 @@ func (statement *Statement) writeJoinTable(buf *builder.BytesWriter, join join)
 		if _, err := fmt.Fprintf(buf, ") %s", statement.quote(aliasName)); err != nil {
 			return err
 		}
+	case *Statement:
+		if _, err := fmt.Fprint(buf, " "); err != nil {
+			return err
+		}
+		return statement.writeSubQueryTable(buf, tp, subQueryAlias(tp))
 	default:
-		tbName := dialects.FullTableName(statement.dialect, statement.tagParser.GetTableMapper(), join.table, true)
+		// the common table expressions have no schema
+		tbName := dialects.FullTableName(statement.dialect, statement.tagParser.GetTableMapper(), join.table, !statement.isCTETable(join.table))
 		if !utils.IsSubQuery(tbName) {
 			var sb strings.Builder
 			if err := statement.dialect.Quoter().QuoteTo(&sb, tbName); err != nil {
@@ -0,0 +1,197 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+var (
+	// ErrKeysetUnsupported represents the query cannot be paged by keyset
+	ErrKeysetUnsupported = errors.New("Keyset pagination only supports the queries ordered by the selected columns of the table")
+	// ErrKeysetNotUnique represents the rows are not ordered by unique columns
+	ErrKeysetNotUnique = errors.New("Keyset pagination needs the rows ordered by unique columns")
+	// ErrKeysetNullValue represents a keyset column of the row is NULL
+	ErrKeysetNullValue = errors.New("Keyset pagination doesn't support NULL values")
+)
+
+// KeysetColumn represents a column of keyset pagination and its order
+type KeysetColumn struct {
+	*schemas.Column
+	Desc bool
+}
+
+// String returns the name of the column, it's prefixed by "-" if the order is descending
+func (col KeysetColumn) String() string {
+	if col.Desc {
+		return "-" + col.Name
+	}
+	return col.Name
+}
+
+// Keyset returns the columns of keyset pagination, they are the columns of Asc and Desc, or the
+// primary key if there are no orders. If the columns are not unique, the primary key is appended
+// to the orders when appendPK is true, otherwise ErrKeysetNotUnique is returned.
+func (statement *Statement) Keyset(appendPK bool) ([]KeysetColumn, error) {
+	table := statement.RefTable
+	if table == nil {
+		return nil, ErrTableNotFound
+	}
+	if statement.RawSQL != "" || statement.IsCompound() || statement.IsDistinct ||
+		statement.GroupByStr != "" || statement.SelectStr != "" {
+		return nil, ErrKeysetUnsupported
+	}
+
+	keyset := make([]KeysetColumn, 0, len(statement.orderBy)+len(table.PrimaryKeys))
+	for _, ob := range statement.orderBy {
+		name, ok := ob.orderStr.(string)
+		if !ok || ob.direction == "" {
+			return nil, ErrKeysetUnsupported
+		}
+		col := statement.keysetColumn(name)
+		if col == nil {
+			return nil, ErrKeysetUnsupported
+		}
+		keyset = append(keyset, KeysetColumn{Column: col, Desc: ob.direction == "DESC"})
+	}
+
+	var pkOrders []orderBy
+	if !isUniqueKeyset(table, keyset) {
+		if (len(keyset) > 0 && !appendPK) || len(table.PrimaryKeys) == 0 {
+			return nil, ErrKeysetNotUnique
+		}
+		for _, col := range table.PKColumns() {
+			if containsKeysetColumn(keyset, col.Name) {
+				continue
+			}
+			keyset = append(keyset, KeysetColumn{Column: col})
+			pkOrders = append(pkOrders, orderBy{statement.colName(col, statement.TableName()), nil, "ASC"})
+		}
+	}
+
+	// the values of the columns are read from the rows
+	for _, col := range keyset {
+		if col.MapType == schemas.ONLYTODB || statement.OmitColumnMap.Contain(col.Name) ||
+			(len(statement.ColumnMap) > 0 && !statement.ColumnMap.Contain(col.Name)) {
+			return nil, ErrKeysetUnsupported
+		}
+	}
+
+	statement.orderBy = append(statement.orderBy, pkOrders...)
+	return keyset, nil
+}
+
+// keysetColumn returns the column of the table by the name of an order, the name could be
+// qualified by the table name or the alias
+func (statement *Statement) keysetColumn(name string) *schemas.Column {
+	trim := func(s string) string {
+		return schemas.CommonQuoter.Trim(statement.dialect.Quoter().Trim(strings.TrimSpace(s)))
+	}
+	fields := strings.Split(name, ".")
+	if len(fields) > 1 {
+		qualifier := trim(fields[len(fields)-2])
+		tableNames := strings.Split(statement.TableName(), ".")
+		if qualifier != trim(statement.TableAlias) && qualifier != trim(tableNames[len(tableNames)-1]) {
+			return nil
+		}
+	}
+	return statement.RefTable.GetColumn(trim(fields[len(fields)-1]))
+}
+
+func containsKeysetColumn(keyset []KeysetColumn, colName string) bool {
+	for _, col := range keyset {
+		if strings.EqualFold(col.Name, colName) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUniqueKeyset returns true if the keyset contains the primary key or the columns of a unique
+// index which are not nullable
+func isUniqueKeyset(table *schemas.Table, keyset []KeysetColumn) bool {
+	containsAll := func(colNames []string) bool {
+		for _, colName := range colNames {
+			if !containsKeysetColumn(keyset, colName) {
+				return false
+			}
+		}
+		return len(colNames) > 0
+	}
+
+	if containsAll(table.PrimaryKeys) {
+		return true
+	}
+	for _, index := range table.Indexes {
+		if index.Type != schemas.UniqueType || index.Where != "" || !containsAll(index.Cols) {
+			continue
+		}
+		nullable := false
+		for _, colName := range index.Cols {
+			if col := table.GetColumn(colName); col == nil || col.Nullable {
+				nullable = true
+				break
+			}
+		}
+		if !nullable {
+			return true
+		}
+	}
+	return false
+}
+
+// KeysetValues returns the values of the keyset columns of the row to query the next rows
+func (statement *Statement) KeysetValues(keyset []KeysetColumn, rowValue reflect.Value) ([]interface{}, error) {
+	rowValue = reflect.Indirect(rowValue)
+	values := make([]interface{}, 0, len(keyset))
+	for _, col := range keyset {
+		fieldValue, err := col.ValueOfV(&rowValue)
+		if err != nil {
+			return nil, err
+		}
+		value, err := statement.Value2Interface(col.Column, *fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, ErrKeysetNullValue
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// After generates the condition to query the rows after the values of the keyset columns, i.e.
+// "a > ? OR (a = ? AND b > ?)", it replaces the previous condition of After
+func (statement *Statement) After(keyset []KeysetColumn, values []interface{}) *Statement {
+	tableName := statement.TableName()
+	conds := make([]builder.Cond, 0, len(keyset))
+	for i, col := range keyset {
+		colName := statement.colName(col.Column, tableName)
+		var cond builder.Cond = builder.Gt{colName: values[i]}
+		if col.Desc {
+			cond = builder.Lt{colName: values[i]}
+		}
+		if i > 0 {
+			eqs := make([]builder.Cond, 0, i+1)
+			for j := 0; j < i; j++ {
+				eqs = append(eqs, builder.Eq{statement.colName(keyset[j].Column, tableName): values[j]})
+			}
+			cond = builder.And(append(eqs, cond)...)
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 1 {
+		statement.keysetCond = conds[0]
+	} else {
+		statement.keysetCond = builder.Or(conds...)
+	}
+	return statement
+}
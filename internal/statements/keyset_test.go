@@ -0,0 +1,65 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+func TestKeysetSQL(t *testing.T) {
+	// the primary key is the default keyset
+	statement := newUpsertStatement(t, schemas.POSTGRES)
+	keyset, err := statement.Keyset(false)
+	assert.NoError(t, err)
+	assert.Len(t, keyset, 1)
+	assert.EqualValues(t, "id", keyset[0].String())
+	statement.After(keyset, []interface{}{int64(3)}).Limit(10)
+	sql, args, err := statement.GenFindSQL(builder.NewCond())
+	assert.NoError(t, err)
+	assert.EqualValues(t, `SELECT "id", "name", "email", "created", "updated", "version" FROM "upsert_user" WHERE "id">? ORDER BY "id" ASC LIMIT 10`, sql)
+	assert.EqualValues(t, []interface{}{int64(3)}, args)
+
+	// the primary key is appended to the columns which are not unique
+	statement = newUpsertStatement(t, schemas.MSSQL)
+	statement.Where("email <> ?", "").Desc("upsert_user.created")
+	_, err = statement.Keyset(false)
+	assert.EqualValues(t, ErrKeysetNotUnique, err)
+	keyset, err = statement.Keyset(true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"-created", "id"}, []string{keyset[0].String(), keyset[1].String()})
+
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	values, err := statement.KeysetValues(keyset, reflect.ValueOf(&UpsertUser{Id: 3, Created: created}))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []interface{}{created.In(time.Local).Format("2006-01-02 15:04:05"), int64(3)}, values)
+	statement.After(keyset, values).Limit(10)
+	sql, args, err = statement.GenFindSQL(builder.NewCond())
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT [id], [name], [email], [created], [updated], [version] FROM [upsert_user] WHERE (email <> ?) AND ([created]<? OR ([created]=? AND [id]>?)) ORDER BY [upsert_user].[created] DESC, [id] ASC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+	assert.EqualValues(t, []interface{}{"", values[0], values[0], int64(3)}, args)
+
+	// the unique columns which are nullable are not unique for keyset pagination
+	statement = newUpsertStatement(t, schemas.SQLITE)
+	statement.Asc("name")
+	_, err = statement.Keyset(false)
+	assert.EqualValues(t, ErrKeysetNotUnique, err)
+
+	statement = newUpsertStatement(t, schemas.SQLITE)
+	statement.OrderBy("id DESC")
+	_, err = statement.Keyset(true)
+	assert.EqualValues(t, ErrKeysetUnsupported, err)
+
+	statement = newUpsertStatement(t, schemas.SQLITE)
+	statement.Omit("id")
+	_, err = statement.Keyset(true)
+	assert.EqualValues(t, ErrKeysetUnsupported, err)
+	assert.False(t, statement.HasOrderBy())
+}
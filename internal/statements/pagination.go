@@ -77,7 +77,7 @@ func (statement *Statement) writeMssqlPaginationCond(w *builder.BytesWriter) err
 	var column string
 	if len(statement.RefTable.PKColumns()) == 0 {
 		for _, index := range statement.RefTable.Indexes {
-			if len(index.Cols) == 1 {
+			if len(index.Cols) == 1 && !schemas.IsIndexExpr(index.Cols[0]) {
 				column = index.Cols[0]
 				break
 			}
@@ -24,7 +24,7 @@ func (statement *Statement) GenQuerySQL(sqlOrArgs ...interface{}) (string, []int
 		return statement.GenRawSQL(), statement.RawParams, nil
 	}
 
-	if len(statement.TableName()) <= 0 {
+	if len(statement.TableName()) <= 0 && !statement.IsCompound() {
 		return "", nil, ErrTableNotFound
 	}
 
@@ -33,6 +33,9 @@ func (statement *Statement) GenQuerySQL(sqlOrArgs ...interface{}) (string, []int
 	}
 
 	buf := builder.NewWriter()
+	if err := statement.writeWith(buf); err != nil {
+		return "", nil, err
+	}
 	if err := statement.writeSelect(buf, statement.genSelectColumnStr(), false); err != nil {
 		return "", nil, err
 	}
@@ -64,6 +67,9 @@ func (statement *Statement) GenSumSQL(bean interface{}, columns ...string) (stri
 	}
 
 	buf := builder.NewWriter()
+	if err := statement.writeWith(buf); err != nil {
+		return "", nil, err
+	}
 	if err := statement.writeSelect(buf, strings.Join(sumStrs, ", "), true); err != nil {
 		return "", nil, err
 	}
@@ -120,6 +126,9 @@ func (statement *Statement) GenGetSQL(bean interface{}) (string, []interface{},
 	}
 
 	buf := builder.NewWriter()
+	if err := statement.writeWith(buf); err != nil {
+		return "", nil, err
+	}
 	if err := statement.writeSelect(buf, columnStr, false); err != nil {
 		return "", nil, err
 	}
@@ -153,6 +162,9 @@ func (statement *Statement) GenCountSQL(beans ...interface{}) (string, []interfa
 	}
 
 	buf := builder.NewWriter()
+	if err := statement.writeWith(buf); err != nil {
+		return "", nil, err
+	}
 	if statement.GroupByStr != "" {
 		if _, err := fmt.Fprintf(buf, "SELECT %s FROM (", selectSQL); err != nil {
 			return "", nil, err
@@ -226,7 +238,7 @@ func (statement *Statement) writeWhereCond(w *builder.BytesWriter, cond builder.
 }
 
 func (statement *Statement) writeWhere(w *builder.BytesWriter) error {
-	return statement.writeWhereCond(w, statement.cond)
+	return statement.writeWhereCond(w, statement.cond.And(statement.keysetCond))
 }
 
 func (statement *Statement) writeForUpdate(w *builder.BytesWriter) error {
@@ -242,6 +254,10 @@ func (statement *Statement) writeForUpdate(w *builder.BytesWriter) error {
 }
 
 func (statement *Statement) writeSelect(buf *builder.BytesWriter, columnStr string, isCounting bool) error {
+	if !isCounting && statement.isBareCompound() {
+		return statement.writeCompoundSelect(buf)
+	}
+
 	dbType := statement.dialect.URI().DBType
 	if statement.isUsingLegacyLimitOffset() {
 		if dbType == "mssql" {
@@ -258,34 +274,40 @@ func (statement *Statement) writeSelect(buf *builder.BytesWriter, columnStr stri
 		statement.writeWhere,
 		statement.writeGroupBy,
 		statement.writeHaving,
-		func(bw *builder.BytesWriter) (err error) {
-			if dbType == "mssql" && len(statement.orderBy) == 0 {
-				// ORDER BY is mandatory to use OFFSET and FETCH clause (only in sqlserver)
-				if statement.LimitN == nil && statement.Start == 0 {
-					// no need to add
-					return
-				}
-				if statement.IsDistinct || len(statement.GroupByStr) > 0 || isCounting {
-					// the order-by column should be one of distincts or group-bys
-					// order by the first column
-					_, err = bw.WriteString(" ORDER BY 1 ASC")
-					return
-				}
-				if statement.RefTable == nil || len(statement.RefTable.PrimaryKeys) != 1 {
-					// no primary key, order by the first column
-					_, err = bw.WriteString(" ORDER BY 1 ASC")
-					return
-				}
-				// order by primary key
-				statement.orderBy = []orderBy{{orderStr: statement.colName(statement.RefTable.GetColumn(statement.RefTable.PrimaryKeys[0]), statement.TableName()), direction: "ASC"}}
-			}
-			return statement.writeOrderBys(bw)
-		},
+		statement.writeSelectOrderBys(isCounting),
 		statement.writePagination,
 		statement.writeForUpdate,
 	)
 }
 
+// writeSelectOrderBys writes the orders of the query, an order is required by the pagination of MSSQL
+func (statement *Statement) writeSelectOrderBys(isCounting bool) func(bw *builder.BytesWriter) error {
+	dbType := statement.dialect.URI().DBType
+	return func(bw *builder.BytesWriter) (err error) {
+		if dbType == "mssql" && len(statement.orderBy) == 0 {
+			// ORDER BY is mandatory to use OFFSET and FETCH clause (only in sqlserver)
+			if statement.LimitN == nil && statement.Start == 0 {
+				// no need to add
+				return
+			}
+			if statement.IsDistinct || len(statement.GroupByStr) > 0 || isCounting {
+				// the order-by column should be one of distincts or group-bys
+				// order by the first column
+				_, err = bw.WriteString(" ORDER BY 1 ASC")
+				return
+			}
+			if statement.RefTable == nil || len(statement.RefTable.PrimaryKeys) != 1 {
+				// no primary key, order by the first column
+				_, err = bw.WriteString(" ORDER BY 1 ASC")
+				return
+			}
+			// order by primary key
+			statement.orderBy = []orderBy{{orderStr: statement.colName(statement.RefTable.GetColumn(statement.RefTable.PrimaryKeys[0]), statement.TableName()), direction: "ASC"}}
+		}
+		return statement.writeOrderBys(bw)
+	}
+}
+
 // GenExistSQL generates Exist SQL
 func (statement *Statement) GenExistSQL(bean ...interface{}) (string, []interface{}, error) {
 	if statement.RawSQL != "" {
@@ -306,19 +328,22 @@ func (statement *Statement) GenExistSQL(bean ...interface{}) (string, []interfac
 			}
 		}
 	}
-	tableName := statement.TableName()
-	if len(tableName) <= 0 {
+	if len(statement.TableName()) <= 0 {
 		return "", nil, ErrTableNotFound
 	}
 	if statement.RefTable != nil {
 		return statement.Limit(1).GenGetSQL(b)
 	}
 
-	tableName = statement.quote(tableName)
-
 	buf := builder.NewWriter()
+	if err := statement.writeWith(buf); err != nil {
+		return "", nil, err
+	}
 	if statement.dialect.URI().DBType == schemas.MSSQL {
-		if _, err := fmt.Fprintf(buf, "SELECT TOP 1 * FROM %s", tableName); err != nil {
+		if _, err := fmt.Fprint(buf, "SELECT TOP 1 * FROM "); err != nil {
+			return "", nil, err
+		}
+		if err := statement.writeTableName(buf); err != nil {
 			return "", nil, err
 		}
 		if err := statement.writeJoins(buf); err != nil {
@@ -328,7 +353,10 @@ func (statement *Statement) GenExistSQL(bean ...interface{}) (string, []interfac
 			return "", nil, err
 		}
 	} else if statement.dialect.URI().DBType == schemas.ORACLE {
-		if _, err := fmt.Fprintf(buf, "SELECT * FROM %s", tableName); err != nil {
+		if _, err := fmt.Fprint(buf, "SELECT * FROM "); err != nil {
+			return "", nil, err
+		}
+		if err := statement.writeTableName(buf); err != nil {
 			return "", nil, err
 		}
 		if err := statement.writeJoins(buf); err != nil {
@@ -349,7 +377,10 @@ func (statement *Statement) GenExistSQL(bean ...interface{}) (string, []interfac
 			return "", nil, err
 		}
 	} else {
-		if _, err := fmt.Fprintf(buf, "SELECT 1 FROM %s", tableName); err != nil {
+		if _, err := fmt.Fprint(buf, "SELECT 1 FROM "); err != nil {
+			return "", nil, err
+		}
+		if err := statement.writeTableName(buf); err != nil {
 			return "", nil, err
 		}
 		if err := statement.writeJoins(buf); err != nil {
@@ -399,13 +430,16 @@ func (statement *Statement) GenFindSQL(autoCond builder.Cond) (string, []interfa
 		return statement.GenRawSQL(), statement.RawParams, nil
 	}
 
-	if len(statement.TableName()) <= 0 {
+	if len(statement.TableName()) <= 0 && !statement.IsCompound() {
 		return "", nil, ErrTableNotFound
 	}
 
 	statement.cond = statement.cond.And(autoCond)
 
 	buf := builder.NewWriter()
+	if err := statement.writeWith(buf); err != nil {
+		return "", nil, err
+	}
 	if err := statement.writeSelect(buf, statement.genSelectColumnStr(), false); err != nil {
 		return "", nil, err
 	}
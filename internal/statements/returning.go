@@ -0,0 +1,153 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"errors"
+	"strings"
+
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+// ErrReturningNotSupported represents the database cannot return the rows of insert, update or delete
+var ErrReturningNotSupported = errors.New("Returning is not supported by the database")
+
+// Returning represents the columns returned from the rows of insert, update or delete
+type Returning struct {
+	// Cols are the returned columns, all the columns of the table will be returned if it's empty
+	Cols []string
+	// Dest is a pointer to a slice which the returned rows will be appended to
+	Dest interface{}
+}
+
+// SetReturning sets the columns to be returned
+func (statement *Statement) SetReturning(cols ...string) {
+	if statement.Returning == nil {
+		statement.Returning = &Returning{}
+	}
+	statement.Returning.Cols = cols
+}
+
+// SetReturningDest sets the slice which the returned rows will be appended to
+func (statement *Statement) SetReturningDest(dest interface{}) {
+	if statement.Returning == nil {
+		statement.Returning = &Returning{}
+	}
+	statement.Returning.Dest = dest
+}
+
+// IsReturning returns true if the rows should be returned
+func (statement *Statement) IsReturning() bool {
+	return statement.Returning != nil
+}
+
+// IsReturningInto returns true if the returned rows are assigned to the arguments
+// but not as the query results, i.e. RETURNING INTO on Oracle and Dameng
+func (statement *Statement) IsReturningInto() bool {
+	dbType := statement.dialect.URI().DBType
+	return statement.Returning != nil && (dbType == schemas.ORACLE || dbType == schemas.DAMENG)
+}
+
+// ReturningColumns returns the columns to be returned and the columns of includes will be
+// appended if they are missing. It returns nil if all the columns should be returned but
+// there is no table.
+func (statement *Statement) ReturningColumns(includes ...string) []string {
+	if statement.Returning == nil {
+		return nil
+	}
+
+	cols := make([]string, 0, len(statement.Returning.Cols)+len(includes))
+	if len(statement.Returning.Cols) > 0 {
+		cols = append(cols, statement.Returning.Cols...)
+	} else if statement.RefTable != nil {
+		for _, col := range statement.RefTable.Columns() {
+			if col.MapType != schemas.ONLYTODB {
+				cols = append(cols, col.Name)
+			}
+		}
+	} else {
+		return nil
+	}
+
+	for _, include := range includes {
+		if include != "" && !containsCol(cols, include) {
+			cols = append(cols, include)
+		}
+	}
+	return cols
+}
+
+// insertReturningColumns returns the columns returned by insert, the auto increment column is always included
+func (statement *Statement) insertReturningColumns() []string {
+	if statement.RefTable == nil {
+		return statement.ReturningColumns()
+	}
+	return statement.ReturningColumns(statement.RefTable.AutoIncrement)
+}
+
+// writeReturningOutput writes the OUTPUT clause for MSSQL, the prefix should be INSERTED or DELETED
+func (statement *Statement) writeReturningOutput(buf *strings.Builder, prefix string, cols []string) error {
+	if statement.dialect.URI().DBType != schemas.MSSQL {
+		return nil
+	}
+	if _, err := buf.WriteString(" OUTPUT "); err != nil {
+		return err
+	}
+	if len(cols) == 0 {
+		_, err := buf.WriteString(prefix + ".*")
+		return err
+	}
+	for i, col := range cols {
+		if i > 0 {
+			if _, err := buf.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if _, err := buf.WriteString(prefix + "."); err != nil {
+			return err
+		}
+		if err := statement.dialect.Quoter().QuoteTo(buf, col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeReturning writes the RETURNING clause at the end of the SQL. The placeholders of
+// RETURNING INTO are written for Oracle and Dameng, and the output arguments should be
+// appended by the caller.
+func (statement *Statement) writeReturning(w *builder.BytesWriter, cols []string) error {
+	switch statement.dialect.URI().DBType {
+	case schemas.POSTGRES, schemas.SQLITE:
+		if _, err := w.WriteString(" RETURNING "); err != nil {
+			return err
+		}
+		if len(cols) == 0 {
+			_, err := w.WriteString("*")
+			return err
+		}
+		return statement.dialect.Quoter().JoinWrite(w.Builder, cols, ",")
+	case schemas.ORACLE, schemas.DAMENG:
+		if len(cols) == 0 {
+			return ErrReturningNotSupported
+		}
+		if _, err := w.WriteString(" RETURNING "); err != nil {
+			return err
+		}
+		if err := statement.dialect.Quoter().JoinWrite(w.Builder, cols, ","); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(" INTO "); err != nil {
+			return err
+		}
+		return statement.writeQuestions(w, len(cols))
+	case schemas.MSSQL:
+		// the OUTPUT clause has been written
+		return nil
+	default:
+		return ErrReturningNotSupported
+	}
+}
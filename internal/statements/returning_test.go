@@ -0,0 +1,85 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+func TestReturningSQL(t *testing.T) {
+	kases := []struct {
+		dbType schemas.DBType
+		insert string
+		update string
+		delete string
+	}{
+		{
+			schemas.POSTGRES,
+			`INSERT INTO "upsert_user" ("name","email") VALUES (?,?) RETURNING "email","created","id"`,
+			`UPDATE "upsert_user" SET email = ?, "version" = "version" + 1 WHERE name=? RETURNING "id","name","email","created","updated","version"`,
+			`DELETE FROM "upsert_user" WHERE name=? RETURNING "id"`,
+		},
+		{
+			schemas.SQLITE,
+			"INSERT INTO `upsert_user` (`name`,`email`) VALUES (?,?) RETURNING `email`,`created`,`id`",
+			"UPDATE `upsert_user` SET email = ?, `version` = `version` + 1 WHERE name=? RETURNING `id`,`name`,`email`,`created`,`updated`,`version`",
+			"DELETE FROM `upsert_user` WHERE name=? RETURNING `id`",
+		},
+		{
+			schemas.MSSQL,
+			"INSERT INTO [upsert_user] ([name],[email]) OUTPUT INSERTED.[email],INSERTED.[created],INSERTED.[id] VALUES (?,?)",
+			"UPDATE [upsert_user] SET email = ?, [version] = [version] + 1 OUTPUT INSERTED.[id],INSERTED.[name],INSERTED.[email],INSERTED.[created],INSERTED.[updated],INSERTED.[version] FROM [upsert_user] WHERE name=?",
+			"DELETE FROM [upsert_user] OUTPUT DELETED.[id] WHERE name=?",
+		},
+		{
+			schemas.ORACLE,
+			`INSERT INTO "upsert_user" ("name","email","id") VALUES (?,?,SEQ_UPSERT_USER.nextval) RETURNING "email","created","id" INTO ?,?,?`,
+			`UPDATE "upsert_user" SET email = ?, "version" = "version" + 1 WHERE name=? RETURNING "id","name","email","created","updated","version" INTO ?,?,?,?,?,?`,
+			`DELETE FROM "upsert_user" WHERE name=? RETURNING "id" INTO ?`,
+		},
+	}
+
+	nowTime := func(*schemas.Column) (interface{}, time.Time, error) {
+		return nil, time.Time{}, nil
+	}
+
+	for _, kase := range kases {
+		statement := newUpsertStatement(t, kase.dbType)
+		statement.SetReturning("email", "created")
+		sql, _, err := statement.GenInsertSQL([]string{"name", "email"}, []interface{}{"a", "b"})
+		assert.NoError(t, err)
+		assert.EqualValues(t, kase.insert, sql)
+
+		statement = newUpsertStatement(t, kase.dbType)
+		statement.SetReturningDest(&[]UpsertUser{})
+		statement.And(builder.Eq{"name": "a"})
+		w := builder.NewWriter()
+		assert.NoError(t, statement.WriteUpdate(w, statement.Conds(), reflect.ValueOf(UpsertUser{}), []string{"email = ?"}, []interface{}{"b"}))
+		assert.EqualValues(t, kase.update, w.String())
+
+		statement = newUpsertStatement(t, kase.dbType)
+		statement.SetReturning("id")
+		statement.And(builder.Eq{"name": "a"})
+		realWriter := builder.NewWriter()
+		assert.NoError(t, statement.WriteDelete(realWriter, builder.NewWriter(), nowTime))
+		assert.EqualValues(t, kase.delete, realWriter.String())
+	}
+
+	statement := newUpsertStatement(t, schemas.MYSQL)
+	statement.SetReturning()
+	_, _, err := statement.GenInsertSQL([]string{"name", "email"}, []interface{}{"a", "b"})
+	assert.ErrorIs(t, err, ErrReturningNotSupported)
+
+	statement = newUpsertStatement(t, schemas.DAMENG)
+	statement.SetReturning()
+	err = statement.WriteInsertMultiple(builder.NewWriter(), "upsert_user", []string{"name"}, []string{"?", "?"})
+	assert.ErrorIs(t, err, ErrReturningNotSupported)
+}
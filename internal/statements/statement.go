@@ -91,6 +91,12 @@ type Statement struct {
 	Context         contexts.ContextCache
 	LastError       error
 	indexHints      []indexHint
+	Upsert          *Upsert
+	Returning       *Returning
+	ctes            []cte
+	compounds       []compoundQuery
+	fromQuery       *Statement
+	keysetCond      builder.Cond
 }
 
 // NewStatement creates a new statement
@@ -161,7 +167,13 @@ func (statement *Statement) Reset() {
 	statement.IncrColumns = exprParams{}
 	statement.DecrColumns = exprParams{}
 	statement.ExprColumns = exprParams{}
+	statement.Upsert = nil
+	statement.Returning = nil
+	statement.ctes = nil
+	statement.compounds = nil
+	statement.fromQuery = nil
 	statement.cond = builder.NewCond()
+	statement.keysetCond = builder.NewCond()
 	statement.BufferSize = 0
 	statement.Context = nil
 	statement.LastError = nil
@@ -281,6 +293,13 @@ func (statement *Statement) Limit(limit int, start ...int) *Statement {
 
 // SetTable tempororily set table name, the parameter could be a string or a pointer of struct
 func (statement *Statement) SetTable(tableNameOrBean interface{}) error {
+	// the subquery is queried as a table named by its alias or table name
+	if query, ok := tableNameOrBean.(*Statement); ok {
+		statement.fromQuery = query
+		statement.AltTableName = subQueryAlias(query)
+		return nil
+	}
+
 	v := rValue(tableNameOrBean)
 	t := v.Type()
 	if t.Kind() == reflect.Struct {
@@ -291,7 +310,8 @@ func (statement *Statement) SetTable(tableNameOrBean interface{}) error {
 		}
 	}
 
-	statement.AltTableName = dialects.FullTableName(statement.dialect, statement.tagParser.GetTableMapper(), tableNameOrBean, true)
+	// the common table expressions have no schema
+	statement.AltTableName = dialects.FullTableName(statement.dialect, statement.tagParser.GetTableMapper(), tableNameOrBean, !statement.isCTETable(tableNameOrBean))
 	return nil
 }
 
@@ -615,7 +635,8 @@ func (statement *Statement) BuildConds(table *schemas.Table, bean interface{}, i
 
 // MergeConds merge conditions from bean and id
 func (statement *Statement) MergeConds(bean interface{}) error {
-	if !statement.NoAutoCondition && statement.RefTable != nil {
+	// the conditions of the bean have been applied by the queries of the compound query
+	if !statement.NoAutoCondition && statement.RefTable != nil && !statement.IsCompound() {
 		addedTableName := len(statement.joins) > 0
 		autoCond, err := statement.BuildConds(statement.RefTable, bean, true, true, false, true, addedTableName)
 		if err != nil {
@@ -37,8 +37,7 @@ func TestMain(m *testing.M) {
 	if tagParser == nil {
 		panic("tags parser is nil")
 	}
-	m.Run()
-	os.Exit(0)
+	os.Exit(m.Run())
 }
 
 var colStrTests = []struct {
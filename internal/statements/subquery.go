@@ -0,0 +1,124 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"fmt"
+	"strings"
+
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+// subQueryCond represents a condition with a subquery, i.e. col IN (subquery) or EXISTS (subquery).
+// The SQL of the subquery is generated when the condition is written, so the arguments are always
+// merged in the order of the SQL.
+type subQueryCond struct {
+	prefix string
+	query  *Statement
+}
+
+var _ builder.Cond = subQueryCond{}
+
+func (cond subQueryCond) WriteTo(w builder.Writer) error {
+	sql, args, err := cond.query.GenSubQuerySQL()
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, cond.prefix, " ("); err != nil {
+		return err
+	}
+	if err := writeQuoted(w, sql); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, ")"); err != nil {
+		return err
+	}
+	w.Append(args...)
+	return nil
+}
+
+func (cond subQueryCond) And(conds ...builder.Cond) builder.Cond {
+	return builder.And(cond, builder.And(conds...))
+}
+
+func (cond subQueryCond) Or(conds ...builder.Cond) builder.Cond {
+	return builder.Or(cond, builder.Or(conds...))
+}
+
+func (cond subQueryCond) IsValid() bool {
+	return cond.query != nil
+}
+
+// writeQuoted writes the SQL which has been quoted by the dialect, the quotes will not be replaced again
+func writeQuoted(w builder.Writer, sql string) error {
+	if replacer, ok := w.(*QuoteReplacer); ok {
+		_, err := replacer.BytesWriter.Builder.WriteString(sql)
+		return err
+	}
+	_, err := fmt.Fprint(w, sql)
+	return err
+}
+
+// isSubQueryOrdered returns true if the orders are written when the statement is a subquery. ORDER
+// BY is invalid in the subqueries without TOP or OFFSET on MSSQL, and the orders don't change the
+// rows of the subqueries without pagination.
+func (statement *Statement) isSubQueryOrdered() bool {
+	return statement.HasOrderBy() &&
+		(statement.dialect.URI().DBType != schemas.MSSQL || statement.LimitN != nil || statement.Start > 0)
+}
+
+// IsSubQueryTable returns true if the table is a subquery
+func (statement *Statement) IsSubQueryTable() bool {
+	return statement.fromQuery != nil
+}
+
+// subQueryAlias returns the alias of the subquery when it's queried as a table, it's the alias
+// or the table name of the subquery
+func subQueryAlias(query *Statement) string {
+	if query.TableAlias != "" {
+		return query.TableAlias
+	}
+	fields := strings.Split(query.TableName(), ".")
+	return query.dialect.Quoter().Trim(fields[len(fields)-1])
+}
+
+// writeSubQueryTable writes the subquery as a table with the alias
+func (statement *Statement) writeSubQueryTable(w *builder.BytesWriter, query *Statement, alias string) error {
+	sql, args, err := query.GenSubQuerySQL()
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, "(", sql, ")"); err != nil {
+		return err
+	}
+	w.Append(args...)
+	return statement.writeTableAlias(w, alias)
+}
+
+// writeTableAlias writes the alias of a subquery, AS is not supported by Oracle
+func (statement *Statement) writeTableAlias(w *builder.BytesWriter, alias string) error {
+	if alias == "" {
+		return nil
+	}
+	if statement.dialect.URI().DBType == schemas.ORACLE {
+		_, err := fmt.Fprint(w, " ", statement.quote(alias))
+		return err
+	}
+	_, err := fmt.Fprint(w, " AS ", statement.quote(alias))
+	return err
+}
+
+// WhereExists generates "WHERE EXISTS (subquery)" statement
+func (statement *Statement) WhereExists(query *Statement) *Statement {
+	statement.cond = statement.cond.And(subQueryCond{prefix: "EXISTS", query: query})
+	return statement
+}
+
+// WhereNotExists generates "WHERE NOT EXISTS (subquery)" statement
+func (statement *Statement) WhereNotExists(query *Statement) *Statement {
+	statement.cond = statement.cond.And(subQueryCond{prefix: "NOT EXISTS", query: query})
+	return statement
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+	"xorm.io/xorm/dialects"
+	"xorm.io/xorm/schemas"
+)
+
+func TestSubQuerySQL(t *testing.T) {
+	newSub := func(dbType schemas.DBType) *Statement {
+		sub := newUpsertStatement(t, dbType)
+		assert.NoError(t, sub.SetTable(UpsertUser{}))
+		sub.Cols("id").Where("email = ?", "a@b.c")
+		return sub
+	}
+
+	// the arguments are merged in the order of the SQL
+	statement := newUpsertStatement(t, schemas.POSTGRES)
+	statement.Where("name = ?", "a").In("id", newSub(schemas.POSTGRES)).And("version > ?", 1)
+	sql, args, err := statement.GenFindSQL(builder.NewCond())
+	assert.NoError(t, err)
+	assert.EqualValues(t, `SELECT "id", "name", "email", "created", "updated", "version" FROM "upsert_user" WHERE (name = ?) AND "id" IN (SELECT "id" FROM "upsert_user" WHERE (email = ?)) AND (version > ?)`, sql)
+	assert.EqualValues(t, []interface{}{"a", "a@b.c", 1}, args)
+
+	statement = newUpsertStatement(t, schemas.POSTGRES)
+	statement.NotIn("id", newSub(schemas.POSTGRES)).WhereNotExists(newSub(schemas.POSTGRES))
+	sql, args, err = statement.GenCountSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `SELECT count(*) FROM "upsert_user" WHERE "id" NOT IN (SELECT "id" FROM "upsert_user" WHERE (email = ?)) AND NOT EXISTS (SELECT "id" FROM "upsert_user" WHERE (email = ?))`, sql)
+	assert.EqualValues(t, []interface{}{"a@b.c", "a@b.c"}, args)
+
+	// the quote policy of the dialect is followed by the subqueries
+	sub := newSub(schemas.MYSQL)
+	sub.dialect.SetQuotePolicy(dialects.QuotePolicyNone)
+	statement = newUpsertStatement(t, schemas.MYSQL)
+	statement.dialect.SetQuotePolicy(dialects.QuotePolicyNone)
+	statement.WhereExists(sub)
+	sql, args, err = statement.GenCountSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT count(*) FROM upsert_user WHERE EXISTS (SELECT id FROM upsert_user WHERE (email = ?))", sql)
+	assert.EqualValues(t, []interface{}{"a@b.c"}, args)
+
+	// the subquery is named by its alias or its table name
+	sub = newSub(schemas.MSSQL)
+	sub.Alias("s")
+	statement = newUpsertStatement(t, schemas.MSSQL)
+	assert.NoError(t, statement.SetTable(UpsertUser{}))
+	statement.Join("INNER", sub, "s.id = upsert_user.id")
+	sql, args, err = statement.GenCountSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "SELECT count(*) FROM [upsert_user] INNER JOIN (SELECT [id] FROM [upsert_user] AS [s] WHERE (email = ?)) AS [s] ON s.id = upsert_user.id", sql)
+	assert.EqualValues(t, []interface{}{"a@b.c"}, args)
+
+	statement = newUpsertStatement(t, schemas.ORACLE)
+	assert.NoError(t, statement.SetTable(newSub(schemas.ORACLE)))
+	statement.Where("name = ?", "a")
+	sql, args, err = statement.GenCountSQL()
+	assert.NoError(t, err)
+	assert.EqualValues(t, `SELECT count(*) FROM (SELECT "id" FROM "upsert_user" WHERE (email = ?)) "upsert_user" WHERE (name = ?)`, sql)
+	assert.EqualValues(t, []interface{}{"a@b.c", "a"}, args)
+}
@@ -43,6 +43,17 @@ func (statement *Statement) writeAlias(w *builder.BytesWriter) error {
 }
 
 func (statement *Statement) writeTableName(w *builder.BytesWriter) error {
+	if statement.IsCompound() {
+		return statement.writeCompoundTable(w)
+	}
+	if statement.fromQuery != nil {
+		// the alias will be written by writeAlias if there is one
+		alias := statement.AltTableName
+		if statement.TableAlias != "" {
+			alias = ""
+		}
+		return statement.writeSubQueryTable(w, statement.fromQuery, alias)
+	}
 	if statement.dialect.URI().DBType == schemas.MSSQL && strings.Contains(statement.TableName(), "..") {
 		if _, err := fmt.Fprint(w, statement.TableName()); err != nil {
 			return err
@@ -48,7 +48,7 @@ func (statement *Statement) ifAddColUpdate(col *schemas.Column, includeVersion,
 		return false, nil
 	}
 
-	if col.MapType == schemas.ONLYFROMDB {
+	if col.MapType == schemas.ONLYFROMDB || col.IsGenerated() {
 		return false, nil
 	}
 
@@ -68,6 +68,26 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 	includeVersion, includeUpdated, includeNil,
 	includeAutoIncr, update bool,
 ) ([]string, []interface{}, error) {
+	colNames, _, args, err := statement.buildUpdates(tableValue, includeVersion, includeUpdated, includeNil,
+		includeAutoIncr, update)
+	return colNames, args, err
+}
+
+// BuildUpdateColumns returns the names but not the set expressions of the columns
+// to be updated and the values, see BuildUpdates
+func (statement *Statement) BuildUpdateColumns(tableValue reflect.Value,
+	includeVersion, includeUpdated, includeNil,
+	includeAutoIncr, update bool,
+) ([]string, []interface{}, error) {
+	_, cols, args, err := statement.buildUpdates(tableValue, includeVersion, includeUpdated, includeNil,
+		includeAutoIncr, update)
+	return cols, args, err
+}
+
+func (statement *Statement) buildUpdates(tableValue reflect.Value,
+	includeVersion, includeUpdated, includeNil,
+	includeAutoIncr, update bool,
+) ([]string, []string, []interface{}, error) {
 	table := statement.RefTable
 	allUseBool := statement.allUseBool
 	useAllCols := statement.useAllCols
@@ -75,13 +95,14 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 	nullableMap := statement.NullableMap
 
 	colNames := make([]string, 0)
+	cols := make([]string, 0)
 	args := make([]interface{}, 0)
 
 	for _, col := range table.Columns() {
 		ok, err := statement.ifAddColUpdate(col, includeVersion, includeUpdated, includeNil,
 			includeAutoIncr, update)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		if !ok {
 			continue
@@ -89,7 +110,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 
 		fieldValuePtr, err := col.ValueOfV(&tableValue)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		if fieldValuePtr == nil {
 			continue
@@ -131,7 +152,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 				}
 				data, err := structConvert.ToDB()
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 				if data != nil {
 					val = data
@@ -146,7 +167,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 		if structConvert, ok := fieldValue.Interface().(convert.Conversion); ok && !fieldValue.IsNil() {
 			data, err := structConvert.ToDB()
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			if data != nil {
 				val = data
@@ -162,6 +183,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 				if includeNil {
 					args = append(args, nil)
 					colNames = append(colNames, fmt.Sprintf("%v=?", statement.quote(col.Name)))
+					cols = append(cols, col.Name)
 				}
 				continue
 			} else if !fieldValue.IsValid() {
@@ -216,7 +238,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 				}
 				val, err = dialects.FormatColumnTime(statement.dialect, statement.defaultTimeZone, col, t)
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 			} else if nulType, ok := fieldValue.Interface().(driver.Valuer); ok {
 				val, _ = nulType.Value()
@@ -238,7 +260,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 								continue
 							}
 						} else {
-							return nil, nil, errors.New("Not supported multiple primary keys")
+							return nil, nil, nil, errors.New("Not supported multiple primary keys")
 						}
 					}
 				} else {
@@ -246,7 +268,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 					if requiredField || !utils.IsStructZero(fieldValue) {
 						bytes, err := json.DefaultJSONHandler.Marshal(fieldValue.Interface())
 						if err != nil {
-							return nil, nil, fmt.Errorf("mashal %v failed", fieldValue.Interface())
+							return nil, nil, nil, fmt.Errorf("mashal %v failed", fieldValue.Interface())
 						}
 						if col.SQLType.IsText() {
 							val = string(bytes)
@@ -275,7 +297,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 			if col.SQLType.IsText() {
 				bytes, err := json.DefaultJSONHandler.Marshal(fieldValue.Interface())
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, nil, err
 				}
 				val = string(bytes)
 			} else if col.SQLType.IsBlob() {
@@ -294,7 +316,7 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 				} else {
 					bytes, err = json.DefaultJSONHandler.Marshal(fieldValue.Interface())
 					if err != nil {
-						return nil, nil, err
+						return nil, nil, nil, err
 					}
 					val = bytes
 				}
@@ -308,9 +330,10 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 	APPEND:
 		args = append(args, val)
 		colNames = append(colNames, fmt.Sprintf("%v = ?", statement.quote(col.Name)))
+		cols = append(cols, col.Name)
 	}
 
-	return colNames, args, nil
+	return colNames, cols, args, nil
 }
 
 func (statement *Statement) writeUpdateTop(updateWriter *builder.BytesWriter) error {
@@ -656,14 +679,19 @@ func (statement *Statement) writeUpdateSets(w *builder.BytesWriter, v reflect.Va
 var ErrNoColumnsTobeUpdated = errors.New("no columns found to be updated")
 
 func (statement *Statement) WriteUpdate(updateWriter *builder.BytesWriter, cond builder.Cond, v reflect.Value, colNames []string, args []interface{}) error {
+	var err error
 	switch statement.dialect.URI().DBType {
 	case schemas.MYSQL:
-		return statement.writeUpdateMySQL(updateWriter, cond, v, colNames, args)
+		err = statement.writeUpdateMySQL(updateWriter, cond, v, colNames, args)
 	case schemas.MSSQL:
-		return statement.writeUpdateMSSQL(updateWriter, cond, v, colNames, args)
+		err = statement.writeUpdateMSSQL(updateWriter, cond, v, colNames, args)
 	default:
-		return statement.writeUpdateCommon(updateWriter, cond, v, colNames, args)
+		err = statement.writeUpdateCommon(updateWriter, cond, v, colNames, args)
+	}
+	if err != nil || !statement.IsReturning() {
+		return err
 	}
+	return statement.writeReturning(updateWriter, statement.ReturningColumns())
 }
 
 func (statement *Statement) writeUpdateMySQL(updateWriter *builder.BytesWriter, cond builder.Cond, v reflect.Value, colNames []string, args []interface{}) error {
@@ -706,6 +734,12 @@ func (statement *Statement) writeUpdateMSSQL(updateWriter *builder.BytesWriter,
 		return err
 	}
 
+	if statement.IsReturning() {
+		if err := statement.writeReturningOutput(updateWriter.Builder, "INSERTED", statement.ReturningColumns()); err != nil {
+			return err
+		}
+	}
+
 	// write from
 	joinConds, err := statement.writeUpdateFrom(updateWriter)
 	if err != nil {
@@ -0,0 +1,334 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+// ErrUpdateMultiNoPrimaryKey represents the rows cannot be updated without the primary keys
+var ErrUpdateMultiNoPrimaryKey = errors.New("UpdateMulti needs the primary keys of all the beans")
+
+// the alias and the column prefixes of the VALUES list on Postgres
+const (
+	valuesAlias         = "xv"
+	valuesPKPrefix      = "k"
+	valuesColPrefix     = "c"
+	valuesSetFlagPrefix = "s"
+	valuesVersion       = "v"
+)
+
+// UpdateMultiRow represents a row to be updated by its primary key
+type UpdateMultiRow struct {
+	// PK are the values of the primary key columns
+	PK []interface{}
+	// Version is the value of the version column to be checked, nil means no check
+	Version interface{}
+	// Cols are the columns to be updated and Args are the values
+	Cols []string
+	Args []interface{}
+}
+
+func (row *UpdateMultiRow) arg(col string) (interface{}, bool) {
+	for i, c := range row.Cols {
+		if c == col {
+			return row.Args[i], true
+		}
+	}
+	return nil, false
+}
+
+// updateMultiColumns returns the columns updated by any of the rows in the order of the table,
+// and whether the columns are updated by all the rows
+func (statement *Statement) updateMultiColumns(rows []*UpdateMultiRow) ([]string, []bool) {
+	var cols []string
+	var updatedByAll []bool
+	for _, col := range statement.RefTable.Columns() {
+		var count int
+		for _, row := range rows {
+			if _, ok := row.arg(col.Name); ok {
+				count++
+			}
+		}
+		if count > 0 {
+			cols = append(cols, col.Name)
+			updatedByAll = append(updatedByAll, count == len(rows))
+		}
+	}
+	return cols, updatedByAll
+}
+
+// WriteUpdateMulti writes the SQL to update the rows by their primary keys in one statement, it's
+// UPDATE ... FROM (VALUES ...) on Postgres and UPDATE ... SET col = CASE ... END on the others.
+// The sets of colNames and args, Incr, Decr and SetExpr are applied to all the rows, and the
+// version column is increased if the versions of the rows should be checked.
+func (statement *Statement) WriteUpdateMulti(w *builder.BytesWriter, cond builder.Cond, rows []*UpdateMultiRow, colNames []string, args []interface{}) error {
+	table := statement.RefTable
+	if table == nil || len(table.PrimaryKeys) == 0 {
+		return ErrUpdateMultiNoPrimaryKey
+	}
+	if len(rows) == 0 {
+		return ErrNoColumnsTobeUpdated
+	}
+
+	cols, updatedByAll := statement.updateMultiColumns(rows)
+	checkVersion := table.Version != "" && rows[0].Version != nil
+	setNumber := len(cols) + len(colNames) + len(statement.IncrColumns) + len(statement.DecrColumns) + len(statement.ExprColumns)
+	if setNumber == 0 && !checkVersion {
+		return ErrNoColumnsTobeUpdated
+	}
+
+	if statement.dialect.URI().DBType == schemas.POSTGRES {
+		return statement.writeUpdateMultiValues(w, cond, rows, cols, updatedByAll, colNames, args, checkVersion)
+	}
+	return statement.writeUpdateMultiCase(w, cond, rows, cols, colNames, args, checkVersion)
+}
+
+func (statement *Statement) writeUpdateMultiCase(w *builder.BytesWriter, cond builder.Cond, rows []*UpdateMultiRow, cols []string,
+	colNames []string, args []interface{}, checkVersion bool,
+) error {
+	table := statement.RefTable
+	if _, err := fmt.Fprint(w, "UPDATE ", statement.quote(statement.TableName()), " SET "); err != nil {
+		return err
+	}
+
+	for i, col := range cols {
+		if i > 0 {
+			if _, err := w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(w, statement.quote(col), " = CASE"); err != nil {
+			return err
+		}
+		if len(table.PrimaryKeys) == 1 {
+			if _, err := fmt.Fprint(w, " ", statement.quote(table.PrimaryKeys[0])); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			arg, ok := row.arg(col)
+			if !ok {
+				continue
+			}
+			if _, err := w.WriteString(" WHEN "); err != nil {
+				return err
+			}
+			if len(table.PrimaryKeys) == 1 {
+				if _, err := w.WriteString("?"); err != nil {
+					return err
+				}
+				w.Append(row.PK[0])
+			} else {
+				eq := make(builder.Eq, len(table.PrimaryKeys))
+				for j, pk := range table.PrimaryKeys {
+					eq[statement.quote(pk)] = row.PK[j]
+				}
+				if err := eq.WriteTo(w); err != nil {
+					return err
+				}
+			}
+			if _, err := w.WriteString(" THEN ?"); err != nil {
+				return err
+			}
+			w.Append(arg)
+		}
+		if _, err := fmt.Fprint(w, " ELSE ", statement.quote(col), " END"); err != nil {
+			return err
+		}
+	}
+
+	if err := statement.writeUpdateMultiSets(w, len(cols) > 0, colNames, args, checkVersion, ""); err != nil {
+		return err
+	}
+
+	var rowsCond builder.Cond
+	if len(table.PrimaryKeys) == 1 && !checkVersion {
+		pks := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			pks = append(pks, row.PK[0])
+		}
+		rowsCond = builder.In(statement.quote(table.PrimaryKeys[0]), pks...)
+	} else {
+		conds := make([]builder.Cond, 0, len(rows))
+		for _, row := range rows {
+			eq := make(builder.Eq, len(table.PrimaryKeys)+1)
+			for j, pk := range table.PrimaryKeys {
+				eq[statement.quote(pk)] = row.PK[j]
+			}
+			if checkVersion {
+				eq[statement.quote(table.Version)] = row.Version
+			}
+			conds = append(conds, eq)
+		}
+		rowsCond = builder.Or(conds...)
+	}
+	return statement.writeWhereCond(w, cond.And(rowsCond))
+}
+
+// writeUpdateMultiSets writes the sets for all the rows, the version column will be qualified by the table
+func (statement *Statement) writeUpdateMultiSets(w *builder.BytesWriter, hasPreviousSets bool, colNames []string,
+	args []interface{}, checkVersion bool, qualifier string,
+) error {
+	for _, colName := range colNames {
+		if hasPreviousSets {
+			if _, err := w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		if _, err := w.WriteString(colName); err != nil {
+			return err
+		}
+		hasPreviousSets = true
+	}
+	w.Append(args...)
+
+	if err := statement.writeIncrSets(w, hasPreviousSets); err != nil {
+		return err
+	}
+	hasPreviousSets = hasPreviousSets || len(statement.IncrColumns) > 0
+	if err := statement.writeDecrSets(w, hasPreviousSets); err != nil {
+		return err
+	}
+	hasPreviousSets = hasPreviousSets || len(statement.DecrColumns) > 0
+	if err := statement.writeExprSets(w, hasPreviousSets); err != nil {
+		return err
+	}
+	hasPreviousSets = hasPreviousSets || len(statement.ExprColumns) > 0
+
+	if checkVersion {
+		if hasPreviousSets {
+			if _, err := w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		version := statement.quote(statement.RefTable.Version)
+		if _, err := fmt.Fprint(w, version, " = ", qualifier, version, " + 1"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// castType returns the type of the column to cast the values to
+func (statement *Statement) castType(colName string) string {
+	col := *statement.RefTable.GetColumn(colName)
+	// the type of serial columns cannot be used to cast
+	col.IsAutoIncrement = false
+	return statement.dialect.SQLType(&col)
+}
+
+func (statement *Statement) writeUpdateMultiValues(w *builder.BytesWriter, cond builder.Cond, rows []*UpdateMultiRow, cols []string,
+	updatedByAll []bool, colNames []string, args []interface{}, checkVersion bool,
+) error {
+	table := statement.RefTable
+	tableName := statement.quote(statement.TableName())
+	if _, err := fmt.Fprint(w, "UPDATE ", tableName, " SET "); err != nil {
+		return err
+	}
+
+	for i, col := range cols {
+		if i > 0 {
+			if _, err := w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		value := valuesAlias + "." + valuesColPrefix + strconv.Itoa(i)
+		var err error
+		if updatedByAll[i] {
+			_, err = fmt.Fprint(w, statement.quote(col), " = ", value)
+		} else {
+			// the rows not updating the column keep the values
+			_, err = fmt.Fprintf(w, "%s = CASE WHEN %s.%s%d THEN %s ELSE %s.%s END", statement.quote(col),
+				valuesAlias, valuesSetFlagPrefix, i, value, tableName, statement.quote(col))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := statement.writeUpdateMultiSets(w, len(cols) > 0, colNames, args, checkVersion, tableName+"."); err != nil {
+		return err
+	}
+
+	if _, err := w.WriteString(" FROM (VALUES "); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if i > 0 {
+			if _, err := w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+		if _, err := w.WriteString("("); err != nil {
+			return err
+		}
+		values := make([]string, 0, len(table.PrimaryKeys)+len(cols)*2+1)
+		for j, pk := range table.PrimaryKeys {
+			values = append(values, "CAST(? AS "+statement.castType(pk)+")")
+			w.Append(row.PK[j])
+		}
+		if checkVersion {
+			values = append(values, "CAST(? AS "+statement.castType(table.Version)+")")
+			w.Append(row.Version)
+		}
+		for j, col := range cols {
+			arg, ok := row.arg(col)
+			if ok {
+				values = append(values, "CAST(? AS "+statement.castType(col)+")")
+				w.Append(arg)
+			} else {
+				values = append(values, "CAST(NULL AS "+statement.castType(col)+")")
+			}
+			if !updatedByAll[j] {
+				values = append(values, strconv.FormatBool(ok))
+			}
+		}
+		if _, err := w.WriteString(strings.Join(values, ",")); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(")"); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(table.PrimaryKeys)+len(cols)*2+1)
+	for j := range table.PrimaryKeys {
+		names = append(names, valuesPKPrefix+strconv.Itoa(j))
+	}
+	if checkVersion {
+		names = append(names, valuesVersion)
+	}
+	for j := range cols {
+		names = append(names, valuesColPrefix+strconv.Itoa(j))
+		if !updatedByAll[j] {
+			names = append(names, valuesSetFlagPrefix+strconv.Itoa(j))
+		}
+	}
+	if _, err := fmt.Fprint(w, ") AS ", valuesAlias, "("); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(strings.Join(names, ",")); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(")"); err != nil {
+		return err
+	}
+
+	rowsCond := builder.NewCond()
+	for j, pk := range table.PrimaryKeys {
+		rowsCond = rowsCond.And(builder.Expr(fmt.Sprintf("%s.%s = %s.%s%d", tableName, statement.quote(pk), valuesAlias, valuesPKPrefix, j)))
+	}
+	if checkVersion {
+		rowsCond = rowsCond.And(builder.Expr(fmt.Sprintf("%s.%s = %s.%s", tableName, statement.quote(table.Version), valuesAlias, valuesVersion)))
+	}
+	return statement.writeWhereCond(w, rowsCond.And(cond))
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+	"xorm.io/xorm/caches"
+	"xorm.io/xorm/dialects"
+	"xorm.io/xorm/names"
+	"xorm.io/xorm/schemas"
+	"xorm.io/xorm/tags"
+)
+
+type UpdateMultiUser struct {
+	Id      int64
+	Name    string
+	Email   string
+	Updated time.Time `xorm:"updated"`
+	Version int       `xorm:"version"`
+}
+
+func newUpdateMultiStatement(t *testing.T, dbType schemas.DBType) *Statement {
+	dialect := dialects.QueryDialect(dbType)
+	assert.NoError(t, dialect.Init(&dialects.URI{DBType: dbType}))
+	parser := tags.NewParser("xorm", dialect, names.SnakeMapper{}, names.SnakeMapper{}, caches.NewManager())
+	statement := NewStatement(dialect, parser, time.Local)
+	assert.NoError(t, statement.SetRefValue(reflect.ValueOf(UpdateMultiUser{})))
+	return statement
+}
+
+func TestUpdateMultiSQL(t *testing.T) {
+	newRows := func(withVersion bool) []*UpdateMultiRow {
+		rows := []*UpdateMultiRow{
+			{PK: []interface{}{1}, Cols: []string{"name", "email"}, Args: []interface{}{"a", "a@b.c"}},
+			{PK: []interface{}{2}, Cols: []string{"name"}, Args: []interface{}{"b"}},
+		}
+		if withVersion {
+			rows[0].Version = 1
+			rows[1].Version = 3
+		}
+		return rows
+	}
+
+	kases := []struct {
+		dbType      schemas.DBType
+		withVersion bool
+		expected    string
+		args        []interface{}
+	}{
+		{
+			schemas.POSTGRES,
+			true,
+			`UPDATE "update_multi_user" SET "name" = xv.c0, "email" = CASE WHEN xv.s1 THEN xv.c1 ELSE "update_multi_user"."email" END, "updated" = ?, "version" = "update_multi_user"."version" + 1 FROM (VALUES (CAST(? AS BIGINT),CAST(? AS INTEGER),CAST(? AS VARCHAR(255)),CAST(? AS VARCHAR(255)),true), (CAST(? AS BIGINT),CAST(? AS INTEGER),CAST(? AS VARCHAR(255)),CAST(NULL AS VARCHAR(255)),false)) AS xv(k0,v,c0,c1,s1) WHERE ("update_multi_user"."id" = xv.k0) AND ("update_multi_user"."version" = xv.v) AND (x=?)`,
+			[]interface{}{"now", 1, 1, "a", "a@b.c", 2, 3, "b", 1},
+		},
+		{
+			schemas.MYSQL,
+			true,
+			"UPDATE `update_multi_user` SET `name` = CASE `id` WHEN ? THEN ? WHEN ? THEN ? ELSE `name` END, `email` = CASE `id` WHEN ? THEN ? ELSE `email` END, `updated` = ?, `version` = `version` + 1 WHERE (x=?) AND ((`id`=? AND `version`=?) OR (`id`=? AND `version`=?))",
+			[]interface{}{1, "a", 2, "b", 1, "a@b.c", "now", 1, 1, 1, 2, 3},
+		},
+		{
+			schemas.MSSQL,
+			false,
+			"UPDATE [update_multi_user] SET [name] = CASE [id] WHEN ? THEN ? WHEN ? THEN ? ELSE [name] END, [email] = CASE [id] WHEN ? THEN ? ELSE [email] END, [updated] = ? WHERE (x=?) AND [id] IN (?,?)",
+			[]interface{}{1, "a", 2, "b", 1, "a@b.c", "now", 1, 1, 2},
+		},
+	}
+
+	for _, kase := range kases {
+		t.Run(string(kase.dbType), func(t *testing.T) {
+			statement := newUpdateMultiStatement(t, kase.dbType)
+			w := builder.NewWriter()
+			err := statement.WriteUpdateMulti(w, builder.Expr("x=?", 1), newRows(kase.withVersion),
+				[]string{statement.quote("updated") + " = ?"}, []interface{}{"now"})
+			assert.NoError(t, err)
+			assert.EqualValues(t, kase.expected, w.String())
+			assert.EqualValues(t, kase.args, w.Args())
+		})
+	}
+
+	statement := newUpdateMultiStatement(t, schemas.SQLITE)
+	w := builder.NewWriter()
+	err := statement.WriteUpdateMulti(w, builder.NewCond(), []*UpdateMultiRow{{PK: []interface{}{1}}}, nil, nil)
+	assert.EqualValues(t, ErrNoColumnsTobeUpdated, err)
+}
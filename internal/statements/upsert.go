@@ -0,0 +1,484 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"xorm.io/builder"
+	"xorm.io/xorm/internal/utils"
+	"xorm.io/xorm/schemas"
+)
+
+var (
+	// ErrUpsertWithWhere represents an upsert cannot be used with insert where
+	ErrUpsertWithWhere = errors.New("Upsert cannot be used with conditions")
+	// ErrUpsertNoConflictColumns represents the conflict columns cannot be found for an upsert
+	ErrUpsertNoConflictColumns = errors.New("Upsert needs conflict columns or a primary key")
+)
+
+const (
+	mergeTargetAlias = "xt"
+	mergeSourceAlias = "xs"
+)
+
+// Upsert represents how to handle the conflicts when inserting
+type Upsert struct {
+	// ConflictCols are the columns of the unique constraint to detect the conflicts,
+	// the primary key will be used if it's empty. MySQL detects the conflicts on all
+	// unique constraints and ignores them.
+	ConflictCols []string
+	// UpdateCols are the columns to be updated when conflicting, all the inserted columns
+	// will be updated except the conflict, auto increment and created columns if it's empty
+	UpdateCols []string
+	// DoNothing means the conflicting rows will be kept without any changes
+	DoNothing bool
+}
+
+// rawSQL represents a value written into SQL directly but not as an argument
+type rawSQL string
+
+// OnConflict sets the conflict columns of the upsert
+func (statement *Statement) OnConflict(cols ...string) {
+	if statement.Upsert == nil {
+		statement.Upsert = &Upsert{}
+	}
+	statement.Upsert.ConflictCols = cols
+}
+
+// DoUpdate sets the columns to be updated when conflicting
+func (statement *Statement) DoUpdate(cols ...string) {
+	if statement.Upsert == nil {
+		statement.Upsert = &Upsert{}
+	}
+	statement.Upsert.UpdateCols = cols
+	statement.Upsert.DoNothing = false
+}
+
+// DoNothing keeps the conflicting rows without any changes
+func (statement *Statement) DoNothing() {
+	if statement.Upsert == nil {
+		statement.Upsert = &Upsert{}
+	}
+	statement.Upsert.DoNothing = true
+	statement.Upsert.UpdateCols = nil
+}
+
+// IsMergeUpsert returns true if the upsert is rendered as a MERGE statement
+func (statement *Statement) IsMergeUpsert() bool {
+	if statement.Upsert == nil {
+		return false
+	}
+	switch statement.dialect.URI().DBType {
+	case schemas.MSSQL, schemas.ORACLE, schemas.DAMENG:
+		return true
+	}
+	return false
+}
+
+func (statement *Statement) upsertConflictCols() []string {
+	if len(statement.Upsert.ConflictCols) > 0 {
+		return statement.Upsert.ConflictCols
+	}
+	if statement.RefTable != nil {
+		return statement.RefTable.PrimaryKeys
+	}
+	return nil
+}
+
+// upsertVersionCol returns the version column which should be increased when updating
+func (statement *Statement) upsertVersionCol(colNames []string) string {
+	table := statement.RefTable
+	if table == nil || table.Version == "" || !statement.CheckVersion || !containsCol(colNames, table.Version) {
+		return ""
+	}
+	return table.Version
+}
+
+// upsertUpdateCols returns the columns to be updated with the inserted values when conflicting
+func (statement *Statement) upsertUpdateCols(colNames []string) ([]string, error) {
+	if statement.Upsert.DoNothing {
+		return nil, nil
+	}
+
+	table := statement.RefTable
+	versionCol := statement.upsertVersionCol(colNames)
+	var updateCols []string
+	if len(statement.Upsert.UpdateCols) > 0 {
+		for _, col := range statement.Upsert.UpdateCols {
+			if !containsCol(colNames, col) {
+				return nil, fmt.Errorf("Upsert column %s is not inserted", col)
+			}
+			if !strings.EqualFold(col, versionCol) {
+				updateCols = append(updateCols, col)
+			}
+		}
+		// the updated columns are always updated with the new time
+		if table != nil && statement.UseAutoTime {
+			for _, col := range table.Columns() {
+				if col.IsUpdated && containsCol(colNames, col.Name) && !containsCol(updateCols, col.Name) {
+					updateCols = append(updateCols, col.Name)
+				}
+			}
+		}
+		return updateCols, nil
+	}
+
+	conflictCols := statement.upsertConflictCols()
+	for _, colName := range colNames {
+		if containsCol(conflictCols, colName) || strings.EqualFold(colName, versionCol) {
+			continue
+		}
+		if table != nil {
+			if col := table.GetColumn(colName); col != nil && (col.IsAutoIncrement || col.IsCreated) {
+				continue
+			}
+		}
+		updateCols = append(updateCols, colName)
+	}
+	return updateCols, nil
+}
+
+func containsCol(cols []string, col string) bool {
+	for _, c := range cols {
+		if strings.EqualFold(schemas.CommonQuoter.Trim(c), schemas.CommonQuoter.Trim(col)) {
+			return true
+		}
+	}
+	return false
+}
+
+// unqualifiedTableName returns the table name without the schema
+func unqualifiedTableName(tableName string) string {
+	if idx := strings.LastIndexByte(tableName, '.'); idx >= 0 {
+		return tableName[idx+1:]
+	}
+	return tableName
+}
+
+// writeUpsert writes the ON CONFLICT or ON DUPLICATE KEY UPDATE clause after the insert
+func (statement *Statement) writeUpsert(w *builder.BytesWriter, tableName string, colNames []string) error {
+	if statement.Upsert == nil {
+		return nil
+	}
+	if statement.dialect.URI().DBType == schemas.MYSQL {
+		return statement.writeMySQLUpsert(w, colNames)
+	}
+
+	quoter := statement.dialect.Quoter()
+	updateCols, err := statement.upsertUpdateCols(colNames)
+	if err != nil {
+		return err
+	}
+	versionCol := statement.upsertVersionCol(colNames)
+	if versionCol != "" && !statement.Upsert.DoNothing {
+		updateCols = append(updateCols, versionCol)
+	}
+	conflictCols := statement.upsertConflictCols()
+
+	if _, err := w.WriteString(" ON CONFLICT"); err != nil {
+		return err
+	}
+	if len(conflictCols) > 0 {
+		if _, err := fmt.Fprintf(w, " (%s)", quoter.Join(conflictCols, ",")); err != nil {
+			return err
+		}
+	} else if len(updateCols) > 0 {
+		return ErrUpsertNoConflictColumns
+	}
+	if len(updateCols) == 0 {
+		_, err := w.WriteString(" DO NOTHING")
+		return err
+	}
+
+	if _, err := w.WriteString(" DO UPDATE SET "); err != nil {
+		return err
+	}
+	table := quoter.Quote(unqualifiedTableName(tableName))
+	for i, col := range updateCols {
+		if i > 0 {
+			if _, err := w.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if strings.EqualFold(col, versionCol) {
+			_, err = fmt.Fprintf(w, "%s = %s.%s + 1", quoter.Quote(col), table, quoter.Quote(col))
+		} else {
+			_, err = fmt.Fprintf(w, "%s = excluded.%s", quoter.Quote(col), quoter.Quote(col))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (statement *Statement) writeMySQLUpsert(w *builder.BytesWriter, colNames []string) error {
+	quoter := statement.dialect.Quoter()
+	updateCols, err := statement.upsertUpdateCols(colNames)
+	if err != nil {
+		return err
+	}
+
+	var sets []string
+	for _, col := range updateCols {
+		sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", quoter.Quote(col), quoter.Quote(col)))
+	}
+	if len(sets) > 0 {
+		if versionCol := statement.upsertVersionCol(colNames); versionCol != "" {
+			sets = append(sets, fmt.Sprintf("%s = %s + 1", quoter.Quote(versionCol), quoter.Quote(versionCol)))
+		}
+	}
+
+	table := statement.RefTable
+	switch {
+	case table != nil && table.AutoIncrement != "":
+		// so that the id of the updated row will be returned as the last insert id
+		aiCol := quoter.Quote(table.AutoIncrement)
+		sets = append(sets, fmt.Sprintf("%s = LAST_INSERT_ID(%s)", aiCol, aiCol))
+	case len(sets) == 0:
+		// updating a column to itself changes nothing
+		var col string
+		if conflictCols := statement.upsertConflictCols(); len(conflictCols) > 0 {
+			col = conflictCols[0]
+		} else if len(colNames) > 0 {
+			col = colNames[0]
+		} else {
+			return ErrUpsertNoConflictColumns
+		}
+		sets = append(sets, fmt.Sprintf("%s = %s", quoter.Quote(col), quoter.Quote(col)))
+	}
+
+	_, err = fmt.Fprintf(w, " ON DUPLICATE KEY UPDATE %s", strings.Join(sets, ","))
+	return err
+}
+
+// writeUpsertValue writes a value of the MERGE source
+func (statement *Statement) writeUpsertValue(w *builder.BytesWriter, value interface{}) error {
+	if raw, ok := value.(rawSQL); ok {
+		_, err := w.WriteString(string(raw))
+		return err
+	}
+	return statement.WriteArg(w, value)
+}
+
+// exprValues converts the expressions to the values of MERGE source
+func exprValues(exprs exprParams) []interface{} {
+	values := make([]interface{}, 0, len(exprs))
+	for _, expr := range exprs {
+		if s, ok := expr.Arg.(string); ok {
+			if s == "" {
+				s = "''"
+			}
+			values = append(values, rawSQL(s))
+		} else {
+			values = append(values, expr.Arg)
+		}
+	}
+	return values
+}
+
+// genMergeUpsertSQL generates a MERGE statement inserts the rows of values, the auto increment
+// column will be inserted with the sequence for Oracle and Dameng if it's not in the columns
+func (statement *Statement) genMergeUpsertSQL(colNames []string, argss [][]interface{}) (string, []interface{}, error) {
+	tableName := statement.TableName()
+	exprs := statement.ExprColumns
+
+	var seqCol string
+	if table := statement.RefTable; table != nil && len(table.AutoIncrement) > 0 && !containsCol(colNames, table.AutoIncrement) &&
+		(statement.dialect.URI().DBType == schemas.ORACLE || statement.dialect.URI().DBType == schemas.DAMENG) {
+		seqCol = table.AutoIncrement
+	}
+
+	cols := append(append([]string{}, colNames...), exprs.ColNames()...)
+	w := builder.NewWriter()
+	if err := statement.writeMergeUpsert(w, tableName, cols, seqCol, len(argss), func(w *builder.BytesWriter, row, col int) error {
+		if col < len(colNames) {
+			return statement.WriteArg(w, argss[row][col])
+		}
+		return statement.writeUpsertValue(w, exprValues(exprs)[col-len(colNames)])
+	}); err != nil {
+		return "", nil, err
+	}
+	return w.String(), w.Args(), nil
+}
+
+// writeMultipleMergeUpsert writes a MERGE statement inserts the rows of placeholders
+func (statement *Statement) writeMultipleMergeUpsert(w *builder.BytesWriter, tableName string, colNames []string, colMultiPlaces []string) error {
+	seqPlace := utils.SeqName(tableName) + ".nextval"
+	var seqCol string
+	places := make([][]string, 0, len(colMultiPlaces))
+	for _, colPlaces := range colMultiPlaces {
+		places = append(places, strings.Split(colPlaces, ", "))
+	}
+
+	// the sequence cannot be used in the source of MERGE
+	cols := make([]string, 0, len(colNames))
+	indexes := make([]int, 0, len(colNames))
+	for i, col := range colNames {
+		if len(places) > 0 && places[0][i] == seqPlace {
+			seqCol = col
+			continue
+		}
+		cols = append(cols, col)
+		indexes = append(indexes, i)
+	}
+
+	return statement.writeMergeUpsert(w, tableName, cols, seqCol, len(places), func(w *builder.BytesWriter, row, col int) error {
+		_, err := w.WriteString(places[row][indexes[col]])
+		return err
+	})
+}
+
+// writeMergeUpsert writes a MERGE statement for the databases have no ON CONFLICT, writeValue
+// writes the value of the column of the row as the source. The seqCol is inserted with the
+// next value of the sequence if it's not empty.
+func (statement *Statement) writeMergeUpsert(w *builder.BytesWriter, tableName string, colNames []string, seqCol string, rows int,
+	writeValue func(w *builder.BytesWriter, row, col int) error,
+) error {
+	quoter := statement.dialect.Quoter()
+	dbType := statement.dialect.URI().DBType
+
+	if statement.IsReturningInto() {
+		// MERGE has no RETURNING clause on Oracle and Dameng
+		return ErrReturningNotSupported
+	}
+
+	conflictCols := statement.upsertConflictCols()
+	if len(conflictCols) == 0 {
+		return ErrUpsertNoConflictColumns
+	}
+	for _, col := range conflictCols {
+		if !containsCol(colNames, col) {
+			return fmt.Errorf("Upsert conflict column %s is not inserted", col)
+		}
+	}
+	updateCols, err := statement.upsertUpdateCols(colNames)
+	if err != nil {
+		return err
+	}
+
+	// the target is locked until the end of the transaction on MSSQL, otherwise the concurrent
+	// upserts may insert the same rows and fail with the violation of the unique key
+	var tableHint string
+	if dbType == schemas.MSSQL {
+		tableHint = " WITH (HOLDLOCK) AS"
+	}
+	if _, err := fmt.Fprintf(w, "MERGE INTO %s%s %s USING (", quoter.Quote(tableName), tableHint, mergeTargetAlias); err != nil {
+		return err
+	}
+	for row := 0; row < rows; row++ {
+		if row > 0 {
+			if _, err := w.WriteString(" UNION ALL "); err != nil {
+				return err
+			}
+		}
+		if _, err := w.WriteString("SELECT "); err != nil {
+			return err
+		}
+		for i, col := range colNames {
+			if i > 0 {
+				if _, err := w.WriteString(","); err != nil {
+					return err
+				}
+			}
+			if err := writeValue(w, row, i); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, " AS %s", quoter.Quote(col)); err != nil {
+				return err
+			}
+		}
+		if dbType == schemas.ORACLE || dbType == schemas.DAMENG {
+			if _, err := w.WriteString(" FROM DUAL"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, ") %s ON (", mergeSourceAlias); err != nil {
+		return err
+	}
+	for i, col := range conflictCols {
+		if i > 0 {
+			if _, err := w.WriteString(" AND "); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s.%s = %s.%s", mergeTargetAlias, quoter.Quote(col), mergeSourceAlias, quoter.Quote(col)); err != nil {
+			return err
+		}
+	}
+	if _, err := w.WriteString(")"); err != nil {
+		return err
+	}
+
+	if len(updateCols) > 0 {
+		if _, err := w.WriteString(" WHEN MATCHED THEN UPDATE SET "); err != nil {
+			return err
+		}
+		for i, col := range updateCols {
+			if i > 0 {
+				if _, err := w.WriteString(","); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintf(w, "%s.%s = %s.%s", mergeTargetAlias, quoter.Quote(col), mergeSourceAlias, quoter.Quote(col)); err != nil {
+				return err
+			}
+		}
+		if versionCol := statement.upsertVersionCol(colNames); versionCol != "" {
+			if _, err := fmt.Fprintf(w, ",%s.%s = %s.%s + 1", mergeTargetAlias, quoter.Quote(versionCol),
+				mergeTargetAlias, quoter.Quote(versionCol)); err != nil {
+				return err
+			}
+		}
+	}
+
+	insertCols := colNames
+	if seqCol != "" {
+		insertCols = append(append([]string{}, colNames...), seqCol)
+	}
+	if _, err := fmt.Fprintf(w, " WHEN NOT MATCHED THEN INSERT (%s) VALUES (", quoter.Join(insertCols, ",")); err != nil {
+		return err
+	}
+	for i, col := range colNames {
+		if i > 0 {
+			if _, err := w.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s.%s", mergeSourceAlias, quoter.Quote(col)); err != nil {
+			return err
+		}
+	}
+	if seqCol != "" {
+		if _, err := fmt.Fprintf(w, ",%s.nextval", utils.SeqName(tableName)); err != nil {
+			return err
+		}
+	}
+	if _, err := w.WriteString(")"); err != nil {
+		return err
+	}
+
+	if dbType == schemas.MSSQL {
+		if statement.IsReturning() {
+			if err := statement.writeReturningOutput(w.Builder, "INSERTED", statement.insertReturningColumns()); err != nil {
+				return err
+			}
+		} else if table := statement.RefTable; table != nil && len(table.AutoIncrement) > 0 {
+			if _, err := fmt.Fprintf(w, " OUTPUT Inserted.%s", quoter.Quote(table.AutoIncrement)); err != nil {
+				return err
+			}
+		}
+		// MERGE must be terminated by a semicolon
+		if _, err := w.WriteString(";"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+	"xorm.io/xorm/caches"
+	"xorm.io/xorm/dialects"
+	"xorm.io/xorm/names"
+	"xorm.io/xorm/schemas"
+	"xorm.io/xorm/tags"
+)
+
+type UpsertUser struct {
+	Id      int64
+	Name    string `xorm:"unique"`
+	Email   string
+	Created time.Time `xorm:"created"`
+	Updated time.Time `xorm:"updated"`
+	Version int       `xorm:"version"`
+}
+
+func newUpsertStatement(t *testing.T, dbType schemas.DBType) *Statement {
+	dialect := dialects.QueryDialect(dbType)
+	assert.NoError(t, dialect.Init(&dialects.URI{DBType: dbType}))
+	parser := tags.NewParser("xorm", dialect, names.SnakeMapper{}, names.SnakeMapper{}, caches.NewManager())
+	statement := NewStatement(dialect, parser, time.Local)
+	assert.NoError(t, statement.SetRefValue(reflect.ValueOf(UpsertUser{})))
+	return statement
+}
+
+func TestUpsertSQL(t *testing.T) {
+	colNames := []string{"name", "email", "created", "updated", "version"}
+	args := []interface{}{"a", "a@b.c", "t", "t", 1}
+
+	kases := []struct {
+		dbType   schemas.DBType
+		upsert   func(*Statement)
+		expected string
+	}{
+		{
+			schemas.POSTGRES,
+			func(s *Statement) { s.OnConflict("name") },
+			`INSERT INTO "upsert_user" ("name","email","created","updated","version") VALUES (?,?,?,?,?) ON CONFLICT ("name") DO UPDATE SET "email" = excluded."email","updated" = excluded."updated","version" = "upsert_user"."version" + 1 RETURNING "id"`,
+		},
+		{
+			schemas.POSTGRES,
+			func(s *Statement) { s.OnConflict("name"); s.DoNothing() },
+			`INSERT INTO "upsert_user" ("name","email","created","updated","version") VALUES (?,?,?,?,?) ON CONFLICT ("name") DO NOTHING RETURNING "id"`,
+		},
+		{
+			schemas.SQLITE,
+			func(s *Statement) { s.OnConflict("name"); s.DoUpdate("email") },
+			"INSERT INTO `upsert_user` (`name`,`email`,`created`,`updated`,`version`) VALUES (?,?,?,?,?) ON CONFLICT (`name`) DO UPDATE SET `email` = excluded.`email`,`updated` = excluded.`updated`,`version` = `upsert_user`.`version` + 1",
+		},
+		{
+			schemas.MYSQL,
+			func(s *Statement) { s.OnConflict("name") },
+			"INSERT INTO `upsert_user` (`name`,`email`,`created`,`updated`,`version`) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE `email` = VALUES(`email`),`updated` = VALUES(`updated`),`version` = `version` + 1,`id` = LAST_INSERT_ID(`id`)",
+		},
+		{
+			schemas.MYSQL,
+			func(s *Statement) { s.DoNothing() },
+			"INSERT INTO `upsert_user` (`name`,`email`,`created`,`updated`,`version`) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE `id` = LAST_INSERT_ID(`id`)",
+		},
+		{
+			schemas.MSSQL,
+			func(s *Statement) { s.OnConflict("name") },
+			"MERGE INTO [upsert_user] WITH (HOLDLOCK) AS xt USING (SELECT ? AS [name],? AS [email],? AS [created],? AS [updated],? AS [version]) xs ON (xt.[name] = xs.[name]) WHEN MATCHED THEN UPDATE SET xt.[email] = xs.[email],xt.[updated] = xs.[updated],xt.[version] = xt.[version] + 1 WHEN NOT MATCHED THEN INSERT ([name],[email],[created],[updated],[version]) VALUES (xs.[name],xs.[email],xs.[created],xs.[updated],xs.[version]) OUTPUT Inserted.[id];",
+		},
+		{
+			schemas.ORACLE,
+			func(s *Statement) { s.OnConflict("name"); s.DoNothing() },
+			`MERGE INTO "upsert_user" xt USING (SELECT ? AS "name",? AS "email",? AS "created",? AS "updated",? AS "version" FROM DUAL) xs ON (xt."name" = xs."name") WHEN NOT MATCHED THEN INSERT ("name","email","created","updated","version","id") VALUES (xs."name",xs."email",xs."created",xs."updated",xs."version",SEQ_UPSERT_USER.nextval)`,
+		},
+	}
+
+	for _, kase := range kases {
+		statement := newUpsertStatement(t, kase.dbType)
+		kase.upsert(statement)
+		sql, sqlArgs, err := statement.GenInsertSQL(append([]string{}, colNames...), args)
+		assert.NoError(t, err)
+		assert.EqualValues(t, kase.expected, sql)
+		assert.EqualValues(t, args, sqlArgs)
+	}
+
+	statement := newUpsertStatement(t, schemas.MSSQL)
+	statement.OnConflict("id")
+	_, _, err := statement.GenInsertSQL(append([]string{}, colNames...), args)
+	assert.Error(t, err)
+
+	statement = newUpsertStatement(t, schemas.POSTGRES)
+	statement.DoUpdate("id")
+	_, _, err = statement.GenInsertSQL(append([]string{}, colNames...), args)
+	assert.Error(t, err)
+}
+
+func TestUpsertMultipleSQL(t *testing.T) {
+	statement := newUpsertStatement(t, schemas.ORACLE)
+	statement.OnConflict("name")
+	statement.DoUpdate("email")
+
+	w := builder.NewWriter()
+	assert.NoError(t, statement.WriteInsertMultiple(w, "upsert_user", []string{"id", "name", "email"},
+		[]string{"SEQ_UPSERT_USER.nextval, ?, ?", "SEQ_UPSERT_USER.nextval, ?, ?"}))
+	assert.EqualValues(t, `MERGE INTO "upsert_user" xt USING (SELECT ? AS "name",? AS "email" FROM DUAL UNION ALL SELECT ? AS "name",? AS "email" FROM DUAL) xs ON (xt."name" = xs."name") WHEN MATCHED THEN UPDATE SET xt."email" = xs."email" WHEN NOT MATCHED THEN INSERT ("name","email","id") VALUES (xs."name",xs."email",SEQ_UPSERT_USER.nextval)`, w.String())
+
+	statement = newUpsertStatement(t, schemas.SQLITE)
+	statement.OnConflict("name")
+	w = builder.NewWriter()
+	assert.NoError(t, statement.WriteInsertMultiple(w, "upsert_user", []string{"name", "email"}, []string{"?, ?", "?, ?"}))
+	assert.EqualValues(t, "INSERT INTO `upsert_user` (`name`,`email`) VALUES (?, ?),(?, ?) ON CONFLICT (`name`) DO UPDATE SET `email` = excluded.`email`", w.String())
+}
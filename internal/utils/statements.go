@@ -0,0 +1,164 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+const delimiterDirective = "DELIMITER "
+
+// StatementSplitter splits a SQL script into statements by the semicolons which are not in
+// quotes, comments or dollar-quoted strings. A DELIMITER line changes the delimiter as the MySQL
+// client does, so that the statements have semicolons in the body, i.e. procedures and triggers,
+// could be split. The statements have only comments are returned as empty tokens.
+type StatementSplitter struct {
+	// BackslashEscapes means a backslash escapes the next character in strings, i.e. MySQL
+	BackslashEscapes bool
+	// DollarQuotes means $tag$ ... $tag$ quotes strings, i.e. Postgres
+	DollarQuotes bool
+	// BatchSeparator is a line ends a statement, i.e. GO of MSSQL. The script is split only by the
+	// separator lines if it's not empty, so a batch could have multiple statements and semicolons
+	// in the body of procedures, triggers and functions
+	BatchSeparator string
+
+	delimiter string
+}
+
+// Split implements bufio.SplitFunc
+func (s *StatementSplitter) Split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	delimiter := s.delimiter
+	if delimiter == "" {
+		delimiter = ";"
+	}
+
+	var hasContent bool
+	statement := func(end int) []byte {
+		if !hasContent {
+			return []byte{}
+		}
+		return bytes.TrimSpace(data[:end])
+	}
+
+	for i := 0; i < len(data); {
+		if i == 0 || data[i-1] == '\n' {
+			lineEnd := bytes.IndexByte(data[i:], '\n')
+			if lineEnd < 0 && !atEOF {
+				return 0, nil, nil
+			}
+			next := len(data)
+			if lineEnd >= 0 {
+				next = i + lineEnd + 1
+			}
+			line := strings.TrimSpace(string(data[i:next]))
+			if s.BatchSeparator == "" && !hasContent && len(line) > len(delimiterDirective) &&
+				strings.EqualFold(line[:len(delimiterDirective)], delimiterDirective) {
+				s.delimiter = strings.TrimSpace(line[len(delimiterDirective):])
+				return next, []byte{}, nil
+			}
+			if s.BatchSeparator != "" && strings.EqualFold(line, s.BatchSeparator) {
+				return next, statement(i), nil
+			}
+		}
+
+		switch c := data[i]; {
+		case s.BatchSeparator == "" && bytes.HasPrefix(data[i:], []byte(delimiter)):
+			return i + len(delimiter), statement(i), nil
+		case bytes.HasPrefix(data[i:], []byte("--")):
+			end := bytes.IndexByte(data[i:], '\n')
+			if end < 0 {
+				i = len(data)
+			} else {
+				i += end
+			}
+		case bytes.HasPrefix(data[i:], []byte("/*")):
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(data)
+			} else {
+				i += end + 4
+			}
+		case c == '\'' || c == '"' || c == '`':
+			hasContent = true
+			i = s.skipQuoted(data, i)
+		case c == '$' && s.DollarQuotes:
+			hasContent = true
+			i = skipDollarQuoted(data, i)
+		default:
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				hasContent = true
+			}
+			i++
+		}
+	}
+
+	// If we're at EOF, we have a final, non-terminated statement. Return it.
+	if atEOF {
+		return len(data), statement(len(data)), nil
+	}
+	// Request more data.
+	return 0, nil, nil
+}
+
+// skipQuoted returns the position after the quoted string starts at i
+func (s *StatementSplitter) skipQuoted(data []byte, i int) int {
+	quote := data[i]
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			if s.BackslashEscapes && quote != '`' {
+				j++
+			}
+		case quote:
+			return j + 1
+		}
+	}
+	return len(data)
+}
+
+// skipDollarQuoted returns the position after the dollar-quoted string starts at i,
+// or i+1 if it's not a dollar quote, i.e. a parameter like $1
+func skipDollarQuoted(data []byte, i int) int {
+	j := i + 1
+	for ; j < len(data); j++ {
+		c := data[j]
+		if c == '$' {
+			break
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > i+1 && c >= '0' && c <= '9') {
+			return i + 1
+		}
+	}
+	if j >= len(data) {
+		return len(data)
+	}
+
+	tag := data[i : j+1]
+	end := bytes.Index(data[j+1:], tag)
+	if end < 0 {
+		return len(data)
+	}
+	return j + 1 + end + len(tag)
+}
+
+// SplitStatements splits the SQL script into statements, the empty statements are ignored
+func (s *StatementSplitter) SplitStatements(script string) []string {
+	var statements []string
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	scanner.Buffer(nil, len(script)+1)
+	scanner.Split(s.Split)
+	for scanner.Scan() {
+		if stmt := scanner.Text(); stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	kases := []struct {
+		splitter StatementSplitter
+		script   string
+		expected []string
+	}{
+		{
+			script:   "CREATE TABLE a (id INT);\nINSERT INTO a VALUES (1);",
+			expected: []string{"CREATE TABLE a (id INT)", "INSERT INTO a VALUES (1)"},
+		},
+		{
+			script:   "INSERT INTO a VALUES ('a;b', 'it''s;', \"c;\");\nSELECT 1",
+			expected: []string{"INSERT INTO a VALUES ('a;b', 'it''s;', \"c;\")", "SELECT 1"},
+		},
+		{
+			script:   "-- comment;\n/* block; comment */\n;\nSELECT 1; -- the end;\n",
+			expected: []string{"SELECT 1"},
+		},
+		{
+			splitter: StatementSplitter{BackslashEscapes: true},
+			script:   "INSERT INTO a VALUES ('it\\'s;');SELECT `a;b` FROM a",
+			expected: []string{"INSERT INTO a VALUES ('it\\'s;')", "SELECT `a;b` FROM a"},
+		},
+		{
+			script:   "INSERT INTO a VALUES ('C:\\');SELECT 1",
+			expected: []string{"INSERT INTO a VALUES ('C:\\')", "SELECT 1"},
+		},
+		{
+			splitter: StatementSplitter{DollarQuotes: true},
+			script: "CREATE FUNCTION f() RETURNS INT AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql;\n" +
+				"SELECT $$a;b$$, $1;",
+			expected: []string{
+				"CREATE FUNCTION f() RETURNS INT AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql",
+				"SELECT $$a;b$$, $1",
+			},
+		},
+		{
+			script: "DELIMITER //\nCREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN SET NEW.id = 1; END//\n" +
+				"delimiter ;\nSELECT 1;",
+			expected: []string{
+				"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN SET NEW.id = 1; END",
+				"SELECT 1",
+			},
+		},
+		{
+			splitter: StatementSplitter{BatchSeparator: "GO"},
+			script:   "CREATE TABLE a (id INT)\nGO\nSELECT 'GO'\ngo\nSELECT 1",
+			expected: []string{"CREATE TABLE a (id INT)", "SELECT 'GO'", "SELECT 1"},
+		},
+		{
+			splitter: StatementSplitter{BatchSeparator: "GO"},
+			script: "CREATE PROCEDURE p AS\nBEGIN\n  SET NOCOUNT ON;\n  SELECT 1;\nEND;\nGO\n" +
+				"INSERT INTO a VALUES (1);\nINSERT INTO a VALUES (2);\nGO\n",
+			expected: []string{
+				"CREATE PROCEDURE p AS\nBEGIN\n  SET NOCOUNT ON;\n  SELECT 1;\nEND;",
+				"INSERT INTO a VALUES (1);\nINSERT INTO a VALUES (2);",
+			},
+		},
+	}
+
+	for _, kase := range kases {
+		t.Run(kase.script, func(t *testing.T) {
+			assert.EqualValues(t, kase.expected, kase.splitter.SplitStatements(kase.script))
+		})
+	}
+}
+
+func TestSplitStatementsStream(t *testing.T) {
+	// the statements across the buffers should be read completely
+	script := strings.Repeat("INSERT INTO a VALUES ('"+strings.Repeat("x;", 3000)+"');\n", 10)
+
+	var splitter StatementSplitter
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	scanner.Buffer(make([]byte, 1024), len(script))
+	scanner.Split(splitter.Split)
+	var count int
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			continue
+		}
+		assert.Len(t, scanner.Text(), len("INSERT INTO a VALUES ('')")+6000)
+		count++
+	}
+	assert.NoError(t, scanner.Err())
+	assert.EqualValues(t, 10, count)
+}
@@ -0,0 +1,257 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"xorm.io/xorm/dialects"
+	"xorm.io/xorm/schemas"
+)
+
+// lockTableName is the table keeps the named locks for the databases have no native named locks
+const lockTableName = "xorm_lock"
+
+// DefaultLockTTL is the default time to live of the named locks kept in the lock table
+const DefaultLockTTL = time.Minute
+
+// lockRetryInterval is the interval to retry acquiring a named lock held by others
+var lockRetryInterval = 100 * time.Millisecond
+
+// NamedLock represents a named lock across all the connections of the database
+type NamedLock struct {
+	engine *Engine
+	name   string
+	conn   *sql.Conn // the connection holds the lock, nil if the lock is kept in the lock table
+	owner  string    // the owner of the lock kept in the lock table
+
+	// stop and done stop the refreshing of the lock kept in the lock table
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Lock acquires the named lock, it waits until the lock is released by others or the context is done.
+// Postgres advisory locks, MySQL GET_LOCK and MSSQL sp_getapplock are used, which are released
+// automatically if the connection is closed. The other databases keep the lock in the xorm_lock table
+// with the time it's acquired, which is refreshed while the lock is held. If the process exits without
+// unlocking, the lock will be taken over by others after the TTL set by SetLockTTL, so the clocks of
+// the processes should be synchronized.
+func (engine *Engine) Lock(ctx context.Context, name string) (*NamedLock, error) {
+	if dialect, ok := engine.dialect.(dialects.LockDialect); ok {
+		return engine.lockByConn(ctx, dialect, name)
+	}
+	return engine.lockByTable(ctx, name)
+}
+
+func (engine *Engine) lockByConn(ctx context.Context, dialect dialects.LockDialect, name string) (*NamedLock, error) {
+	// the lock is held by the connection, so a connection should be kept until unlocking
+	conn, err := engine.db.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args := dialect.TryLockSQL(name)
+	for {
+		var acquired int
+		if err := conn.QueryRowContext(ctx, query, args...).Scan(&acquired); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		if acquired == 1 {
+			return &NamedLock{engine: engine, name: name, conn: conn}, nil
+		}
+		if err := waitLockRetry(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+}
+
+func (engine *Engine) lockByTable(ctx context.Context, name string) (*NamedLock, error) {
+	if err := engine.createLockTable(ctx); err != nil {
+		return nil, err
+	}
+
+	owner, err := newLockOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	tableName := engine.Quote(engine.TableName(lockTableName, true))
+	insertSQL := fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?)", tableName,
+		engine.Quote("name"), engine.Quote("owner"), engine.Quote("acquired"))
+	acquiredSQL := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", engine.Quote("acquired"), tableName, engine.Quote("name"))
+	deleteStaleSQL := fmt.Sprintf("DELETE FROM %s WHERE %s = ? AND %s = ?", tableName, engine.Quote("name"), engine.Quote("acquired"))
+	var retried bool
+	for {
+		_, err := engine.Context(ctx).Exec(insertSQL, name, owner, time.Now().UnixMilli())
+		if err == nil {
+			lock := &NamedLock{engine: engine, name: name, owner: owner}
+			if engine.lockTTL > 0 {
+				lock.stop = make(chan struct{})
+				lock.done = make(chan struct{})
+				go lock.refresh(engine.lockTTL / 3)
+			}
+			return lock, nil
+		}
+
+		// the insert fails since the name is the primary key if the lock is held by others,
+		// otherwise the error should be returned
+		var acquired int64
+		held, getErr := engine.Context(ctx).SQL(acquiredSQL, name).Get(&acquired)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if !held {
+			// the lock may be released just now, so retry once before returning the error
+			if retried {
+				return nil, err
+			}
+			retried = true
+			continue
+		}
+		retried = false
+
+		// the holder may exit without unlocking if the lock is not refreshed in the TTL, the lock is
+		// deleted only if it's not refreshed or taken over by others in the meantime
+		if engine.lockTTL > 0 && time.Since(time.UnixMilli(acquired)) > engine.lockTTL {
+			if _, err := engine.Context(ctx).Exec(deleteStaleSQL, name, acquired); err != nil {
+				return nil, err
+			}
+			engine.logger.Warnf("Lock %s acquired at %s is expired and will be taken over", name, time.UnixMilli(acquired))
+			continue
+		}
+		if err := waitLockRetry(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// newLockOwner returns an unique owner of a lock kept in the lock table
+func newLockOwner() (string, error) {
+	bs := make([]byte, 8)
+	if _, err := rand.Read(bs); err != nil {
+		return "", err
+	}
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(bs)), nil
+}
+
+// refresh updates the acquired time of the lock kept in the lock table periodically until unlocking
+func (lock *NamedLock) refresh(interval time.Duration) {
+	defer close(lock.done)
+
+	engine := lock.engine
+	refreshSQL := fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ? AND %s = ?", engine.Quote(engine.TableName(lockTableName, true)),
+		engine.Quote("acquired"), engine.Quote("name"), engine.Quote("owner"))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-lock.stop:
+			return
+		case <-ticker.C:
+			res, err := engine.Exec(refreshSQL, time.Now().UnixMilli(), lock.name, lock.owner)
+			if err != nil {
+				engine.logger.Errorf("Refresh lock %s failed: %v", lock.name, err)
+				continue
+			}
+			if cnt, err := res.RowsAffected(); err == nil && cnt == 0 {
+				engine.logger.Errorf("Lock %s has been taken over by others", lock.name)
+				return
+			}
+		}
+	}
+}
+
+func (engine *Engine) createLockTable(ctx context.Context) error {
+	session := engine.NewSession().Context(ctx)
+	defer session.Close()
+
+	exist, err := session.IsTableExist(lockTableName)
+	if err != nil || exist {
+		return err
+	}
+
+	nameCol := schemas.NewColumn("name", "", schemas.SQLType{Name: schemas.Varchar}, 255, 0, false)
+	nameCol.IsPrimaryKey = true
+	table := schemas.NewEmptyTable()
+	table.Name = lockTableName
+	table.AddColumn(nameCol)
+	table.AddColumn(schemas.NewColumn("owner", "", schemas.SQLType{Name: schemas.Varchar}, 255, 0, false))
+	table.AddColumn(schemas.NewColumn("acquired", "", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false))
+	table.PrimaryKeys = []string{nameCol.Name}
+
+	sqlStr, _, err := engine.dialect.CreateTableSQL(ctx, engine.db, table, engine.TableName(lockTableName, true))
+	if err != nil {
+		return err
+	}
+	if _, err := session.Exec(sqlStr); err != nil {
+		// the table may be created by others at the same time
+		if exist, _ := session.IsTableExist(lockTableName); !exist {
+			return err
+		}
+	}
+	return nil
+}
+
+func waitLockRetry(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrLockTimeout
+		}
+		return ctx.Err()
+	case <-time.After(lockRetryInterval):
+		return nil
+	}
+}
+
+// lockWithTimeout acquires the named lock in the timeout if it's not zero, and returns
+// the function to release the lock which logs the error
+func (engine *Engine) lockWithTimeout(ctx context.Context, name string, timeout time.Duration) (func(), error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	lock, err := engine.Lock(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := lock.Unlock(); err != nil {
+			engine.logger.Errorf("Unlock %s failed: %v", name, err)
+		}
+	}, nil
+}
+
+// Unlock releases the named lock
+func (lock *NamedLock) Unlock() error {
+	if lock.conn != nil {
+		defer lock.conn.Close()
+		query, args := lock.engine.dialect.(dialects.LockDialect).UnlockSQL(lock.name)
+		_, err := lock.conn.ExecContext(context.Background(), query, args...)
+		return err
+	}
+
+	if lock.stop != nil {
+		close(lock.stop)
+		<-lock.done
+		lock.stop = nil
+	}
+	// the lock may be taken over by others if it's expired, so only the lock of the owner is deleted
+	_, err := lock.engine.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ? AND %s = ?",
+		lock.engine.Quote(lock.engine.TableName(lockTableName, true)), lock.engine.Quote("name"),
+		lock.engine.Quote("owner")), lock.name, lock.owner)
+	return err
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"xorm.io/xorm"
+	"xorm.io/xorm/schemas"
+)
+
+const (
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// sqlMigration keeps the SQL scripts of a migration, the key is the database type
+// and the empty key is for all databases
+type sqlMigration struct {
+	id   string
+	up   map[schemas.DBType]string
+	down map[schemas.DBType]string
+}
+
+// LoadFS loads the migrations for the database type from the SQL files in the root directory of
+// the file system, the files are named like 20240101_add_users.up.sql and 20240101_add_users.down.sql,
+// and the name before the suffix is the ID of the migration. A file for a database, i.e.
+// 20240101_add_users.postgres.up.sql, is used instead of the common one on the database.
+// The migrations are sorted by the IDs and the down files are optional. The checksum of
+// a migration is computed from the up file used on the database, so the files for the other
+// databases could be changed without affecting it.
+func LoadFS(fsys fs.FS, dbType schemas.DBType) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	sqlMigrations := make(map[string]*sqlMigration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		var isUp bool
+		switch {
+		case strings.HasSuffix(name, upSuffix):
+			name, isUp = strings.TrimSuffix(name, upSuffix), true
+		case strings.HasSuffix(name, downSuffix):
+			name = strings.TrimSuffix(name, downSuffix)
+		default:
+			continue
+		}
+
+		var fileDBType schemas.DBType
+		if idx := strings.LastIndexByte(name, '.'); idx > 0 && isDBType(name[idx+1:]) {
+			name, fileDBType = name[:idx], schemas.DBType(name[idx+1:])
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := sqlMigrations[name]
+		if !ok {
+			mig = &sqlMigration{
+				id:   name,
+				up:   make(map[schemas.DBType]string),
+				down: make(map[schemas.DBType]string),
+			}
+			sqlMigrations[name] = mig
+		}
+		if isUp {
+			mig.up[fileDBType] = string(content)
+		} else {
+			mig.down[fileDBType] = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(sqlMigrations))
+	for _, mig := range sqlMigrations {
+		up, ok := mig.script(mig.up, dbType)
+		if !ok {
+			return nil, fmt.Errorf("migration %s has no %s file for %s", mig.id, upSuffix, dbType)
+		}
+		migration := &Migration{
+			ID:        mig.id,
+			MigrateTx: execScript(up),
+			Checksum:  checksum(up),
+		}
+		if down, ok := mig.script(mig.down, dbType); ok {
+			migration.RollbackTx = execScript(down)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].ID < migrations[j].ID
+	})
+	return migrations, nil
+}
+
+func isDBType(s string) bool {
+	switch schemas.DBType(s) {
+	case schemas.POSTGRES, schemas.SQLITE, schemas.MYSQL, schemas.MSSQL, schemas.ORACLE, schemas.DAMENG:
+		return true
+	}
+	return false
+}
+
+// script returns the script for the database type, or the common one if there is no such one
+func (mig *sqlMigration) script(scripts map[schemas.DBType]string, dbType schemas.DBType) (string, bool) {
+	if script, ok := scripts[dbType]; ok {
+		return script, true
+	}
+	script, ok := scripts[""]
+	return script, ok
+}
+
+// checksum returns the SHA-256 of the script
+func checksum(script string) string {
+	h := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(h[:])
+}
+
+// execScript returns the function executes the script in the session
+func execScript(script string) func(*xorm.Session) error {
+	return func(session *xorm.Session) error {
+		_, err := session.Import(strings.NewReader(script))
+		return err
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"xorm.io/xorm"
+)
+
+// GeneratedMigration represents a migration generated from the differences between
+// the structs and the database schemas
+type GeneratedMigration struct {
+	ID string
+	// DBType is the database the SQLs are generated for
+	DBType string
+	// Up are the SQLs to migrate the database to the structs
+	Up []string
+	// Down are the SQLs to undo Up in the reverse order
+	Down []string
+	// Irreversible are the actions of Up which cannot be undone automatically,
+	// Down should be completed manually if it's not empty
+	Irreversible []*xorm.SyncAction
+}
+
+// Generate compares the beans with the database by SyncWithOptions in dry run mode, and returns
+// the migration with the SQLs. Nothing will be changed on the database.
+func Generate(db *xorm.Engine, id string, opts xorm.SyncOptions, beans ...interface{}) (*GeneratedMigration, error) {
+	if len(id) == 0 {
+		return nil, ErrMissingID
+	}
+
+	opts.DryRun = true
+	result, err := db.SyncWithOptions(opts, beans...)
+	if err != nil {
+		return nil, err
+	}
+
+	down, irreversible := result.RevertSQLs()
+	return &GeneratedMigration{
+		ID:           id,
+		DBType:       string(db.Dialect().URI().DBType),
+		Up:           result.SQLs(),
+		Down:         down,
+		Irreversible: irreversible,
+	}, nil
+}
+
+// IsEmpty returns true if the structs have no differences with the database
+func (gen *GeneratedMigration) IsEmpty() bool {
+	return len(gen.Up) == 0
+}
+
+func irreversibleComment(action *xorm.SyncAction) string {
+	// the comment should be in one line
+	return fmt.Sprintf("irreversible %s on %s: %s", action.Type, action.Table, strings.Join(strings.Fields(action.SQL), " "))
+}
+
+// GoSource returns the formatted Go source declares a variable of *migrate.Migration
+// in the package, the irreversible actions are left as comments in RollbackTx.
+func (gen *GeneratedMigration) GoSource(packageName, varName string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by xorm migrate for %s. Please review it before using.\n\n", gen.DBType)
+	fmt.Fprintf(&buf, "package %s\n\n", packageName)
+	buf.WriteString("import (\n\"xorm.io/xorm\"\n\"xorm.io/xorm/migrate\"\n)\n\n")
+	fmt.Fprintf(&buf, "var %s = &migrate.Migration{\n", varName)
+	fmt.Fprintf(&buf, "ID: %s,\n", strconv.Quote(gen.ID))
+
+	writeFunc := func(field string, sqls []string, comments []string) {
+		fmt.Fprintf(&buf, "%s: func(tx *xorm.Session) error {\n", field)
+		for _, comment := range comments {
+			fmt.Fprintf(&buf, "// TODO: %s\n", comment)
+		}
+		if len(sqls) > 0 {
+			buf.WriteString("for _, sql := range []string{\n")
+			for _, sql := range sqls {
+				fmt.Fprintf(&buf, "%s,\n", strconv.Quote(sql))
+			}
+			buf.WriteString("} {\nif _, err := tx.Exec(sql); err != nil {\nreturn err\n}\n}\n")
+		}
+		buf.WriteString("return nil\n},\n")
+	}
+
+	writeFunc("MigrateTx", gen.Up, nil)
+	comments := make([]string, 0, len(gen.Irreversible))
+	for _, action := range gen.Irreversible {
+		comments = append(comments, irreversibleComment(action))
+	}
+	writeFunc("RollbackTx", gen.Down, comments)
+	buf.WriteString("}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+// SQLFiles returns the contents of the up and down SQL files, the irreversible
+// actions are left as comments in the down file.
+func (gen *GeneratedMigration) SQLFiles() (up, down []byte) {
+	var upBuf, downBuf bytes.Buffer
+	for _, sql := range gen.Up {
+		upBuf.WriteString(sql)
+		upBuf.WriteString(";\n")
+	}
+	for _, action := range gen.Irreversible {
+		fmt.Fprintf(&downBuf, "-- TODO: %s\n", irreversibleComment(action))
+	}
+	for _, sql := range gen.Down {
+		downBuf.WriteString(sql)
+		downBuf.WriteString(";\n")
+	}
+	return upBuf.Bytes(), downBuf.Bytes()
+}
+
+// WriteSQLFiles writes the up and down SQL files into the directory, the files are named
+// like ID.DBType.up.sql so that they could be loaded by LoadFS for the database.
+func (gen *GeneratedMigration) WriteSQLFiles(dir string) error {
+	up, down := gen.SQLFiles()
+	base := filepath.Join(dir, gen.ID+"."+gen.DBType)
+	if err := os.WriteFile(base+upSuffix, up, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(base+downSuffix, down, 0o644)
+}
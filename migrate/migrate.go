@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"time"
 
 	"xorm.io/xorm"
 	"xorm.io/xorm/schemas"
@@ -16,6 +18,14 @@ type MigrateFunc func(*xorm.Engine) error
 // RollbackFunc is the func signature for rollbacking.
 type RollbackFunc func(*xorm.Engine) error
 
+// MigrateTxFunc is the func signature for migrating with a session, the session is
+// in a transaction if Options.UseTransaction is true.
+type MigrateTxFunc func(*xorm.Session) error
+
+// RollbackTxFunc is the func signature for rollbacking with a session, the session is
+// in a transaction if Options.UseTransaction is true.
+type RollbackTxFunc func(*xorm.Session) error
+
 // InitSchemaFunc is the func signature for initializing the schemas.
 type InitSchemaFunc func(*xorm.Engine) error
 
@@ -25,6 +35,17 @@ type Options struct {
 	TableName string
 	// IDColumnName is the name of column where the migration id will be stored.
 	IDColumnName string
+	// LockName is the name of a lock held while migrating if it's not empty, so that the
+	// migrations of the replicas started at the same time will run one by one. It should not be
+	// the same as the lock name used by Sync in the migrations. See xorm.Engine.Lock
+	LockName string
+	// LockTimeout is the max duration to wait for the lock, zero means waiting forever.
+	LockTimeout time.Duration
+	// UseTransaction runs every migration in its own transaction, the changes of MigrateTx
+	// or RollbackTx and the record in the migration table are committed or rollbacked together.
+	// Migrate and Rollback which receive the engine are not in the transaction. Please notice
+	// some databases, i.e. MySQL, commit the DDL statements implicitly.
+	UseTransaction bool
 }
 
 // Migration represents a database migration (a modification to be made on the database).
@@ -35,6 +56,14 @@ type Migration struct {
 	Migrate MigrateFunc
 	// Rollback will be executed on rollback. Can be nil.
 	Rollback RollbackFunc
+	// MigrateTx is used instead of Migrate if it's not nil.
+	MigrateTx MigrateTxFunc
+	// RollbackTx is used instead of Rollback if it's not nil.
+	RollbackTx RollbackTxFunc
+	// Checksum is stored with the applied migration, the migration is considered as modified
+	// if it's different from the stored one. Empty means not checking. LoadFS sets it by the
+	// contents of the SQL files.
+	Checksum string
 }
 
 // Migrate represents a collection of all migrations of a database schemas.
@@ -65,6 +94,17 @@ var (
 	// ErrNoRunnedMigration is returned when any runned migration was found while
 	// running RollbackLast
 	ErrNoRunnedMigration = errors.New("Could not find last runned migration")
+
+	// ErrMigrationNotFound is returned when the migration of MigrateTo or RollbackTo is not defined
+	ErrMigrationNotFound = errors.New("Could not find the migration")
+
+	// ErrMigrationModified is returned when the checksum of an applied migration is changed
+	ErrMigrationModified = errors.New("Migration has been modified after applied")
+)
+
+const (
+	checksumColumnName  = "checksum"
+	appliedAtColumnName = "applied_at"
 )
 
 // New returns a new Gormigrate.
@@ -86,6 +126,56 @@ func (m *Migrate) InitSchema(initSchema InitSchemaFunc) {
 
 // Migrate executes all migrations that did not run yet.
 func (m *Migrate) Migrate() error {
+	return m.withLock(func() error {
+		return m.migrate("")
+	})
+}
+
+// MigrateTo executes the migrations that did not run yet up to the migration with the id,
+// the migrations after it are kept pending.
+func (m *Migrate) MigrateTo(migrationID string) error {
+	if err := m.checkMigrationID(migrationID); err != nil {
+		return err
+	}
+	return m.withLock(func() error {
+		return m.migrate(migrationID)
+	})
+}
+
+func (m *Migrate) checkMigrationID(migrationID string) error {
+	for _, migration := range m.migrations {
+		if migration.ID == migrationID {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrMigrationNotFound, migrationID)
+}
+
+// withLock runs the function with the lock held if the lock name is set
+func (m *Migrate) withLock(f func() error) error {
+	if m.options.LockName == "" {
+		return f()
+	}
+
+	ctx := context.Background()
+	if m.options.LockTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.options.LockTimeout)
+		defer cancel()
+	}
+	lock, err := m.db.Lock(ctx, m.options.LockName)
+	if err != nil {
+		return err
+	}
+	if err := f(); err != nil {
+		_ = lock.Unlock()
+		return err
+	}
+	return lock.Unlock()
+}
+
+// migrate executes the migrations up to the migration with the id, or all if it's empty
+func (m *Migrate) migrate(migrationID string) error {
 	if err := m.createMigrationTableIfNotExists(); err != nil {
 		return err
 	}
@@ -98,16 +188,65 @@ func (m *Migrate) Migrate() error {
 		return m.runInitSchema()
 	}
 
-	for _, migration := range m.migrations {
+	applied, err := m.getAppliedMigrations()
+	if err != nil {
+		return err
+	}
+	if err := m.checkChecksums(applied); err != nil {
+		return err
+	}
+
+	lastApplied := -1
+	for i, migration := range m.migrations {
+		if _, ok := applied[migration.ID]; ok {
+			lastApplied = i
+		}
+	}
+
+	for i, migration := range m.migrations {
+		if i < lastApplied {
+			if _, ok := applied[migration.ID]; !ok {
+				m.db.Logger().Warnf("Migration %s is out of order, it runs after the migration %s",
+					migration.ID, m.migrations[lastApplied].ID)
+			}
+		}
 		if err := m.runMigration(migration); err != nil {
 			return err
 		}
+		if migration.ID == migrationID {
+			break
+		}
+	}
+	return nil
+}
+
+// checkChecksums returns an error if an applied migration has been modified,
+// and stores the checksums of the applied migrations which have no checksums
+func (m *Migrate) checkChecksums(applied map[string]*appliedMigration) error {
+	tableName := m.db.TableName(m.options.TableName, true)
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.ID]
+		if !ok || migration.Checksum == "" {
+			continue
+		}
+		if record.checksum == "" {
+			if _, err := m.db.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", tableName,
+				checksumColumnName, m.options.IDColumnName), migration.Checksum, migration.ID); err != nil {
+				return err
+			}
+		} else if record.checksum != migration.Checksum {
+			return fmt.Errorf("%w: %s", ErrMigrationModified, migration.ID)
+		}
 	}
 	return nil
 }
 
 // RollbackLast undo the last migration
 func (m *Migrate) RollbackLast() error {
+	return m.withLock(m.rollbackLast)
+}
+
+func (m *Migrate) rollbackLast() error {
 	if len(m.migrations) == 0 {
 		return ErrNoMigrationDefined
 	}
@@ -120,6 +259,36 @@ func (m *Migrate) RollbackLast() error {
 	return m.RollbackMigration(lastRunnedMigration)
 }
 
+// RollbackTo undo the applied migrations after the migration with the id in the reverse order,
+// the migration with the id is not rollbacked.
+func (m *Migrate) RollbackTo(migrationID string) error {
+	if err := m.checkMigrationID(migrationID); err != nil {
+		return err
+	}
+	return m.withLock(func() error {
+		return m.rollbackTo(migrationID)
+	})
+}
+
+func (m *Migrate) rollbackTo(migrationID string) error {
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.ID == migrationID {
+			break
+		}
+		run, err := m.migrationDidRun(migration)
+		if err != nil {
+			return err
+		}
+		if run {
+			if err := m.RollbackMigration(migration); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (m *Migrate) getLastRunnedMigration() (*Migration, error) {
 	for i := len(m.migrations) - 1; i >= 0; i-- {
 		migration := m.migrations[i]
@@ -135,21 +304,49 @@ func (m *Migrate) getLastRunnedMigration() (*Migration, error) {
 
 // RollbackMigration undo a migration.
 func (m *Migrate) RollbackMigration(mig *Migration) error {
-	if mig.Rollback == nil {
+	if mig.Rollback == nil && mig.RollbackTx == nil {
 		return ErrRollbackImpossible
 	}
 
-	if err := mig.Rollback(m.db); err != nil {
-		return err
-	}
+	return m.runInSession(func(session *xorm.Session) error {
+		var err error
+		if mig.RollbackTx != nil {
+			err = mig.RollbackTx(session)
+		} else {
+			err = mig.Rollback(m.db)
+		}
+		if err != nil {
+			return err
+		}
 
-	tableName := m.db.TableName(m.options.TableName, true)
+		tableName := m.db.TableName(m.options.TableName, true)
+
+		sql := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", tableName, m.options.IDColumnName)
+		if _, err := session.Exec(sql, mig.ID); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// runInSession runs the function with a new session, the session is in a transaction
+// which will be committed if the function succeeds when Options.UseTransaction is true
+func (m *Migrate) runInSession(f func(*xorm.Session) error) error {
+	session := m.db.NewSession()
+	defer session.Close()
+
+	if !m.options.UseTransaction {
+		return f(session)
+	}
 
-	sql := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", tableName, m.options.IDColumnName)
-	if _, err := m.db.Exec(sql, mig.ID); err != nil {
+	if err := session.Begin(); err != nil {
 		return err
 	}
-	return nil
+	// the transaction will be rollbacked when closing the session if it's not committed
+	if err := f(session); err != nil {
+		return err
+	}
+	return session.Commit()
 }
 
 func (m *Migrate) runInitSchema() error {
@@ -158,7 +355,7 @@ func (m *Migrate) runInitSchema() error {
 	}
 
 	for _, migration := range m.migrations {
-		if err := m.insertMigration(migration.ID); err != nil {
+		if err := m.insertMigration(m.db, migration); err != nil {
 			return err
 		}
 	}
@@ -176,16 +373,23 @@ func (m *Migrate) runMigration(migration *Migration) error {
 		return err
 	}
 
-	if !run {
-		if err := migration.Migrate(m.db); err != nil {
-			return err
-		}
+	if run {
+		return nil
+	}
 
-		if err := m.insertMigration(migration.ID); err != nil {
+	return m.runInSession(func(session *xorm.Session) error {
+		var err error
+		if migration.MigrateTx != nil {
+			err = migration.MigrateTx(session)
+		} else {
+			err = migration.Migrate(m.db)
+		}
+		if err != nil {
 			return err
 		}
-	}
-	return nil
+
+		return m.insertMigration(session, migration)
+	})
 }
 
 func (m *Migrate) createMigrationTableIfNotExists() error {
@@ -194,7 +398,7 @@ func (m *Migrate) createMigrationTableIfNotExists() error {
 		return err
 	}
 	if exists {
-		return nil
+		return m.upgradeMigrationTable()
 	}
 
 	idCol := schemas.NewColumn(m.options.IDColumnName, "", schemas.SQLType{
@@ -204,6 +408,9 @@ func (m *Migrate) createMigrationTableIfNotExists() error {
 
 	table := schemas.NewTable(m.options.TableName, reflect.TypeOf(new(schemas.Table)))
 	table.AddColumn(idCol)
+	for _, col := range migrationTableColumns() {
+		table.AddColumn(col)
+	}
 
 	sql, _, err := m.db.Dialect().CreateTableSQL(context.Background(), m.db.DB(), table, m.options.TableName)
 	if err != nil {
@@ -216,6 +423,64 @@ func (m *Migrate) createMigrationTableIfNotExists() error {
 	return nil
 }
 
+// migrationTableColumns returns the columns of the migration table except the id column
+func migrationTableColumns() []*schemas.Column {
+	return []*schemas.Column{
+		schemas.NewColumn(checksumColumnName, "", schemas.SQLType{Name: schemas.Varchar}, 64, 0, true),
+		schemas.NewColumn(appliedAtColumnName, "", schemas.SQLType{Name: schemas.BigInt}, 0, 0, true),
+	}
+}
+
+// upgradeMigrationTable adds the columns to the migration table created by the old versions
+// which has only the id column
+func (m *Migrate) upgradeMigrationTable() error {
+	// the columns are queried in the schema of the dialect by the name without the schema
+	_, cols, err := m.db.Dialect().GetColumns(m.db.DB(), context.Background(), m.options.TableName)
+	if err != nil {
+		return err
+	}
+
+	tableName := m.db.TableName(m.options.TableName, true)
+
+	for _, col := range migrationTableColumns() {
+		if _, ok := cols[col.Name]; ok {
+			continue
+		}
+		if _, err := m.db.Exec(m.db.Dialect().AddColumnSQL(tableName, col)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appliedMigration represents a record of the migration table
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// getAppliedMigrations returns the records of the migration table by the ids
+func (m *Migrate) getAppliedMigrations() (map[string]*appliedMigration, error) {
+	tableName := m.db.TableName(m.options.TableName, true)
+	records, err := m.db.SQL(fmt.Sprintf("SELECT %s, %s, %s FROM %s", m.options.IDColumnName,
+		checksumColumnName, appliedAtColumnName, tableName)).QueryString()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]*appliedMigration, len(records))
+	for _, record := range records {
+		migration := &appliedMigration{
+			checksum: record[checksumColumnName],
+		}
+		if appliedAt, _ := strconv.ParseInt(record[appliedAtColumnName], 10, 64); appliedAt > 0 {
+			migration.appliedAt = time.Unix(appliedAt, 0)
+		}
+		applied[record[m.options.IDColumnName]] = migration
+	}
+	return applied, nil
+}
+
 func (m *Migrate) migrationDidRun(mig *Migration) (bool, error) {
 	tableName := m.db.TableName(m.options.TableName, true)
 	count, err := m.db.SQL(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = ?", tableName, m.options.IDColumnName), mig.ID).Count()
@@ -229,9 +494,10 @@ func (m *Migrate) isFirstRun() (bool, error) {
 	return count == 0, err
 }
 
-func (m *Migrate) insertMigration(id string) error {
+func (m *Migrate) insertMigration(db xorm.Interface, migration *Migration) error {
 	tableName := m.db.TableName(m.options.TableName, true)
-	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES (?)", tableName, m.options.IDColumnName)
-	_, err := m.db.Exec(sql, id)
+	sql := fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?)", tableName,
+		m.options.IDColumnName, checksumColumnName, appliedAtColumnName)
+	_, err := db.Exec(sql, migration.ID, migration.Checksum, time.Now().Unix())
 	return err
 }
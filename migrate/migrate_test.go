@@ -1,10 +1,14 @@
 package migrate
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
@@ -138,7 +142,336 @@ func TestMissingID(t *testing.T) {
 	assert.Equal(t, ErrMissingID, m.Migrate())
 }
 
+func TestMigrationWithLock(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	if db != nil {
+		defer db.Close()
+	}
+	assert.NoError(t, db.Ping())
+
+	options := &Options{
+		TableName:    "migrations",
+		IDColumnName: "id",
+		LockName:     "migrate",
+		LockTimeout:  time.Second,
+	}
+	m := New(db, options, migrations)
+	assert.NoError(t, m.Migrate())
+	assert.Equal(t, 2, tableCount(db, "migrations"))
+	assert.Equal(t, 0, tableCount(db, "xorm_lock"))
+
+	lock, err := db.Lock(context.Background(), "migrate")
+	assert.NoError(t, err)
+	options.LockTimeout = 200 * time.Millisecond
+	assert.ErrorIs(t, m.RollbackLast(), xorm.ErrLockTimeout)
+	assert.Equal(t, 2, tableCount(db, "migrations"))
+
+	assert.NoError(t, lock.Unlock())
+	assert.NoError(t, m.RollbackLast())
+	assert.Equal(t, 1, tableCount(db, "migrations"))
+}
+
+func TestMigrationWithTransaction(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	if db != nil {
+		defer db.Close()
+	}
+	assert.NoError(t, db.Ping())
+
+	options := &Options{
+		TableName:      "migrations",
+		IDColumnName:   "id",
+		UseTransaction: true,
+	}
+	txMigrations := []*Migration{
+		{
+			ID: "201608301400",
+			MigrateTx: func(tx *xorm.Session) error {
+				return tx.Sync(&Person{})
+			},
+			RollbackTx: func(tx *xorm.Session) error {
+				return tx.DropTable(&Person{})
+			},
+		},
+		{
+			ID: "201608301430",
+			MigrateTx: func(tx *xorm.Session) error {
+				if _, err := tx.Insert(&Person{Name: "xlw"}); err != nil {
+					return err
+				}
+				return errors.New("failed")
+			},
+		},
+	}
+	m := New(db, options, txMigrations)
+	assert.EqualError(t, m.Migrate(), "failed")
+	assert.Equal(t, 1, tableCount(db, "migrations"))
+	assert.Equal(t, 0, tableCount(db, "person"))
+
+	txMigrations[1].MigrateTx = func(tx *xorm.Session) error {
+		_, err := tx.Insert(&Person{Name: "xlw"})
+		return err
+	}
+	assert.NoError(t, m.Migrate())
+	assert.Equal(t, 2, tableCount(db, "migrations"))
+	assert.Equal(t, 1, tableCount(db, "person"))
+
+	assert.NoError(t, m.RollbackMigration(txMigrations[0]))
+	assert.Equal(t, 1, tableCount(db, "migrations"))
+	exist, err := db.IsTableExist(&Person{})
+	assert.NoError(t, err)
+	assert.False(t, exist)
+}
+
+func TestLoadFS(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	if db != nil {
+		defer db.Close()
+	}
+	assert.NoError(t, db.Ping())
+
+	fsys := fstest.MapFS{
+		"201608301400_add_person.up.sql": &fstest.MapFile{
+			Data: []byte("CREATE TABLE person (id INTEGER PRIMARY KEY, name TEXT);\n-- the first person;\nINSERT INTO person (name) VALUES ('a;b');"),
+		},
+		"201608301400_add_person.down.sql": &fstest.MapFile{Data: []byte("DROP TABLE person;")},
+		"201608301430_add_pet.up.sql":      &fstest.MapFile{Data: []byte("CREATE TABLE pet (id INT);")},
+		"201608301430_add_pet.sqlite3.up.sql": &fstest.MapFile{
+			Data: []byte("CREATE TABLE pet (id INTEGER PRIMARY KEY, name TEXT, person_id INTEGER);"),
+		},
+		"README.md": &fstest.MapFile{Data: []byte("migrations")},
+	}
+	migrations, err := LoadFS(fsys, db.Dialect().URI().DBType)
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.EqualValues(t, "201608301400_add_person", migrations[0].ID)
+	assert.EqualValues(t, "201608301430_add_pet", migrations[1].ID)
+	assert.Nil(t, migrations[1].RollbackTx)
+
+	m := New(db, &Options{
+		TableName:      "migrations",
+		IDColumnName:   "id",
+		UseTransaction: true,
+	}, migrations)
+	assert.NoError(t, m.Migrate())
+	assert.Equal(t, 2, tableCount(db, "migrations"))
+	assert.Equal(t, 1, tableCount(db, "person"))
+
+	tables, err := db.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if table.Name == "pet" {
+			assert.NotNil(t, table.GetColumn("person_id"))
+		}
+	}
+
+	assert.Equal(t, ErrRollbackImpossible, m.RollbackLast())
+	assert.NoError(t, m.RollbackMigration(migrations[0]))
+	exist, err := db.IsTableExist("person")
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	// only the file used on the database is checked
+	fsys["201608301430_add_pet.postgres.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE pet (id SERIAL PRIMARY KEY);")}
+	fsys["201608301430_add_pet.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE pet (id BIGINT);")}
+	changed, err := LoadFS(fsys, db.Dialect().URI().DBType)
+	assert.NoError(t, err)
+	assert.EqualValues(t, migrations[1].Checksum, changed[1].Checksum)
+
+	fsys["201608301430_add_pet.sqlite3.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE pet (id INTEGER PRIMARY KEY);")}
+	changed, err = LoadFS(fsys, db.Dialect().URI().DBType)
+	assert.NoError(t, err)
+	assert.NotEqualValues(t, migrations[1].Checksum, changed[1].Checksum)
+
+	_, err = LoadFS(fstest.MapFS{
+		"201608301400_add_person.down.sql": &fstest.MapFile{Data: []byte("DROP TABLE person;")},
+	}, db.Dialect().URI().DBType)
+	assert.Error(t, err)
+	_, err = LoadFS(fstest.MapFS{
+		"201608301400_add_person.postgres.up.sql": &fstest.MapFile{Data: []byte("CREATE TABLE person (id SERIAL);")},
+	}, db.Dialect().URI().DBType)
+	assert.Error(t, err)
+}
+
+func TestMigrateToAndRollbackTo(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	if db != nil {
+		defer db.Close()
+	}
+	assert.NoError(t, db.Ping())
+
+	m := New(db, DefaultOptions, migrations)
+	assert.ErrorIs(t, m.MigrateTo("not_exist"), ErrMigrationNotFound)
+
+	assert.NoError(t, m.MigrateTo("201608301400"))
+	assert.Equal(t, 1, tableCount(db, "migrations"))
+	assert.True(t, hasTable(db, &Person{}))
+	assert.False(t, hasTable(db, &Pet{}))
+
+	assert.NoError(t, m.Migrate())
+	assert.Equal(t, 2, tableCount(db, "migrations"))
+
+	assert.ErrorIs(t, m.RollbackTo("not_exist"), ErrMigrationNotFound)
+	assert.NoError(t, m.RollbackTo("201608301400"))
+	assert.Equal(t, 1, tableCount(db, "migrations"))
+	assert.True(t, hasTable(db, &Person{}))
+	assert.False(t, hasTable(db, &Pet{}))
+}
+
+func TestStatus(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	if db != nil {
+		defer db.Close()
+	}
+	assert.NoError(t, db.Ping())
+
+	// the migration table created by the old versions
+	_, err = db.Exec("CREATE TABLE migrations (id VARCHAR(255) PRIMARY KEY NOT NULL)")
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO migrations (id) VALUES ('201608301400'), ('201608301200')")
+	assert.NoError(t, err)
+	assert.NoError(t, db.Sync(&Person{}))
+
+	checksumMigrations := []*Migration{
+		{
+			ID:       "201608301300",
+			Checksum: "a",
+			Migrate: func(tx *xorm.Engine) error {
+				return nil
+			},
+		},
+		migrations[0],
+		{
+			ID:       "201608301430",
+			Checksum: "b",
+			Migrate: func(tx *xorm.Engine) error {
+				return tx.Sync(&Pet{})
+			},
+		},
+	}
+	m := New(db, DefaultOptions, checksumMigrations)
+	statuses, err := m.Status()
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 4)
+	assert.EqualValues(t, MigrationStatus{ID: "201608301300", State: StatePending, OutOfOrder: true}, *statuses[0])
+	assert.EqualValues(t, MigrationStatus{ID: "201608301400", State: StateApplied}, *statuses[1])
+	assert.EqualValues(t, MigrationStatus{ID: "201608301430", State: StatePending}, *statuses[2])
+	assert.EqualValues(t, MigrationStatus{ID: "201608301200", State: StateUnknown}, *statuses[3])
+
+	assert.NoError(t, m.Migrate())
+	statuses, err = m.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses[:3] {
+		assert.EqualValues(t, StateApplied, status.State)
+		assert.False(t, status.OutOfOrder)
+		assert.False(t, status.Modified)
+	}
+	assert.False(t, statuses[0].AppliedAt.IsZero())
+	assert.True(t, statuses[1].AppliedAt.IsZero())
+
+	checksumMigrations[2].Checksum = "c"
+	statuses, err = m.Status()
+	assert.NoError(t, err)
+	assert.True(t, statuses[2].Modified)
+	assert.ErrorIs(t, m.Migrate(), ErrMigrationModified)
+}
+
+func TestMigrateWithSchema(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	if db != nil {
+		defer db.Close()
+	}
+	assert.NoError(t, db.Ping())
+	// SetSchema only works for Postgres, and the main schema of SQLite is used here
+	db.Dialect().URI().Schema = "main"
+
+	// the migration table created by the old versions
+	_, err = db.Exec("CREATE TABLE migrations (id VARCHAR(255) PRIMARY KEY NOT NULL)")
+	assert.NoError(t, err)
+
+	m := New(db, DefaultOptions, migrations)
+	assert.NoError(t, m.Migrate())
+	// the upgraded migration table will not be upgraded again
+	assert.NoError(t, m.Migrate())
+	statuses, err := m.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.EqualValues(t, StateApplied, status.State)
+		assert.False(t, status.AppliedAt.IsZero())
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	os.Remove(dbName)
+
+	db, err := xorm.NewEngine("sqlite3", dbName)
+	assert.NoError(t, err)
+	if db != nil {
+		defer db.Close()
+	}
+	assert.NoError(t, db.Ping())
+	assert.NoError(t, db.Sync(&Person{}))
+
+	_, err = Generate(db, "", xorm.SyncOptions{}, &Person{}, &Pet{})
+	assert.Equal(t, ErrMissingID, err)
+
+	gen, err := Generate(db, "201608301430_add_pet", xorm.SyncOptions{}, &Person{}, &Pet{})
+	assert.NoError(t, err)
+	assert.False(t, gen.IsEmpty())
+	assert.Len(t, gen.Up, 1)
+	assert.Len(t, gen.Down, 1)
+	assert.Empty(t, gen.Irreversible)
+	assert.False(t, hasTable(db, &Pet{}))
+
+	src, err := gen.GoSource("migrations", "addPet")
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), "package migrations")
+	assert.Contains(t, string(src), "var addPet = &migrate.Migration{")
+	assert.Contains(t, string(src), `ID: "201608301430_add_pet",`)
+	assert.Contains(t, string(src), "DROP TABLE IF EXISTS `pet`")
+
+	dir := t.TempDir()
+	assert.NoError(t, gen.WriteSQLFiles(dir))
+	migrations, err := LoadFS(os.DirFS(dir), db.Dialect().URI().DBType)
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 1)
+
+	m := New(db, DefaultOptions, migrations)
+	assert.NoError(t, m.Migrate())
+	assert.True(t, hasTable(db, &Pet{}))
+
+	gen, err = Generate(db, "201608301500", xorm.SyncOptions{}, &Person{}, &Pet{})
+	assert.NoError(t, err)
+	assert.True(t, gen.IsEmpty())
+
+	assert.NoError(t, m.RollbackLast())
+	assert.False(t, hasTable(db, &Pet{}))
+}
+
 func tableCount(db *xorm.Engine, tableName string) (count int) {
 	_, _ = db.SQL(fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)).Get(&count)
 	return
 }
+
+func hasTable(db *xorm.Engine, bean interface{}) bool {
+	exist, _ := db.IsTableExist(bean)
+	return exist
+}
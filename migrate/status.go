@@ -0,0 +1,104 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"sort"
+	"time"
+)
+
+// MigrationState represents whether a migration has been applied
+type MigrationState int
+
+// enumerates all the migration states
+const (
+	// StatePending means the migration is defined but not applied
+	StatePending MigrationState = iota
+	// StateApplied means the migration is defined and applied
+	StateApplied
+	// StateUnknown means the migration is applied but not defined
+	StateUnknown
+)
+
+// String implements fmt.Stringer
+func (state MigrationState) String() string {
+	switch state {
+	case StatePending:
+		return "pending"
+	case StateApplied:
+		return "applied"
+	case StateUnknown:
+		return "unknown"
+	}
+	return ""
+}
+
+// MigrationStatus represents the status of a migration
+type MigrationStatus struct {
+	ID    string
+	State MigrationState
+	// AppliedAt is the time when the migration was applied, it's zero if the migration is
+	// pending or it was applied by the old versions which didn't record the time.
+	AppliedAt time.Time
+	// Modified means the checksum of the applied migration is different from the defined one
+	Modified bool
+	// OutOfOrder means the migration is pending but some later migrations have been applied
+	OutOfOrder bool
+}
+
+// Status returns the status of the defined migrations in order, followed by the unknown
+// migrations which have been applied but are not defined anymore.
+func (m *Migrate) Status() ([]*MigrationStatus, error) {
+	exists, err := m.db.IsTableExist(m.options.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]*appliedMigration)
+	if exists {
+		if err := m.upgradeMigrationTable(); err != nil {
+			return nil, err
+		}
+		if applied, err = m.getAppliedMigrations(); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(m.migrations))
+	defined := make(map[string]bool, len(m.migrations))
+	lastApplied := -1
+	for i, migration := range m.migrations {
+		defined[migration.ID] = true
+		status := &MigrationStatus{
+			ID:    migration.ID,
+			State: StatePending,
+		}
+		if record, ok := applied[migration.ID]; ok {
+			status.State = StateApplied
+			status.AppliedAt = record.appliedAt
+			status.Modified = record.checksum != "" && migration.Checksum != "" && record.checksum != migration.Checksum
+			lastApplied = i
+		}
+		statuses = append(statuses, status)
+	}
+	for i := 0; i < lastApplied; i++ {
+		statuses[i].OutOfOrder = statuses[i].State == StatePending
+	}
+
+	var unknowns []*MigrationStatus
+	for id, record := range applied {
+		if !defined[id] {
+			unknowns = append(unknowns, &MigrationStatus{
+				ID:        id,
+				State:     StateUnknown,
+				AppliedAt: record.appliedAt,
+			})
+		}
+	}
+	sort.Slice(unknowns, func(i, j int) bool {
+		return unknowns[i].ID < unknowns[j].ID
+	})
+	return append(statuses, unknowns...), nil
+}
@@ -18,11 +18,18 @@ type TableComment interface {
 	TableComment() string
 }
 
+// TableRenamedFrom is an interface to define the old name of a renamed table
+type TableRenamedFrom interface {
+	TableRenamedFrom() string
+}
+
 var (
-	tpTableName    = reflect.TypeOf((*TableName)(nil)).Elem()
-	tpTableComment = reflect.TypeOf((*TableComment)(nil)).Elem()
-	tvCache        sync.Map
-	tcCache        sync.Map
+	tpTableName        = reflect.TypeOf((*TableName)(nil)).Elem()
+	tpTableComment     = reflect.TypeOf((*TableComment)(nil)).Elem()
+	tpTableRenamedFrom = reflect.TypeOf((*TableRenamedFrom)(nil)).Elem()
+	tvCache            sync.Map
+	tcCache            sync.Map
+	trCache            sync.Map
 )
 
 // GetTableName returns table name
@@ -98,3 +105,40 @@ func GetTableComment(v reflect.Value) string {
 
 	return ""
 }
+
+// GetTableRenamedFrom returns the old name of the table
+func GetTableRenamedFrom(v reflect.Value) string {
+	if v.Type().Implements(tpTableRenamedFrom) {
+		return v.Interface().(TableRenamedFrom).TableRenamedFrom()
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+		if v.Type().Implements(tpTableRenamedFrom) {
+			return v.Interface().(TableRenamedFrom).TableRenamedFrom()
+		}
+	} else if v.CanAddr() {
+		v1 := v.Addr()
+		if v1.Type().Implements(tpTableRenamedFrom) {
+			return v1.Interface().(TableRenamedFrom).TableRenamedFrom()
+		}
+	} else {
+		oldName, ok := trCache.Load(v.Type())
+		if ok {
+			if oldName.(string) != "" {
+				return oldName.(string)
+			}
+		} else {
+			v2 := reflect.New(v.Type())
+			if v2.Type().Implements(tpTableRenamedFrom) {
+				tableOldName := v2.Interface().(TableRenamedFrom).TableRenamedFrom()
+				trCache.Store(v.Type(), tableOldName)
+				return tableOldName
+			}
+
+			trCache.Store(v.Type(), "")
+		}
+	}
+
+	return ""
+}
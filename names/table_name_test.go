@@ -138,3 +138,17 @@ func TestMyTable(t *testing.T) {
 		assert.EqualValues(t, fmt.Sprintf("mytable_%d", i), GetTableName(SameMapper{}, reflect.ValueOf(&table)))
 	}
 }
+
+type MyRenamedTable struct {
+	Id int64
+}
+
+func (MyRenamedTable) TableRenamedFrom() string {
+	return "my_old_table"
+}
+
+func TestGetTableRenamedFrom(t *testing.T) {
+	assert.EqualValues(t, "my_old_table", GetTableRenamedFrom(reflect.ValueOf(new(MyRenamedTable))))
+	assert.EqualValues(t, "my_old_table", GetTableRenamedFrom(reflect.ValueOf(MyRenamedTable{})))
+	assert.EqualValues(t, "", GetTableRenamedFrom(reflect.ValueOf(new(MyTable))))
+}
@@ -49,7 +49,10 @@ func newRows(session *Session, bean interface{}) (*Rows, error) {
 		addedTableName := session.statement.NeedTableName()
 		table := rows.session.statement.RefTable
 
-		if !session.statement.NoAutoCondition {
+		if session.statement.IsCompound() {
+			// the conditions of the bean have been applied by the queries of the compound query
+			autoCond = builder.NewCond()
+		} else if !session.statement.NoAutoCondition {
 			var err error
 			autoCond, err = session.statement.BuildConds(table, bean, true, true, false, true, addedTableName)
 			if err != nil {
@@ -0,0 +1,43 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Check represents a CHECK constraint of a table
+type Check struct {
+	// IsRegular is true if the name should be prefixed by XName, the names read from database are not
+	IsRegular bool
+	Name      string
+	Expr      string
+}
+
+// NewCheck creates a CHECK constraint with the expression
+func NewCheck(name, expr string) *Check {
+	return &Check{
+		IsRegular: true,
+		Name:      name,
+		Expr:      expr,
+	}
+}
+
+// XName returns the constraint name of the check for the table
+func (check *Check) XName(tableName string) string {
+	if !check.IsRegular || strings.HasPrefix(check.Name, "CHK_") {
+		return check.Name
+	}
+	tableParts := strings.Split(strings.ReplaceAll(tableName, `"`, ""), ".")
+	return fmt.Sprintf("CHK_%v_%v", tableParts[len(tableParts)-1], check.Name)
+}
+
+// IsXName returns true if the name of the check is generated by XName for the table, the
+// checks read from database which are not named by xorm should not be dropped by xorm
+func (check *Check) IsXName(tableName string) bool {
+	prefix := NewCheck("", "").XName(tableName)
+	return len(check.Name) > len(prefix) && strings.EqualFold(check.Name[:len(prefix)], prefix)
+}
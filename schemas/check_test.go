@@ -0,0 +1,22 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckXName(t *testing.T) {
+	assert.EqualValues(t, "CHK_order_price", NewCheck("price", "price > 0").XName("public.order"))
+	assert.True(t, NewCheck("CHK_order_price", "price > 0").IsXName("order"))
+	assert.False(t, NewCheck("price_positive", "price > 0").IsXName("order"))
+
+	// the names read from database are kept
+	check := NewCheck("price_positive", "price > 0")
+	check.IsRegular = false
+	assert.EqualValues(t, "price_positive", check.XName("order"))
+}
@@ -46,6 +46,9 @@ type Column struct {
 	TimeZone        *time.Location // column specified time zone
 	Comment         string
 	Collation       string
+	RenamedFrom     string // the old column name, Sync will rename it to Name
+	Generated       string // the expression of a generated column, the column is read only if not empty
+	GeneratedStored bool   // the generated column is stored but not computed when read
 }
 
 // NewColumn creates a new column
@@ -98,6 +101,11 @@ func (col *Column) ValueOfV(dataStruct *reflect.Value) (*reflect.Value, error) {
 	return &v, nil
 }
 
+// IsGenerated returns true if the column is generated by the database
+func (col *Column) IsGenerated() bool {
+	return col.Generated != ""
+}
+
 // ConvertID converts id content to suitable type according column type
 func (col *Column) ConvertID(sid string) (interface{}, error) {
 	if col.SQLType.IsNumeric() {
@@ -0,0 +1,148 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// enumerate all the referential actions of foreign keys
+const (
+	NoAction   = "NO ACTION"
+	Restrict   = "RESTRICT"
+	Cascade    = "CASCADE"
+	SetNull    = "SET NULL"
+	SetDefault = "SET DEFAULT"
+)
+
+// ForeignKey represents a foreign key constraint of a table
+type ForeignKey struct {
+	// IsRegular is true if the name should be prefixed by XName, the names read from database are not
+	IsRegular bool
+	Name      string
+	Cols      []string
+	RefTable  string
+	RefCols   []string
+	OnDelete  string
+	OnUpdate  string
+}
+
+// NewForeignKey creates a foreign key references the columns of the table
+func NewForeignKey(name, refTable string) *ForeignKey {
+	return &ForeignKey{
+		IsRegular: true,
+		Name:      name,
+		RefTable:  refTable,
+	}
+}
+
+// XName returns the constraint name of the foreign key for the table
+func (fk *ForeignKey) XName(tableName string) string {
+	if !fk.IsRegular || strings.HasPrefix(fk.Name, "FK_") {
+		return fk.Name
+	}
+	tableParts := strings.Split(strings.ReplaceAll(tableName, `"`, ""), ".")
+	return fmt.Sprintf("FK_%v_%v", tableParts[len(tableParts)-1], fk.Name)
+}
+
+// IsXName returns true if the name of the foreign key is generated by XName for the table, the
+// foreign keys read from database which are not named by xorm should not be dropped by xorm
+func (fk *ForeignKey) IsXName(tableName string) bool {
+	prefix := NewForeignKey("", "").XName(tableName)
+	return len(fk.Name) > len(prefix) && strings.EqualFold(fk.Name[:len(prefix)], prefix)
+}
+
+// AddColumn adds a column and the referenced column to the foreign key
+func (fk *ForeignKey) AddColumn(col, refCol string) {
+	fk.Cols = append(fk.Cols, col)
+	fk.RefCols = append(fk.RefCols, refCol)
+}
+
+// NormalizeAction returns the upper case referential action, the blank, "RESTRICT"
+// and "NO ACTION" are all the default action and will be returned as "NO ACTION"
+func NormalizeAction(action string) string {
+	action = strings.ToUpper(strings.TrimSpace(strings.ReplaceAll(action, "_", " ")))
+	if action == "" || action == Restrict {
+		return NoAction
+	}
+	return action
+}
+
+// Equal return true if the two foreign keys reference the same columns with the same actions
+func (fk *ForeignKey) Equal(dst *ForeignKey) bool {
+	if !strings.EqualFold(fk.RefTable, dst.RefTable) ||
+		len(fk.Cols) != len(dst.Cols) || len(fk.RefCols) != len(dst.RefCols) {
+		return false
+	}
+	for i := range fk.Cols {
+		if !strings.EqualFold(fk.Cols[i], dst.Cols[i]) {
+			return false
+		}
+	}
+	for i := range fk.RefCols {
+		if !strings.EqualFold(fk.RefCols[i], dst.RefCols[i]) {
+			return false
+		}
+	}
+	return NormalizeAction(fk.OnDelete) == NormalizeAction(dst.OnDelete) &&
+		NormalizeAction(fk.OnUpdate) == NormalizeAction(dst.OnUpdate)
+}
+
+// SortTablesByForeignKeys returns the indexes of the tables in an order which the
+// referenced tables are in front of the tables reference them. Circular references
+// cannot be satisfied, the first table of the circle will be put behind the others,
+// see CircularForeignKeys.
+func SortTablesByForeignKeys(tables []*Table) []int {
+	var (
+		order    = make([]int, 0, len(tables))
+		visited  = make([]bool, len(tables))
+		visiting = make([]bool, len(tables))
+		visit    func(int)
+	)
+	visit = func(i int) {
+		if visited[i] || visiting[i] {
+			return
+		}
+		visiting[i] = true
+		for _, name := range tables[i].ForeignKeyNames() {
+			refTable := tables[i].ForeignKeys[name].RefTable
+			for j, table := range tables {
+				if j != i && strings.EqualFold(table.Name, refTable) {
+					visit(j)
+				}
+			}
+		}
+		visiting[i] = false
+		visited[i] = true
+		order = append(order, i)
+	}
+	for i := range tables {
+		visit(i)
+	}
+	return order
+}
+
+// CircularForeignKeys returns the names of the foreign keys of the tables which reference the
+// tables behind them in the order returned by SortTablesByForeignKeys, they are keyed by the
+// indexes of the tables. These foreign keys cannot be created with the tables and should be
+// added after all the tables created.
+func CircularForeignKeys(tables []*Table, order []int) map[int][]string {
+	positions := make(map[string]int, len(order))
+	for pos, i := range order {
+		positions[strings.ToLower(tables[i].Name)] = pos
+	}
+
+	circularFKs := make(map[int][]string)
+	for pos, i := range order {
+		for _, name := range tables[i].ForeignKeyNames() {
+			refPos, ok := positions[strings.ToLower(tables[i].ForeignKeys[name].RefTable)]
+			if ok && refPos > pos {
+				circularFKs[i] = append(circularFKs[i], name)
+			}
+		}
+	}
+	return circularFKs
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortTablesByForeignKeys(t *testing.T) {
+	newTable := func(name string, refTables ...string) *Table {
+		table := NewTable(name, nil)
+		for _, refTable := range refTables {
+			fk := NewForeignKey(refTable+"_id", refTable)
+			fk.AddColumn(refTable+"_id", "id")
+			table.AddForeignKey(fk)
+		}
+		return table
+	}
+
+	tables := []*Table{
+		newTable("order_item", "order", "product"),
+		newTable("order", "user"),
+		newTable("product"),
+		newTable("user", "user"),
+	}
+	order := SortTablesByForeignKeys(tables)
+	assert.EqualValues(t, []int{3, 1, 2, 0}, order)
+	assert.Empty(t, CircularForeignKeys(tables, order))
+
+	// the first table of circular references will be put behind
+	tables = []*Table{
+		newTable("a", "b"),
+		newTable("b", "a"),
+	}
+	order = SortTablesByForeignKeys(tables)
+	assert.EqualValues(t, []int{1, 0}, order)
+	assert.EqualValues(t, map[int][]string{1: {"a_id"}}, CircularForeignKeys(tables, order))
+}
+
+func TestForeignKeyEqual(t *testing.T) {
+	fk := NewForeignKey("user_id", "user")
+	fk.AddColumn("user_id", "id")
+	fk2 := NewForeignKey("FK_order_user_id", "USER")
+	fk2.AddColumn("user_id", "id")
+	fk2.OnDelete = Restrict
+	assert.True(t, fk.Equal(fk2))
+	assert.EqualValues(t, "FK_order_user_id", fk.XName("public.order"))
+
+	fk2.OnDelete = Cascade
+	assert.False(t, fk.Equal(fk2))
+}
+
+func TestForeignKeyIsXName(t *testing.T) {
+	assert.True(t, NewForeignKey("FK_order_user_id", "user").IsXName("public.order"))
+	assert.True(t, NewForeignKey("fk_order_user_id", "user").IsXName("order"))
+	assert.False(t, NewForeignKey("user_id", "user").IsXName("order"))
+	assert.False(t, NewForeignKey("order_user_id_fkey", "user").IsXName("order"))
+	assert.False(t, NewForeignKey("FK_user_id", "user").IsXName("order"))
+	assert.False(t, NewForeignKey("", "user").IsXName("order"))
+}
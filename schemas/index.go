@@ -6,6 +6,7 @@ package schemas
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -20,12 +21,24 @@ type Index struct {
 	IsRegular bool
 	Name      string
 	Type      int
-	Cols      []string
+	// Cols are the keys of the index, a key could be a column name or an expression like lower(email)
+	Cols []string
+	// Method is the index method, e.g. BTREE, HASH, GIN, GIST or FULLTEXT, empty means the default one
+	Method string
+	// Where is the predicate of a partial index
+	Where string
+	// Desc contains the keys sorted descending
+	Desc map[string]bool
 }
 
 // NewIndex new an index object
 func NewIndex(name string, indexType int) *Index {
-	return &Index{true, name, indexType, make([]string, 0)}
+	return &Index{
+		IsRegular: true,
+		Name:      name,
+		Type:      indexType,
+		Cols:      make([]string, 0),
+	}
 }
 
 // XName returns the special index name for the table
@@ -47,11 +60,33 @@ func (index *Index) AddColumn(cols ...string) {
 	index.Cols = append(index.Cols, cols...)
 }
 
+// AddDescColumn add columns or expressions which will be sorted descending in the index
+func (index *Index) AddDescColumn(cols ...string) {
+	if index.Desc == nil {
+		index.Desc = make(map[string]bool)
+	}
+	for _, col := range cols {
+		index.Desc[col] = true
+	}
+	index.Cols = append(index.Cols, cols...)
+}
+
+// IsDesc returns true if the key is sorted descending in the index
+func (index *Index) IsDesc(col string) bool {
+	return index.Desc[col]
+}
+
 // Equal return true if the two Index is equal
 func (index *Index) Equal(dst *Index) bool {
 	if index.Type != dst.Type {
 		return false
 	}
+	if indexMethod(index.Method) != indexMethod(dst.Method) {
+		return false
+	}
+	if NormalizeIndexExpr(index.Where) != NormalizeIndexExpr(dst.Where) {
+		return false
+	}
 	if len(index.Cols) != len(dst.Cols) {
 		return false
 	}
@@ -59,7 +94,7 @@ func (index *Index) Equal(dst *Index) bool {
 	for i := 0; i < len(index.Cols); i++ {
 		var found bool
 		for j := 0; j < len(dst.Cols); j++ {
-			if index.Cols[i] == dst.Cols[j] {
+			if indexKeyEqual(index.Cols[i], dst.Cols[j]) && index.IsDesc(index.Cols[i]) == dst.IsDesc(dst.Cols[j]) {
 				found = true
 				break
 			}
@@ -70,3 +105,66 @@ func (index *Index) Equal(dst *Index) bool {
 	}
 	return true
 }
+
+// indexMethod returns the upper case index method, BTREE is treated as the default one
+func indexMethod(method string) string {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "BTREE" {
+		return ""
+	}
+	return method
+}
+
+func indexKeyEqual(key1, key2 string) bool {
+	if IsIndexExpr(key1) || IsIndexExpr(key2) {
+		return NormalizeIndexExpr(key1) == NormalizeIndexExpr(key2)
+	}
+	return key1 == key2
+}
+
+var (
+	indexColumnRegexp  = regexp.MustCompile(`^[\p{L}\p{N}_$#]+$`)
+	indexCastRegexp    = regexp.MustCompile(`::[a-z_]+`)
+	indexWrappedRegexp = regexp.MustCompile(`\(([\p{L}\p{N}_$#.']+)\)`)
+)
+
+// IsIndexExpr returns true if the key of an index is an expression but not a column name
+func IsIndexExpr(key string) bool {
+	return !indexColumnRegexp.MatchString(key)
+}
+
+// NormalizeIndexExpr normalizes an index expression or predicate for comparing, since the databases
+// rewrite them with quotes, casts and parentheses
+func NormalizeIndexExpr(expr string) string {
+	expr = strings.ToLower(expr)
+	expr = strings.NewReplacer(" ", "", "\t", "", "\n", "", `"`, "", "`", "", "[", "", "]", "").Replace(expr)
+	expr = indexCastRegexp.ReplaceAllString(expr, "")
+	for {
+		s := indexWrappedRegexp.ReplaceAllString(expr, "$1")
+		if s == expr {
+			break
+		}
+		expr = s
+	}
+	return TrimOuterParens(expr)
+}
+
+// TrimOuterParens removes the parentheses which wrap the whole expression
+func TrimOuterParens(expr string) string {
+	expr = strings.TrimSpace(expr)
+	for len(expr) >= 2 && expr[0] == '(' && expr[len(expr)-1] == ')' {
+		var depth int
+		for i := 0; i < len(expr)-1; i++ {
+			if expr[i] == '(' {
+				depth++
+			} else if expr[i] == ')' {
+				depth--
+			}
+			if depth == 0 {
+				return expr
+			}
+		}
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsIndexExpr(t *testing.T) {
+	assert.False(t, IsIndexExpr("name"))
+	assert.False(t, IsIndexExpr("user_id"))
+	assert.True(t, IsIndexExpr("lower(email)"))
+	assert.True(t, IsIndexExpr("a + b"))
+}
+
+func TestNormalizeIndexExpr(t *testing.T) {
+	assert.EqualValues(t, NormalizeIndexExpr("lower(email)"), NormalizeIndexExpr("lower((email)::text)"))
+	assert.EqualValues(t, NormalizeIndexExpr("deleted IS NULL"), NormalizeIndexExpr("([deleted] IS NULL)"))
+	assert.EqualValues(t, NormalizeIndexExpr("`a` > 5"), NormalizeIndexExpr(`("a" > 5)`))
+	assert.NotEqualValues(t, NormalizeIndexExpr("a > 5"), NormalizeIndexExpr("a > 6"))
+	assert.EqualValues(t, "(a) + (b)", TrimOuterParens("(a) + (b)"))
+	assert.EqualValues(t, "a + b", TrimOuterParens("((a + b))"))
+}
+
+func TestIndexEqual(t *testing.T) {
+	index := NewIndex("email", IndexType)
+	index.AddColumn("tenant_id", "lower(email)")
+	index.AddDescColumn("created")
+	index.Where = "deleted IS NULL"
+
+	dst := NewIndex("email", IndexType)
+	dst.AddColumn("lower((email)::text)", "tenant_id")
+	dst.AddDescColumn("created")
+	dst.Method = "btree"
+	dst.Where = "(deleted IS NULL)"
+	assert.True(t, index.Equal(dst))
+
+	dst.Where = ""
+	assert.False(t, index.Equal(dst))
+	dst.Where = index.Where
+
+	dst.Method = "GIN"
+	assert.False(t, index.Equal(dst))
+	dst.Method = ""
+
+	delete(dst.Desc, "created")
+	assert.False(t, index.Equal(dst))
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+// PartitionType represents the partitioning method of a table
+type PartitionType string
+
+// enumerates all the partitioning methods
+const (
+	RangePartition PartitionType = "RANGE"
+	ListPartition  PartitionType = "LIST"
+	HashPartition  PartitionType = "HASH"
+)
+
+// Partitioning describes how a table is partitioned
+type Partitioning struct {
+	Type PartitionType
+	// Keys are the partition key columns or expressions
+	Keys []string
+	// Partitions are the partitions created with the table
+	Partitions []*Partition
+}
+
+// NewPartitioning creates a partitioning with the keys
+func NewPartitioning(tp PartitionType, keys ...string) *Partitioning {
+	return &Partitioning{
+		Type: tp,
+		Keys: keys,
+	}
+}
+
+// AddPartition adds partitions which will be created with the table
+func (partitioning *Partitioning) AddPartition(partitions ...*Partition) {
+	partitioning.Partitions = append(partitioning.Partitions, partitions...)
+}
+
+// Partition represents a partition of a table. It's a table of Postgres,
+// and a named partition of MySQL.
+type Partition struct {
+	Name string
+	// From and To are the bounds of a RANGE partition, MINVALUE and MAXVALUE could be used
+	// for unbounded ranges. MySQL only uses To as VALUES LESS THAN.
+	From string
+	To   string
+	// Values are the values of a LIST partition
+	Values []string
+	// Modulus and Remainder are the bounds of a HASH partition of Postgres
+	Modulus   int
+	Remainder int
+	// IsDefault means the partition stores the rows not matching the others, only for Postgres
+	IsDefault bool
+}
+
+// NewRangePartition creates a RANGE partition
+func NewRangePartition(name, from, to string) *Partition {
+	return &Partition{
+		Name: name,
+		From: from,
+		To:   to,
+	}
+}
+
+// NewListPartition creates a LIST partition
+func NewListPartition(name string, values ...string) *Partition {
+	return &Partition{
+		Name:   name,
+		Values: values,
+	}
+}
+
+// NewHashPartition creates a HASH partition
+func NewHashPartition(name string, modulus, remainder int) *Partition {
+	return &Partition{
+		Name:      name,
+		Modulus:   modulus,
+		Remainder: remainder,
+	}
+}
@@ -6,6 +6,7 @@ package schemas
 
 import (
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -18,6 +19,8 @@ type Table struct {
 	columnsMap    map[string][]*Column
 	columns       []*Column
 	Indexes       map[string]*Index
+	ForeignKeys   map[string]*ForeignKey
+	Checks        map[string]*Check
 	PrimaryKeys   []string
 	AutoIncrement string
 	Created       map[string]bool
@@ -28,6 +31,14 @@ type Table struct {
 	Charset       string
 	Comment       string
 	Collation     string
+	RenamedFrom   string // the old table name, Sync will rename it to Name
+
+	IsView         bool   // the table is a view
+	IsMaterialized bool   // the view is a materialized view
+	ViewDefinition string // the SELECT statement of the view
+
+	Partitioning *Partitioning // nil if the table is not partitioned
+	PartitionOf  string        // the name of the partitioned table if the table is a partition
 }
 
 // NewEmptyTable creates an empty table
@@ -43,6 +54,8 @@ func NewTable(name string, t reflect.Type) *Table {
 		columns:     make([]*Column, 0),
 		columnsMap:  make(map[string][]*Column),
 		Indexes:     make(map[string]*Index),
+		ForeignKeys: make(map[string]*ForeignKey),
+		Checks:      make(map[string]*Check),
 		Created:     make(map[string]bool),
 		PrimaryKeys: make([]string, 0),
 	}
@@ -148,11 +161,90 @@ func (table *Table) AddColumn(col *Column) {
 	}
 }
 
+// RenameColumn renames the column and updates the references of the column in the table
+func (table *Table) RenameColumn(oldName, newName string) {
+	cols := table.columnsByName(oldName)
+	if cols == nil {
+		return
+	}
+	delete(table.columnsMap, strings.ToLower(oldName))
+	table.columnsMap[strings.ToLower(newName)] = append(table.columnsMap[strings.ToLower(newName)], cols...)
+	for _, col := range cols {
+		col.Name = newName
+	}
+
+	renameIn := func(names []string) {
+		for i, name := range names {
+			if strings.EqualFold(name, oldName) {
+				names[i] = newName
+			}
+		}
+	}
+	renameIn(table.columnsSeq)
+	renameIn(table.PrimaryKeys)
+	for _, index := range table.Indexes {
+		renameIn(index.Cols)
+		if index.IsDesc(oldName) {
+			delete(index.Desc, oldName)
+			index.Desc[newName] = true
+		}
+	}
+	for _, fk := range table.ForeignKeys {
+		renameIn(fk.Cols)
+	}
+	if strings.EqualFold(table.AutoIncrement, oldName) {
+		table.AutoIncrement = newName
+	}
+	if table.Created[oldName] {
+		delete(table.Created, oldName)
+		table.Created[newName] = true
+	}
+	if strings.EqualFold(table.Updated, oldName) {
+		table.Updated = newName
+	}
+	if strings.EqualFold(table.Deleted, oldName) {
+		table.Deleted = newName
+	}
+	if strings.EqualFold(table.Version, oldName) {
+		table.Version = newName
+	}
+}
+
 // AddIndex adds an index or an unique to table
 func (table *Table) AddIndex(index *Index) {
 	table.Indexes[index.Name] = index
 }
 
+// ForeignKeyNames returns the names of the foreign keys of the table in order
+func (table *Table) ForeignKeyNames() []string {
+	names := make([]string, 0, len(table.ForeignKeys))
+	for name := range table.ForeignKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddForeignKey adds a foreign key to table
+func (table *Table) AddForeignKey(fk *ForeignKey) {
+	table.ForeignKeys[fk.Name] = fk
+}
+
+// CheckNames returns the names of the checks of the table in order
+func (table *Table) CheckNames() []string {
+	names := make([]string, 0, len(table.Checks))
+	for name := range table.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddCheck adds a CHECK constraint to table
+func (table *Table) AddCheck(check *Check) {
+	table.Checks[check.Name] = check
+}
+
 // IDOfV get id from one value of struct
 func (table *Table) IDOfV(rv reflect.Value) (PK, error) {
 	v := reflect.Indirect(rv)
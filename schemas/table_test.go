@@ -55,6 +55,33 @@ func TestGetColumnIdx(t *testing.T) {
 	}
 }
 
+func TestRenameColumn(t *testing.T) {
+	table := NewEmptyTable()
+	id := NewColumn("id", "Id", SQLType{Name: BigInt}, 0, 0, false)
+	id.IsPrimaryKey = true
+	table.AddColumn(id)
+	table.AddColumn(NewColumn("name", "Name", SQLType{Name: Varchar}, 255, 0, true))
+	index := NewIndex("name", IndexType)
+	index.AddDescColumn("name")
+	table.AddIndex(index)
+
+	table.RenameColumn("name", "nickname")
+	if table.GetColumn("name") != nil || table.GetColumn("nickname") == nil {
+		t.Fatal("column is not renamed")
+	}
+	if table.ColumnsSeq()[1] != "nickname" || table.GetColumn("nickname").Name != "nickname" {
+		t.Errorf("column name is not updated: %v", table.ColumnsSeq())
+	}
+	if index.Cols[0] != "nickname" || !index.IsDesc("nickname") {
+		t.Errorf("index is not updated: %v", index.Cols)
+	}
+
+	table.RenameColumn("id", "uid")
+	if table.PrimaryKeys[0] != "uid" {
+		t.Errorf("primary key is not updated: %v", table.PrimaryKeys)
+	}
+}
+
 func BenchmarkGetColumnWithToLower(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for _, test := range testsGetColumn {
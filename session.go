@@ -233,8 +233,12 @@ func (session *Session) After(closures func(interface{})) *Session {
 	return session
 }
 
-// Table can input a string or pointer to struct for special a table to operate.
+// Table can input a string or pointer to struct for special a table to operate, or another
+// session as a subquery which is named by the alias or the table name of the session.
 func (session *Session) Table(tableNameOrBean interface{}) *Session {
+	if query, ok := tableNameOrBean.(*Session); ok {
+		tableNameOrBean = query.statement
+	}
 	if err := session.statement.SetTable(tableNameOrBean); err != nil {
 		session.statement.LastError = err
 	}
@@ -327,8 +331,12 @@ func (session *Session) NoCache() *Session {
 	return session
 }
 
-// Join join_operator should be one of INNER, LEFT OUTER, CROSS etc - this will be prepended to JOIN
+// Join join_operator should be one of INNER, LEFT OUTER, CROSS etc - this will be prepended to JOIN,
+// the table could be another session as a subquery which is named by its alias or table name
 func (session *Session) Join(joinOperator string, tablename interface{}, condition interface{}, args ...interface{}) *Session {
+	if query, ok := tablename.(*Session); ok {
+		tablename = query.statement
+	}
 	session.statement.Join(joinOperator, tablename, condition, args...)
 	return session
 }
@@ -356,6 +364,9 @@ func (session *Session) canCache() bool {
 		session.statement.RawSQL != "" ||
 		!session.statement.UseCache ||
 		session.statement.IsForUpdate ||
+		session.statement.HasCTE() ||
+		session.statement.IsCompound() ||
+		session.statement.IsSubQueryTable() ||
 		session.tx != nil ||
 		len(session.statement.SelectStr) > 0 {
 		return false
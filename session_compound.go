@@ -0,0 +1,46 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"xorm.io/xorm/internal/statements"
+)
+
+// Union combines the rows of the queries and removes the duplicated rows. The queries are
+// combined from left to right with the previous queries of the session, the first query is
+// the first one if there are no previous queries. The conditions, columns, orders and
+// pagination of the session itself are applied to the combined rows, i.e.
+//
+//	engine.Union(engine.Table(new(User)).Where("age < ?", 18), engine.Table(new(User)).Where("age > ?", 60)).
+//		Desc("id").Limit(10).Find(&users)
+//
+// The conditions of the beans of Find, Get and Count are not applied, they should be added
+// to the queries.
+func (session *Session) Union(queries ...*Session) *Session {
+	return session.compound(statements.CompoundUnion, queries)
+}
+
+// UnionAll combines the rows of the queries and keeps the duplicated rows, see Union
+func (session *Session) UnionAll(queries ...*Session) *Session {
+	return session.compound(statements.CompoundUnionAll, queries)
+}
+
+// Intersect returns the rows returned by both the previous queries and the queries, see Union
+func (session *Session) Intersect(queries ...*Session) *Session {
+	return session.compound(statements.CompoundIntersect, queries)
+}
+
+// Except returns the rows returned by the previous queries but not by the queries, it's
+// rendered as MINUS on Oracle, see Union
+func (session *Session) Except(queries ...*Session) *Session {
+	return session.compound(statements.CompoundExcept, queries)
+}
+
+func (session *Session) compound(op string, queries []*Session) *Session {
+	for _, query := range queries {
+		session.statement.Compound(op, query.statement)
+	}
+	return session
+}
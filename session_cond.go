@@ -37,18 +37,44 @@ func (session *Session) ID(id interface{}) *Session {
 	return session
 }
 
-// In provides a query string like "id in (1, 2, 3)"
+// In provides a query string like "id in (1, 2, 3)", the argument could be another session
+// as a subquery like "id in (SELECT ...)"
 func (session *Session) In(column string, args ...interface{}) *Session {
-	session.statement.In(column, args...)
+	session.statement.In(column, subQueryArgs(args)...)
 	return session
 }
 
-// NotIn provides a query string like "id in (1, 2, 3)"
+// NotIn provides a query string like "id in (1, 2, 3)", the argument could be another session
+// as a subquery like "id not in (SELECT ...)"
 func (session *Session) NotIn(column string, args ...interface{}) *Session {
-	session.statement.NotIn(column, args...)
+	session.statement.NotIn(column, subQueryArgs(args)...)
 	return session
 }
 
+// WhereExists provides a query string like "EXISTS (SELECT ...)" with the query of another session,
+// which is generated as Find does, i.e. with the mapped table name, columns and the condition
+// of soft delete. The session of the subquery should not be used by other queries.
+func (session *Session) WhereExists(query *Session) *Session {
+	session.statement.WhereExists(query.statement)
+	return session
+}
+
+// WhereNotExists provides a query string like "NOT EXISTS (SELECT ...)", see WhereExists
+func (session *Session) WhereNotExists(query *Session) *Session {
+	session.statement.WhereNotExists(query.statement)
+	return session
+}
+
+// subQueryArgs replaces the session of a subquery with its statement
+func subQueryArgs(args []interface{}) []interface{} {
+	if len(args) == 1 {
+		if query, ok := args[0].(*Session); ok {
+			return []interface{}{query.statement}
+		}
+	}
+	return args
+}
+
 // Conds returns session query conditions except auto bean conditions
 func (session *Session) Conds() builder.Cond {
 	return session.statement.Conds()
@@ -0,0 +1,41 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"xorm.io/xorm/internal/statements"
+)
+
+var (
+	// ErrUnsupportedCTEQuery represents the query of a common table expression is not supported
+	ErrUnsupportedCTEQuery = statements.ErrUnsupportedCTEQuery
+	// ErrSubQueryCTEUnsupported represents the subquery has common table expressions which are
+	// not supported by the database, i.e. MSSQL
+	ErrSubQueryCTEUnsupported = statements.ErrSubQueryCTEUnsupported
+)
+
+// With adds a common table expression named name before the query, which could be used as
+// a table by Table or Join. The query should be a *Session or a *builder.Builder, the SQL of
+// a session is generated as Find does, i.e. with the mapped table name, columns and the
+// condition of soft delete, and the session should not be used by other queries.
+func (session *Session) With(name string, query interface{}) *Session {
+	return session.with(name, false, query)
+}
+
+// WithRecursive adds a recursive common table expression which could refer to itself, the query
+// is usually a union of the initial rows and the rows joined with the expression. The name could
+// be followed by the column list like tree(id,parent_id), which is required by Oracle.
+func (session *Session) WithRecursive(name string, query interface{}) *Session {
+	return session.with(name, true, query)
+}
+
+func (session *Session) with(name string, recursive bool, query interface{}) *Session {
+	if sub, ok := query.(*Session); ok {
+		session.statement.With(name, recursive, sub.statement)
+	} else {
+		session.statement.With(name, recursive, query)
+	}
+	return session
+}
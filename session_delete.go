@@ -6,6 +6,7 @@ package xorm
 
 import (
 	"errors"
+	"reflect"
 	"strconv"
 
 	"xorm.io/builder"
@@ -113,6 +114,9 @@ func (session *Session) delete(beans []interface{}, mustHaveConditions bool) (in
 		if err = session.statement.SetRefBean(bean); err != nil {
 			return 0, err
 		}
+		if session.statement.RefTable.IsView {
+			return 0, ErrViewReadOnly
+		}
 
 		executeBeforeClosures(session, bean)
 
@@ -163,9 +167,24 @@ func (session *Session) delete(beans []interface{}, mustHaveConditions bool) (in
 	}
 
 	session.statement.RefTable = table
-	res, err := session.exec(realSQLWriter.String(), realSQLWriter.Args()...)
-	if err != nil {
-		return 0, err
+	var affected int64
+	if session.statement.IsReturning() {
+		var beans []interface{}
+		if bean != nil && reflect.ValueOf(bean).Kind() == reflect.Ptr {
+			beans = append(beans, bean)
+		}
+		affected, err = session.execReturning(realSQLWriter.String(), realSQLWriter.Args(), session.statement.ReturningColumns(), beans...)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		res, err := session.exec(realSQLWriter.String(), realSQLWriter.Args()...)
+		if err != nil {
+			return 0, err
+		}
+		if affected, err = res.RowsAffected(); err != nil {
+			return 0, err
+		}
 	}
 
 	if bean != nil {
@@ -197,5 +216,5 @@ func (session *Session) delete(beans []interface{}, mustHaveConditions bool) (in
 	cleanupProcessorsClosures(&session.afterClosures)
 	// --
 
-	return res.RowsAffected()
+	return affected, nil
 }
@@ -119,7 +119,8 @@ func (session *Session) find(rowsSlicePtr interface{}, condiBean ...interface{})
 		addedTableName = session.statement.NeedTableName()
 		autoCond       builder.Cond
 	)
-	if tp == tpStruct {
+	// the conditions of the beans have been applied by the queries of the compound query
+	if tp == tpStruct && !session.statement.IsCompound() {
 		if !session.statement.NoAutoCondition && len(condiBean) > 0 {
 			condTable, err := session.engine.tagParser.Parse(reflect.ValueOf(condiBean[0]))
 			if err != nil {
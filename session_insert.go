@@ -5,8 +5,10 @@
 package xorm
 
 import (
+	gosql "database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
 	"strings"
@@ -79,6 +81,9 @@ func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, e
 	if err := session.statement.SetRefBean(sliceValue.Index(0).Interface()); err != nil {
 		return 0, err
 	}
+	if session.statement.RefTable.IsView {
+		return 0, ErrViewReadOnly
+	}
 
 	tableName := session.statement.TableName()
 	if len(tableName) == 0 {
@@ -91,9 +96,12 @@ func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, e
 		colNames       []string
 		colMultiPlaces []string
 		args           []interface{}
+		argsOffsets    = make([]int, 0, size)
+		hasIDs         bool
 	)
 
 	for i := 0; i < size; i++ {
+		argsOffsets = append(argsOffsets, len(args))
 		v := sliceValue.Index(i)
 		var vv reflect.Value
 		switch v.Kind() {
@@ -122,6 +130,9 @@ func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, e
 				return 0, err
 			}
 			fieldValue := *ptrFieldValue
+			if col.IsAutoIncrement && !utils.IsZero(fieldValue.Interface()) {
+				hasIDs = true
+			}
 			if col.IsAutoIncrement && utils.IsZero(fieldValue.Interface()) {
 				if session.engine.dialect.Features().AutoincrMode == dialects.SequenceAutoincrMode {
 					if i == 0 {
@@ -131,7 +142,7 @@ func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, e
 				}
 				continue
 			}
-			if col.MapType == schemas.ONLYFROMDB {
+			if col.MapType == schemas.ONLYFROMDB || col.IsGenerated() {
 				continue
 			}
 			if col.IsDeleted {
@@ -187,14 +198,75 @@ func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, e
 	}
 	cleanupProcessorsClosures(&session.beforeClosures)
 
-	w := builder.NewWriter()
-	if err := session.statement.WriteInsertMultiple(w, tableName, colNames, colMultiPlaces); err != nil {
-		return 0, err
+	// the generated ids will be assigned to the elements if no ids are given, it's unsafe
+	// for upsert because the conflicting rows may be skipped. OUTPUT without INTO fails on
+	// the MSSQL tables with triggers, so Returning should be called explicitly on MSSQL.
+	var assignIDs, queryIDStep bool
+	if len(table.AutoIncrement) > 0 && !hasIDs && session.statement.Upsert == nil && !session.statement.IsReturning() {
+		switch session.engine.dialect.URI().DBType {
+		case schemas.POSTGRES:
+			session.statement.SetReturning(table.AutoIncrement)
+			// the returning is only for this slice but not the following beans of Insert
+			defer func() {
+				session.statement.Returning = nil
+			}()
+		case schemas.MYSQL:
+			assignIDs, queryIDStep = true, true
+		case schemas.SQLITE:
+			assignIDs = true
+		}
 	}
 
-	res, err := session.exec(w.String(), args...)
-	if err != nil {
-		return 0, err
+	// the rows are inserted by chunks in one transaction if there are too many parameters, and
+	// the step of the ids on MySQL should be queried on the same connection of the insert
+	chunkSize := session.insertChunkSize(len(colNames))
+	var needCommit bool
+	if (chunkSize < size || queryIDStep) && session.isAutoCommit {
+		if err := session.Begin(); err != nil {
+			return 0, err
+		}
+		needCommit = true
+	}
+
+	var idStep int64 = 1
+	if queryIDStep {
+		var err error
+		if assignIDs, idStep, err = session.mysqlIDStep(); err != nil {
+			if needCommit {
+				_ = session.Rollback()
+			}
+			return 0, err
+		}
+	}
+	if !assignIDs {
+		idStep = 0
+	}
+
+	var affected int64
+	for start := 0; start < size; start += chunkSize {
+		end := start + chunkSize
+		if end > size {
+			end = size
+		}
+		argsEnd := len(args)
+		if end < size {
+			argsEnd = argsOffsets[end]
+		}
+
+		cnt, err := session.insertMultipleChunk(tableName, colNames, colMultiPlaces[start:end],
+			args[argsOffsets[start]:argsEnd], sliceValue.Slice(start, end), idStep)
+		if err != nil {
+			if needCommit {
+				_ = session.Rollback()
+			}
+			return 0, err
+		}
+		affected += cnt
+	}
+	if needCommit {
+		if err := session.Commit(); err != nil {
+			return 0, err
+		}
 	}
 
 	_ = session.cacheInsert(tableName)
@@ -230,9 +302,120 @@ func (session *Session) insertMultipleStruct(rowsSlicePtr interface{}) (int64, e
 	}
 
 	cleanupProcessorsClosures(&session.afterClosures)
+	return affected, nil
+}
+
+// insertChunkSize returns the max number of rows could be inserted by one statement according
+// to the limits of the dialect
+func (session *Session) insertChunkSize(paramsPerRow int) int {
+	features := session.engine.dialect.Features()
+	size := math.MaxInt32
+	if features.MaxParams > 0 && paramsPerRow > 0 {
+		size = features.MaxParams / paramsPerRow
+		if size < 1 {
+			size = 1
+		}
+	}
+	if features.MaxRows > 0 && size > features.MaxRows {
+		size = features.MaxRows
+	}
+	return size
+}
+
+// insertMultipleChunk inserts the rows of the elements by one statement, the generated ids are
+// assigned to the elements by the step if it's not zero
+func (session *Session) insertMultipleChunk(tableName string, colNames, colMultiPlaces []string, args []interface{},
+	sliceValue reflect.Value, idStep int64,
+) (int64, error) {
+	w := builder.NewWriter()
+	if err := session.statement.WriteInsertMultiple(w, tableName, colNames, colMultiPlaces); err != nil {
+		return 0, err
+	}
+
+	table := session.statement.RefTable
+	if session.statement.IsReturning() {
+		beans := make([]interface{}, 0, sliceValue.Len())
+		for i := 0; i < sliceValue.Len(); i++ {
+			v := sliceValue.Index(i)
+			if v.Kind() == reflect.Interface {
+				v = v.Elem()
+			}
+			switch {
+			case v.Kind() == reflect.Ptr:
+				beans = append(beans, v.Interface())
+			case v.CanAddr():
+				beans = append(beans, v.Addr().Interface())
+			default:
+				// the element cannot be assigned
+				beans = append(beans, nil)
+			}
+		}
+		returningCols := session.statement.ReturningColumns(table.AutoIncrement)
+		return session.execReturning(w.String(), args, returningCols, beans...)
+	}
+
+	res, err := session.exec(w.String(), args...)
+	if err != nil {
+		return 0, err
+	}
+	if idStep != 0 {
+		if err := session.assignInsertedIDs(res, sliceValue, table, idStep); err != nil {
+			return 0, err
+		}
+	}
 	return res.RowsAffected()
 }
 
+// mysqlIDStep returns the step of the ids generated by a multiple rows insert on MySQL, it should
+// be called in the transaction of the insert since auto_increment_increment is of the session.
+// The ids are consecutive in one statement only if innodb_autoinc_lock_mode is 0 or 1, they may
+// be interleaved with the concurrent inserts in the mode 2 which is the default of MySQL 8.
+func (session *Session) mysqlIDStep() (bool, int64, error) {
+	var lockMode, step int64
+	if err := session.queryRow("SELECT @@innodb_autoinc_lock_mode, @@auto_increment_increment").Scan(&lockMode, &step); err != nil {
+		return false, 0, err
+	}
+	if lockMode != 0 && lockMode != 1 {
+		return false, 0, nil
+	}
+	return true, step, nil
+}
+
+// assignInsertedIDs assigns the ids generated by a multiple rows insert to the elements, the ids
+// are increased by the step in one statement. LastInsertId returns the id of the first row on
+// MySQL and the last row on SQLite.
+func (session *Session) assignInsertedIDs(res gosql.Result, sliceValue reflect.Value, table *schemas.Table, step int64) error {
+	id, err := res.LastInsertId()
+	if err != nil || id <= 0 {
+		return nil
+	}
+
+	size := sliceValue.Len()
+	firstID := id
+	if session.engine.dialect.URI().DBType != schemas.MYSQL {
+		firstID = id - int64(size-1)*step
+	}
+
+	for i := 0; i < size; i++ {
+		v := sliceValue.Index(i)
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		vv := reflect.Indirect(v)
+		aiValue, err := table.AutoIncrColumn().ValueOfV(&vv)
+		if err != nil {
+			return err
+		}
+		if aiValue == nil || !aiValue.IsValid() || !aiValue.CanSet() {
+			continue
+		}
+		if err := convert.AssignValue(*aiValue, firstID+int64(i)*step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // InsertMulti insert multiple records
 func (session *Session) InsertMulti(rowsSlicePtr interface{}) (int64, error) {
 	if session.isAutoClose {
@@ -244,13 +427,47 @@ func (session *Session) InsertMulti(rowsSlicePtr interface{}) (int64, error) {
 		return 0, ErrPtrSliceType
 	}
 
+	// the statement will be used by all the chunks
+	session.autoResetStatement = false
+	defer func() {
+		session.autoResetStatement = true
+		session.resetStatement()
+	}()
+
 	return session.insertMultipleStruct(rowsSlicePtr)
 }
 
+// OnConflict makes the following inserts upsert, the conflicts are detected by the unique
+// constraint of the columns, or the primary key if no columns. It's rendered as ON CONFLICT on
+// Postgres and SQLite, ON DUPLICATE KEY UPDATE on MySQL which ignores the columns, and MERGE on
+// MSSQL, Oracle and Dameng. The conflicting rows will be updated by all the inserted columns if
+// neither DoUpdate nor DoNothing is called.
+func (session *Session) OnConflict(cols ...string) *Session {
+	session.statement.OnConflict(cols...)
+	return session
+}
+
+// DoUpdate updates the columns of the conflicting rows with the inserted values. The created
+// columns are not updated and the version column is increased if no columns. The updated
+// columns are always updated with the current time.
+func (session *Session) DoUpdate(cols ...string) *Session {
+	session.statement.DoUpdate(cols...)
+	return session
+}
+
+// DoNothing keeps the conflicting rows without any changes
+func (session *Session) DoNothing() *Session {
+	session.statement.DoNothing()
+	return session
+}
+
 func (session *Session) insertStruct(bean interface{}) (int64, error) {
 	if err := session.statement.SetRefBean(bean); err != nil {
 		return 0, err
 	}
+	if session.statement.RefTable.IsView {
+		return 0, ErrViewReadOnly
+	}
 	if len(session.statement.TableName()) == 0 {
 		return 0, ErrTableNotFound
 	}
@@ -306,8 +523,35 @@ func (session *Session) insertStruct(bean interface{}) (int64, error) {
 		cleanupProcessorsClosures(&session.afterClosures) // cleanup after used
 	}
 
+	if session.statement.IsReturning() {
+		returningCols := session.statement.ReturningColumns(table.AutoIncrement)
+		cnt, err := session.execReturning(sqlStr, args, returningCols, bean)
+		if err != nil {
+			return 0, err
+		}
+
+		defer handleAfterInsertProcessorFunc(bean)
+
+		_ = session.cacheInsert(tableName)
+
+		// the version column has been assigned if it's returned
+		if table.Version != "" && session.statement.CheckVersion && utils.IndexSlice(returningCols, table.Version) < 0 {
+			verValue, err := table.VersionColumn().ValueOf(bean)
+			if err != nil {
+				session.engine.logger.Errorf("%v", err)
+			} else if verValue.IsValid() && verValue.CanSet() {
+				session.incrVersionFieldValue(verValue)
+			}
+		}
+		return cnt, nil
+	}
+
+	// the id cannot be known for Oracle and Dameng if the conflicting row is updated
+	isSeqUpsert := session.statement.Upsert != nil && (session.engine.dialect.URI().DBType == schemas.ORACLE ||
+		session.engine.dialect.URI().DBType == schemas.DAMENG)
+
 	// if there is auto increment column and driver don't support return it
-	if len(table.AutoIncrement) > 0 && !session.engine.driver.Features().SupportReturnInsertedID {
+	if len(table.AutoIncrement) > 0 && !session.engine.driver.Features().SupportReturnInsertedID && !isSeqUpsert {
 		var sql string
 		var newArgs []interface{}
 		var needCommit bool
@@ -339,7 +583,13 @@ func (session *Session) insertStruct(bean interface{}) (int64, error) {
 
 		if id == 0 {
 			err := session.queryRow(sql, newArgs...).Scan(&id)
-			if err != nil {
+			if errors.Is(err, gosql.ErrNoRows) && session.statement.Upsert != nil {
+				// the conflicting row is kept and nothing returned
+				if needCommit {
+					return 0, session.Commit()
+				}
+				return 0, nil
+			} else if err != nil {
 				return 0, err
 			}
 		}
@@ -453,7 +703,7 @@ func (session *Session) genInsertColumns(bean interface{}) ([]string, []interfac
 	args := make([]interface{}, 0, len(table.ColumnsSeq()))
 
 	for _, col := range table.Columns() {
-		if col.MapType == schemas.ONLYFROMDB {
+		if col.MapType == schemas.ONLYFROMDB || col.IsGenerated() {
 			continue
 		}
 		if session.statement.OmitColumnMap.Contain(col.Name) {
@@ -645,6 +895,10 @@ func (session *Session) insertMultipleMapString(maps []map[string]string) (int64
 }
 
 func (session *Session) insertMap(columns []string, args []interface{}) (int64, error) {
+	if session.statement.IsReturning() {
+		return 0, ErrReturningMap
+	}
+
 	tableName := session.statement.TableName()
 	if len(tableName) == 0 {
 		return 0, ErrTableNotFound
@@ -672,28 +926,63 @@ func (session *Session) insertMap(columns []string, args []interface{}) (int64,
 }
 
 func (session *Session) insertMultipleMap(columns []string, argss [][]interface{}) (int64, error) {
+	if session.statement.IsReturning() {
+		return 0, ErrReturningMap
+	}
+
 	tableName := session.statement.TableName()
 	if len(tableName) == 0 {
 		return 0, ErrTableNotFound
 	}
 
-	sql, args, err := session.statement.GenInsertMultipleMapSQL(columns, argss)
-	if err != nil {
+	if err := session.cacheInsert(tableName); err != nil {
 		return 0, err
 	}
-	sql = session.engine.dialect.Quoter().Replace(sql)
 
-	if err := session.cacheInsert(tableName); err != nil {
-		return 0, err
+	// the rows are inserted by chunks in one transaction if there are too many parameters
+	chunkSize := session.insertChunkSize(len(columns) + len(session.statement.ExprColumns))
+	var needCommit bool
+	if chunkSize < len(argss) && session.isAutoCommit {
+		if err := session.Begin(); err != nil {
+			return 0, err
+		}
+		needCommit = true
 	}
 
-	res, err := session.exec(sql, args...)
+	var affected int64
+	for start := 0; start < len(argss); start += chunkSize {
+		end := start + chunkSize
+		if end > len(argss) {
+			end = len(argss)
+		}
+
+		cnt, err := session.insertMultipleMapChunk(columns, argss[start:end])
+		if err != nil {
+			if needCommit {
+				_ = session.Rollback()
+			}
+			return 0, err
+		}
+		affected += cnt
+	}
+	if needCommit {
+		if err := session.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	return affected, nil
+}
+
+func (session *Session) insertMultipleMapChunk(columns []string, argss [][]interface{}) (int64, error) {
+	sql, args, err := session.statement.GenInsertMultipleMapSQL(columns, argss)
 	if err != nil {
 		return 0, err
 	}
-	affected, err := res.RowsAffected()
+	sql = session.engine.dialect.Quoter().Replace(sql)
+
+	res, err := session.exec(sql, args...)
 	if err != nil {
 		return 0, err
 	}
-	return affected, nil
+	return res.RowsAffected()
 }
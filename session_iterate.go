@@ -69,6 +69,8 @@ func (session *Session) BufferSize(size int) *Session {
 	return session
 }
 
+// bufferIterate queries the rows by pages, the pages are queried by keyset pagination if the rows
+// are ordered by unique columns, or the primary key if there are no orders, otherwise by OFFSET
 func (session *Session) bufferIterate(bean interface{}, fun IterFunc) error {
 	bufferSize := session.statement.BufferSize
 	pLimitN := session.statement.LimitN
@@ -84,6 +86,17 @@ func (session *Session) bufferIterate(bean interface{}, fun IterFunc) error {
 		session.autoResetStatement = true
 	}()
 
+	if session.statement.RefTable == nil {
+		if err := session.statement.SetRefBean(bean); err != nil {
+			return err
+		}
+	}
+	keyset, err := session.statement.Keyset(false)
+	if err != nil {
+		// the rows could not be paged by keyset
+		keyset = nil
+	}
+
 	for bufferSize > 0 {
 		slice := reflect.New(sliceType)
 		if err := session.NoCache().Limit(bufferSize, start).find(slice.Interface(), bean); err != nil {
@@ -101,9 +114,18 @@ func (session *Session) bufferIterate(bean interface{}, fun IterFunc) error {
 			break
 		}
 
-		start += slice.Elem().Len()
-		if pLimitN != nil && start+bufferSize > *pLimitN {
-			bufferSize = *pLimitN - start
+		if keyset != nil {
+			values, err := session.statement.KeysetValues(keyset, slice.Elem().Index(slice.Elem().Len()-1))
+			if err != nil {
+				return err
+			}
+			session.statement.After(keyset, values)
+			start = 0
+		} else {
+			start += slice.Elem().Len()
+		}
+		if pLimitN != nil && idx+bufferSize > *pLimitN {
+			bufferSize = *pLimitN - idx
 		}
 	}
 
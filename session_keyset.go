@@ -0,0 +1,243 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"xorm.io/xorm/internal/statements"
+)
+
+var (
+	// ErrKeysetUnsupported represents the query cannot be paged by keyset
+	ErrKeysetUnsupported = statements.ErrKeysetUnsupported
+	// ErrKeysetNotUnique represents the rows are not ordered by unique columns
+	ErrKeysetNotUnique = statements.ErrKeysetNotUnique
+	// ErrKeysetNullValue represents a keyset column of the row is NULL
+	ErrKeysetNullValue = statements.ErrKeysetNullValue
+	// ErrInvalidCursor represents the cursor cannot be decoded or is not for the orders of the query
+	ErrInvalidCursor = errors.New("Invalid cursor")
+)
+
+// Cursor is the position of keyset pagination, it holds the values of the ordered columns of the
+// last row of a page. It's opaque and could be encoded by String or MarshalText and decoded by
+// ParseCursor or UnmarshalText, so it could be passed to the clients to get the next page.
+type Cursor struct {
+	keyset []string
+	values []string
+}
+
+// the encoded form of a cursor, a value is prefixed by the kind of it
+type cursorData struct {
+	Keyset []string `json:"k"`
+	Values []string `json:"v"`
+}
+
+const (
+	cursorInt    = 'i'
+	cursorUint   = 'u'
+	cursorFloat  = 'f'
+	cursorString = 's'
+	cursorBool   = 'b'
+	cursorBytes  = 'x'
+)
+
+func newCursor(keyset []statements.KeysetColumn, values []interface{}) (*Cursor, error) {
+	cursor := &Cursor{
+		keyset: make([]string, 0, len(keyset)),
+		values: make([]string, 0, len(values)),
+	}
+	for i, col := range keyset {
+		cursor.keyset = append(cursor.keyset, col.String())
+
+		v := reflect.ValueOf(values[i])
+		var value string
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value = string(cursorInt) + strconv.FormatInt(v.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value = string(cursorUint) + strconv.FormatUint(v.Uint(), 10)
+		case reflect.Float32, reflect.Float64:
+			value = string(cursorFloat) + strconv.FormatFloat(v.Float(), 'g', -1, 64)
+		case reflect.String:
+			value = string(cursorString) + v.String()
+		case reflect.Bool:
+			value = string(cursorBool) + strconv.FormatBool(v.Bool())
+		case reflect.Slice:
+			if v.Type().Elem().Kind() != reflect.Uint8 {
+				return nil, fmt.Errorf("Unsupported type %T of keyset pagination", values[i])
+			}
+			value = string(cursorBytes) + base64.StdEncoding.EncodeToString(v.Bytes())
+		default:
+			return nil, fmt.Errorf("Unsupported type %T of keyset pagination", values[i])
+		}
+		cursor.values = append(cursor.values, value)
+	}
+	return cursor, nil
+}
+
+// ParseCursor decodes the cursor encoded by String
+func ParseCursor(s string) (*Cursor, error) {
+	cursor := new(Cursor)
+	if err := cursor.UnmarshalText([]byte(s)); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+// String encodes the cursor as an URL safe string
+func (cursor *Cursor) String() string {
+	bs, _ := json.Marshal(cursorData{Keyset: cursor.keyset, Values: cursor.values})
+	return base64.RawURLEncoding.EncodeToString(bs)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (cursor *Cursor) MarshalText() ([]byte, error) {
+	return []byte(cursor.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (cursor *Cursor) UnmarshalText(text []byte) error {
+	bs, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	var data cursorData
+	if err := json.Unmarshal(bs, &data); err != nil || len(data.Keyset) == 0 || len(data.Keyset) != len(data.Values) {
+		return ErrInvalidCursor
+	}
+	for _, value := range data.Values {
+		if _, err := decodeCursorValue(value); err != nil {
+			return err
+		}
+	}
+	cursor.keyset = data.Keyset
+	cursor.values = data.Values
+	return nil
+}
+
+func decodeCursorValue(value string) (interface{}, error) {
+	if len(value) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	var v interface{}
+	var err error
+	switch s := value[1:]; value[0] {
+	case cursorInt:
+		v, err = strconv.ParseInt(s, 10, 64)
+	case cursorUint:
+		v, err = strconv.ParseUint(s, 10, 64)
+	case cursorFloat:
+		v, err = strconv.ParseFloat(s, 64)
+	case cursorString:
+		v = s
+	case cursorBool:
+		v, err = strconv.ParseBool(s)
+	case cursorBytes:
+		v, err = base64.StdEncoding.DecodeString(s)
+	default:
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return v, nil
+}
+
+// keysetValues returns the values of the cursor, the cursor should be generated by the same orders
+func (cursor *Cursor) keysetValues(keyset []statements.KeysetColumn) ([]interface{}, error) {
+	if len(cursor.keyset) != len(keyset) {
+		return nil, ErrInvalidCursor
+	}
+	values := make([]interface{}, 0, len(keyset))
+	for i, col := range keyset {
+		if cursor.keyset[i] != col.String() {
+			return nil, ErrInvalidCursor
+		}
+		v, err := decodeCursorValue(cursor.values[i])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// FindPage finds a page of rows after the cursor by keyset pagination instead of OFFSET, so the
+// pages will not become slower when going deeper and no rows are skipped or duplicated when rows
+// are inserted or deleted between the pages. The cursor should be nil for the first page, and the
+// returned cursor is the position of the next page which is nil if there are no more rows.
+//
+// The rows are ordered by the columns of Asc and Desc, or the primary key if there are no orders,
+// and the primary key is appended to the orders if the columns are not unique. The same conditions
+// and orders should be used for all the pages, i.e.
+//
+//	var users []User
+//	cursor, err := engine.Where("age > ?", 18).Desc("created").FindPage(&users, 20, nil)
+//	// get the next page by the cursor if it's not nil
+//	var nextUsers []User
+//	cursor, err = engine.Where("age > ?", 18).Desc("created").FindPage(&nextUsers, 20, cursor)
+//
+// The rows are appended to rowsSlicePtr which should be a pointer to []Struct or []*Struct.
+func (session *Session) FindPage(rowsSlicePtr interface{}, size int, cursor *Cursor, condiBean ...interface{}) (*Cursor, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	defer session.resetStatement()
+
+	if session.statement.LastError != nil {
+		return nil, session.statement.LastError
+	}
+
+	sliceValue := reflect.Indirect(reflect.ValueOf(rowsSlicePtr))
+	if sliceValue.Kind() != reflect.Slice || !sliceValue.CanSet() {
+		return nil, ErrPtrSliceType
+	}
+	elemType := sliceValue.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct || size <= 0 {
+		return nil, ErrParamsType
+	}
+
+	if session.statement.RefTable == nil {
+		if err := session.statement.SetRefValue(reflect.New(elemType)); err != nil {
+			return nil, err
+		}
+	}
+	keyset, err := session.statement.Keyset(true)
+	if err != nil {
+		return nil, err
+	}
+	if cursor != nil {
+		values, err := cursor.keysetValues(keyset)
+		if err != nil {
+			return nil, err
+		}
+		session.statement.After(keyset, values)
+	}
+
+	// one more row is queried to know whether there is a next page
+	start := sliceValue.Len()
+	if err := session.NoCache().Limit(size+1).find(rowsSlicePtr, condiBean...); err != nil {
+		return nil, err
+	}
+	if sliceValue.Len()-start <= size {
+		return nil, nil
+	}
+	sliceValue.Set(sliceValue.Slice(0, start+size))
+
+	values, err := session.statement.KeysetValues(keyset, sliceValue.Index(start+size-1))
+	if err != nil {
+		return nil, err
+	}
+	return newCursor(keyset, values)
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+
+	"xorm.io/xorm/internal/statements"
+	"xorm.io/xorm/internal/utils"
+)
+
+var (
+	// ErrReturningNotSupported represents the database cannot return the rows of insert, update or delete
+	ErrReturningNotSupported = statements.ErrReturningNotSupported
+	// ErrReturningMap represents Returning cannot be used when inserting maps
+	ErrReturningMap = errors.New("Returning cannot be used when inserting maps")
+)
+
+// Returning makes the following insert, update or delete return the columns of the changed
+// rows and assigns them to the beans, so the values computed by the database, i.e. defaults
+// and triggers, are read back without another query. All the columns will be returned if
+// no columns, and the auto increment column is always returned by insert. It's rendered as
+// RETURNING on Postgres and SQLite 3.35+, OUTPUT on MSSQL, and RETURNING INTO on Oracle and
+// Dameng which can return only one row. MySQL is not supported.
+func (session *Session) Returning(cols ...string) *Session {
+	session.statement.SetReturning(cols...)
+	return session
+}
+
+// ReturningInto appends the returned rows of the following update or delete to rowsSlicePtr,
+// which should be a pointer to a slice of structs or pointers to structs. All the columns will
+// be returned if Returning is not called.
+func (session *Session) ReturningInto(rowsSlicePtr interface{}) *Session {
+	session.statement.SetReturningDest(rowsSlicePtr)
+	return session
+}
+
+// execReturning executes the SQL with the returning clause, the returned rows are assigned
+// to the beans in order and appended to the returning destination. It returns the number of
+// the returned rows.
+func (session *Session) execReturning(sqlStr string, args []interface{}, cols []string, beans ...interface{}) (int64, error) {
+	var sliceValue reflect.Value
+	if dest := session.statement.Returning.Dest; dest != nil {
+		sliceValue = reflect.ValueOf(dest)
+		if sliceValue.Kind() != reflect.Ptr || sliceValue.Elem().Kind() != reflect.Slice {
+			return 0, ErrPtrSliceType
+		}
+		sliceValue = sliceValue.Elem()
+	}
+
+	if session.statement.IsReturningInto() {
+		if len(beans) != 1 || beans[0] == nil || sliceValue.IsValid() {
+			return 0, ErrReturningNotSupported
+		}
+		return session.execReturningInto(sqlStr, args, cols, beans[0])
+	}
+
+	rows, err := session.queryRows(sqlStr, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	fields, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for rows.Next() {
+		scanResults := make([]interface{}, len(fields))
+		for i := range scanResults {
+			var cell interface{}
+			scanResults[i] = &cell
+		}
+		if err := session.engine.scan(rows, fields, types, scanResults...); err != nil {
+			return 0, err
+		}
+
+		if int(count) < len(beans) && beans[count] != nil {
+			if err := session.returning2Bean(scanResults, fields, types, beans[count]); err != nil {
+				return 0, err
+			}
+		}
+		if sliceValue.IsValid() {
+			elemType := sliceValue.Type().Elem()
+			isPtr := elemType.Kind() == reflect.Ptr
+			if isPtr {
+				elemType = elemType.Elem()
+			}
+			newValue := reflect.New(elemType)
+			if err := session.returning2Bean(scanResults, fields, types, newValue.Interface()); err != nil {
+				return 0, err
+			}
+			if isPtr {
+				sliceValue.Set(reflect.Append(sliceValue, newValue))
+			} else {
+				sliceValue.Set(reflect.Append(sliceValue, newValue.Elem()))
+			}
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// execReturningInto executes the SQL with RETURNING INTO, the returned columns are assigned to
+// the fields of the bean by the driver directly.
+func (session *Session) execReturningInto(sqlStr string, args []interface{}, cols []string, bean interface{}) (int64, error) {
+	table := session.statement.RefTable
+	if table == nil {
+		return 0, ErrTableNotFound
+	}
+	for _, colName := range cols {
+		col := table.GetColumn(colName)
+		if col == nil {
+			return 0, ErrFieldIsNotExist{colName, table.Name}
+		}
+		fieldValue, err := col.ValueOf(bean)
+		if err != nil {
+			return 0, err
+		}
+		if fieldValue == nil || !fieldValue.CanAddr() {
+			return 0, ErrFieldIsNotValid{colName, table.Name}
+		}
+		args = append(args, sql.Out{Dest: fieldValue.Addr().Interface()})
+	}
+
+	res, err := session.exec(sqlStr, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// returning2Bean assigns the scanned values of a returned row to the bean
+func (session *Session) returning2Bean(scanResults []interface{}, fields []string, types []*sql.ColumnType, bean interface{}) error {
+	dataStruct := utils.ReflectValue(bean)
+	table, err := session.engine.tagParser.ParseWithCache(dataStruct)
+	if err != nil {
+		return err
+	}
+
+	columnsSchema := ParseColumnsSchema(fields, types, table)
+	for i, field := range columnsSchema.Fields {
+		col, fieldValue, err := getField(&dataStruct, table, field)
+		if _, ok := err.(ErrFieldIsNotExist); ok {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err := session.convertBeanField(col, fieldValue, scanResults[i], table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,10 +11,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 
 	"xorm.io/xorm/dialects"
 	"xorm.io/xorm/internal/utils"
+	"xorm.io/xorm/schemas"
 )
 
 // Ping test if database is ok
@@ -36,34 +38,80 @@ func (session *Session) CreateTable(bean interface{}) error {
 	return session.createTable(bean)
 }
 
-func (session *Session) createTable(bean interface{}) error {
+// createTable creates the table of the bean without the foreign keys of excludedFKs, they should
+// be added after the tables they reference created
+func (session *Session) createTable(bean interface{}, excludedFKs ...string) error {
 	if err := session.statement.SetRefBean(bean); err != nil {
 		return err
 	}
+	if session.statement.RefTable.IsView {
+		return session.createView(session.statement.TableName(), session.statement.RefTable)
+	}
+
+	seqSQL, sqlStr, err := session.genCreateTableSQL(bean, excludedFKs...)
+	if err != nil {
+		return err
+	}
+	if seqSQL != "" {
+		if _, err := session.exec(seqSQL); err != nil {
+			return err
+		}
+	}
+	if _, err := session.exec(sqlStr); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createView creates or replaces the view
+func (session *Session) createView(viewName string, view *schemas.Table) error {
+	for _, sqlStr := range session.engine.dialect.CreateViewSQL(viewName, view) {
+		if _, err := session.exec(sqlStr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// genCreateTableSQL returns the SQL to create the sequence of the table if
+// the dialect needs one, and the SQL to create the table without the foreign keys of excludedFKs
+func (session *Session) genCreateTableSQL(bean interface{}, excludedFKs ...string) (string, string, error) {
+	if err := session.statement.SetRefBean(bean); err != nil {
+		return "", "", err
+	}
 
 	session.statement.RefTable.StoreEngine = session.statement.StoreEngine
 	session.statement.RefTable.Charset = session.statement.Charset
 	tableName := session.statement.TableName()
 	refTable := session.statement.RefTable
+
+	var seqSQL string
 	if refTable.AutoIncrement != "" && session.engine.dialect.Features().AutoincrMode == dialects.SequenceAutoincrMode {
-		sqlStr, err := session.engine.dialect.CreateSequenceSQL(context.Background(), session.engine.db, utils.SeqName(tableName))
+		var err error
+		seqSQL, err = session.engine.dialect.CreateSequenceSQL(context.Background(), session.engine.db, utils.SeqName(tableName))
 		if err != nil {
-			return err
+			return "", "", err
 		}
-		if _, err := session.exec(sqlStr); err != nil {
-			return err
+	}
+
+	if len(excludedFKs) > 0 {
+		// the parsed table is cached, so a copy is modified
+		table := *refTable
+		table.ForeignKeys = make(map[string]*schemas.ForeignKey, len(refTable.ForeignKeys))
+		for name, fk := range refTable.ForeignKeys {
+			if utils.IndexSlice(excludedFKs, name) < 0 {
+				table.ForeignKeys[name] = fk
+			}
 		}
+		refTable = &table
 	}
 
 	sqlStr, _, err := session.engine.dialect.CreateTableSQL(context.Background(), session.engine.db, refTable, tableName)
 	if err != nil {
-		return err
-	}
-	if _, err := session.exec(sqlStr); err != nil {
-		return err
+		return "", "", err
 	}
-
-	return nil
+	return seqSQL, sqlStr, nil
 }
 
 // CreateIndexes create indexes
@@ -148,6 +196,25 @@ func (session *Session) DropTable(beanOrTableName interface{}) error {
 
 func (session *Session) dropTable(beanOrTableName interface{}) error {
 	tableName := session.engine.TableName(beanOrTableName)
+	view, err := session.getView(tableName)
+	if err != nil {
+		return err
+	}
+	if view != nil {
+		_, err = session.exec(session.engine.dialect.DropViewSQL(session.engine.TableName(tableName, true), view.IsMaterialized))
+		return err
+	}
+	if v := utils.ReflectValue(beanOrTableName); v.Kind() == reflect.Struct {
+		table, err := session.engine.tagParser.ParseWithCache(v)
+		if err != nil {
+			return err
+		}
+		// the view does not exist
+		if table.IsView {
+			return nil
+		}
+	}
+
 	sqlStr, checkIfExist := session.engine.dialect.DropTableSQL(session.engine.TableName(tableName, true))
 	if !checkIfExist {
 		exist, err := session.engine.dialect.IsTableExist(session.getQueryer(), session.ctx, tableName)
@@ -185,6 +252,111 @@ func (session *Session) dropTable(beanOrTableName interface{}) error {
 	return err
 }
 
+// getView returns the view in database, or nil if there is no view with the name
+func (session *Session) getView(viewName string) (*schemas.Table, error) {
+	tables, err := session.engine.dialect.GetTables(session.getQueryer(), session.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		if table.IsView && strings.EqualFold(table.Name, viewName) {
+			return table, nil
+		}
+	}
+	return nil, nil
+}
+
+// RefreshMaterializedView refreshes the data of a materialized view, it's only supported by Postgres.
+// If concurrently is true, the view will be refreshed without locking out concurrent selects,
+// which requires a unique index on the view.
+func (session *Session) RefreshMaterializedView(beanOrViewName interface{}, concurrently bool) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+
+	refresher, ok := session.engine.dialect.(interface {
+		RefreshMaterializedViewSQL(viewName string, concurrently bool) string
+	})
+	if !ok {
+		return ErrMaterializedViewUnsupported
+	}
+	_, err := session.exec(refresher.RefreshMaterializedViewSQL(session.engine.TableName(beanOrViewName, true), concurrently))
+	return err
+}
+
+func (session *Session) partitionDialect() (dialects.PartitionDialect, error) {
+	dialect, ok := session.engine.dialect.(dialects.PartitionDialect)
+	if !ok {
+		return nil, ErrPartitionUnsupported
+	}
+	return dialect, nil
+}
+
+// CreatePartition creates a new partition of the partitioned table, it's only supported by Postgres and MySQL
+func (session *Session) CreatePartition(beanOrTableName interface{}, partition *schemas.Partition) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+
+	dialect, err := session.partitionDialect()
+	if err != nil {
+		return err
+	}
+	_, err = session.exec(dialect.CreatePartitionSQL(session.engine.TableName(beanOrTableName, true), partition))
+	return err
+}
+
+// AttachPartition attaches the table named by the partition to the partitioned table with the bound
+// of the partition, it's only supported by Postgres and MySQL
+func (session *Session) AttachPartition(beanOrTableName interface{}, partition *schemas.Partition) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+
+	dialect, err := session.partitionDialect()
+	if err != nil {
+		return err
+	}
+	for _, sqlStr := range dialect.AttachPartitionSQL(session.engine.TableName(beanOrTableName, true), partition) {
+		if _, err := session.exec(sqlStr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DetachPartition detaches the partition from the partitioned table, the data of the partition
+// will be kept in a table named by the partition. It's only supported by Postgres and MySQL
+func (session *Session) DetachPartition(beanOrTableName interface{}, partitionName string) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+
+	dialect, err := session.partitionDialect()
+	if err != nil {
+		return err
+	}
+	for _, sqlStr := range dialect.DetachPartitionSQL(session.engine.TableName(beanOrTableName, true), partitionName) {
+		if _, err := session.exec(sqlStr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPartitions returns the partitions of the partitioned table, it's only supported by Postgres and MySQL
+func (session *Session) GetPartitions(beanOrTableName interface{}) ([]*schemas.Partition, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+
+	dialect, err := session.partitionDialect()
+	if err != nil {
+		return nil, err
+	}
+	return dialect.GetPartitions(session.getQueryer(), session.ctx, session.engine.TableName(beanOrTableName))
+}
+
 // IsTableExist if a table is exist
 func (session *Session) IsTableExist(beanOrTableName interface{}) (bool, error) {
 	if session.isAutoClose {
@@ -222,27 +394,6 @@ func (session *Session) isTableEmpty(tableName string) (bool, error) {
 	return total == 0, nil
 }
 
-func (session *Session) addColumn(colName string) error {
-	col := session.statement.RefTable.GetColumn(colName)
-	sql := session.engine.dialect.AddColumnSQL(session.statement.TableName(), col)
-	_, err := session.exec(sql)
-	return err
-}
-
-func (session *Session) addIndex(tableName, idxName string) error {
-	index := session.statement.RefTable.Indexes[idxName]
-	sqlStr := session.engine.dialect.CreateIndexSQL(tableName, index)
-	_, err := session.exec(sqlStr)
-	return err
-}
-
-func (session *Session) addUnique(tableName, uqeName string) error {
-	index := session.statement.RefTable.Indexes[uqeName]
-	sqlStr := session.engine.dialect.CreateIndexSQL(tableName, index)
-	_, err := session.exec(sqlStr)
-	return err
-}
-
 // ImportFile SQL DDL file
 func (session *Session) ImportFile(ddlPath string) ([]sql.Result, error) {
 	file, err := os.Open(ddlPath)
@@ -253,53 +404,19 @@ func (session *Session) ImportFile(ddlPath string) ([]sql.Result, error) {
 	return session.Import(file)
 }
 
-// Import SQL DDL from io.Reader
+// Import SQL DDL from io.Reader, the statements are split by semicolons which are not in quotes,
+// comments or dollar-quoted strings. A DELIMITER line changes the delimiter as MySQL client does.
+// The batches of MSSQL are split only by the GO lines.
 func (session *Session) Import(r io.Reader) ([]sql.Result, error) {
-	var (
-		results       []sql.Result
-		lastError     error
-		inSingleQuote bool
-		startComment  bool
-	)
+	var results []sql.Result
 
+	splitter := session.statementSplitter()
 	scanner := bufio.NewScanner(r)
-	semiColSpliter := func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-		if atEOF && len(data) == 0 {
-			return 0, nil, nil
-		}
-		oriInSingleQuote := inSingleQuote
-		for i, b := range data {
-			if startComment {
-				if b == '\n' {
-					startComment = false
-				}
-			} else {
-				if !inSingleQuote && i > 0 && data[i-1] == '-' && data[i] == '-' {
-					startComment = true
-					continue
-				}
-
-				if b == '\'' {
-					inSingleQuote = !inSingleQuote
-				}
-				if !inSingleQuote && b == ';' {
-					return i + 1, data[0:i], nil
-				}
-			}
-		}
-		// If we're at EOF, we have a final, non-terminated line. Return it.
-		if atEOF {
-			return len(data), data, nil
-		}
-		inSingleQuote = oriInSingleQuote
-		// Request more data.
-		return 0, nil, nil
-	}
-
-	scanner.Split(semiColSpliter)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxImportStatementSize)
+	scanner.Split(splitter.Split)
 
 	for scanner.Scan() {
-		query := strings.Trim(scanner.Text(), " \t\n\r")
+		query := scanner.Text()
 		if len(query) > 0 {
 			result, err := session.Exec(query)
 			if err != nil {
@@ -309,7 +426,24 @@ func (session *Session) Import(r io.Reader) ([]sql.Result, error) {
 		}
 	}
 
-	return results, lastError
+	return results, scanner.Err()
+}
+
+// maxImportStatementSize is the max size of a statement to import
+const maxImportStatementSize = 64 * 1024 * 1024
+
+// statementSplitter returns a splitter of SQL statements for the dialect of the session
+func (session *Session) statementSplitter() *utils.StatementSplitter {
+	splitter := &utils.StatementSplitter{}
+	switch session.engine.dialect.URI().DBType {
+	case schemas.MYSQL:
+		splitter.BackslashEscapes = true
+	case schemas.POSTGRES:
+		splitter.DollarQuotes = true
+	case schemas.MSSQL:
+		splitter.BatchSeparator = "GO"
+	}
+	return splitter
 }
 
 func (session *Session) IndexHint(op, forType, indexerOrColName string) *Session {
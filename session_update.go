@@ -93,6 +93,9 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 		if err := session.statement.SetRefBean(bean); err != nil {
 			return 0, err
 		}
+		if session.statement.RefTable.IsView {
+			return 0, ErrViewReadOnly
+		}
 
 		if len(session.statement.TableName()) == 0 {
 			return 0, ErrTableNotFound
@@ -102,7 +105,11 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 			colNames, args, err = session.statement.BuildUpdates(v, false, false,
 				false, false, true)
 		} else {
-			colNames, args, err = session.genUpdateColumns(bean)
+			var cols []string
+			cols, args, err = session.genUpdateColumns(bean)
+			for _, col := range cols {
+				colNames = append(colNames, session.engine.Quote(col)+" = ?")
+			}
 		}
 		if err != nil {
 			return 0, err
@@ -193,13 +200,33 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 	tableName := session.statement.TableName() // table name must been get before exec because statement will be reset
 	useCache := session.statement.UseCache
 
-	res, err := session.exec(updateWriter.String(), updateWriter.Args()...)
-	if err != nil {
-		return 0, err
-	} else if doIncVer {
-		if verValue != nil && verValue.IsValid() && verValue.CanSet() {
+	var affected int64
+	if session.statement.IsReturning() {
+		returningCols := session.statement.ReturningColumns()
+		var beans []interface{}
+		if isStruct && reflect.ValueOf(bean).Kind() == reflect.Ptr {
+			beans = append(beans, bean)
+		}
+		if affected, err = session.execReturning(updateWriter.String(), updateWriter.Args(), returningCols, beans...); err != nil {
+			return 0, err
+		}
+		// the version column has been assigned if it's returned
+		if doIncVer && affected > 0 && verValue != nil && verValue.IsValid() && verValue.CanSet() &&
+			utils.IndexSlice(returningCols, table.Version) < 0 {
 			session.incrVersionFieldValue(verValue)
 		}
+	} else {
+		res, err := session.exec(updateWriter.String(), updateWriter.Args()...)
+		if err != nil {
+			return 0, err
+		} else if doIncVer {
+			if verValue != nil && verValue.IsValid() && verValue.CanSet() {
+				session.incrVersionFieldValue(verValue)
+			}
+		}
+		if affected, err = res.RowsAffected(); err != nil {
+			return 0, err
+		}
 	}
 
 	if cacher := session.engine.GetCacher(tableName); cacher != nil && useCache {
@@ -237,9 +264,10 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 	cleanupProcessorsClosures(&session.afterClosures) // cleanup after used
 	// --
 
-	return res.RowsAffected()
+	return affected, nil
 }
 
+// genUpdateColumns returns the names of the specified columns to be updated and the values
 func (session *Session) genUpdateColumns(bean interface{}) ([]string, []interface{}, error) {
 	table := session.statement.RefTable
 	colNames := make([]string, 0, len(table.ColumnsSeq()))
@@ -251,7 +279,7 @@ func (session *Session) genUpdateColumns(bean interface{}) ([]string, []interfac
 				continue
 			}
 		}
-		if col.MapType == schemas.ONLYFROMDB {
+		if col.MapType == schemas.ONLYFROMDB || col.IsGenerated() {
 			continue
 		}
 
@@ -313,7 +341,7 @@ func (session *Session) genUpdateColumns(bean interface{}) ([]string, []interfac
 			args = append(args, arg)
 		}
 
-		colNames = append(colNames, session.engine.Quote(col.Name)+" = ?")
+		colNames = append(colNames, col.Name)
 	}
 	return colNames, args, nil
 }
@@ -0,0 +1,395 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"xorm.io/builder"
+	"xorm.io/xorm/convert"
+	"xorm.io/xorm/internal/statements"
+	"xorm.io/xorm/internal/utils"
+	"xorm.io/xorm/schemas"
+)
+
+// ErrUpdateMultiNoPrimaryKey represents the rows cannot be updated without the primary keys
+var ErrUpdateMultiNoPrimaryKey = statements.ErrUpdateMultiNoPrimaryKey
+
+// ErrUpdateMultiConflict represents the rows of some beans are not updated by UpdateMulti since
+// their versions are different from the beans or the rows are not found
+type ErrUpdateMultiConflict struct {
+	TableName string
+	// Beans are the beans whose rows are not updated and PKs are their primary keys
+	Beans []interface{}
+	PKs   []schemas.PK
+}
+
+func (e ErrUpdateMultiConflict) Error() string {
+	pks := make([]string, 0, len(e.PKs))
+	for _, pk := range e.PKs {
+		values := make([]string, 0, len(pk))
+		for _, v := range pk {
+			values = append(values, fmt.Sprint(v))
+		}
+		pks = append(pks, "("+strings.Join(values, ",")+")")
+	}
+	return fmt.Sprintf("UpdateMulti conflicts with the versions of the rows %s of table %s", strings.Join(pks, ", "), e.TableName)
+}
+
+// UpdateMulti updates the rows of the beans in the slice by their primary keys with one statement,
+// rowsSlicePtr should be a slice or a pointer to a slice of structs or pointers to structs. The
+// columns of every bean are chosen as Update does, i.e. non-empty fields or Cols, Omit and MustCols,
+// and the columns not chosen for a bean keep their values. The updated column is set to the same
+// time for all the rows. The statement is split into chunks if there are too many parameters for
+// the database.
+//
+// If the table has a version column, the rows are updated in a transaction only if all of them
+// have the same versions as the beans, and then the versions of the beans are increased. Otherwise
+// the transaction is rolled back and ErrUpdateMultiConflict is returned with the conflicting beans.
+// If the transaction of the session has begun, the versions are read before updating to find the
+// conflicting beans, and the transaction should be rolled back by the caller on the conflict.
+func (session *Session) UpdateMulti(rowsSlicePtr interface{}) (int64, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+
+	// the statement is used by all the chunks
+	session.autoResetStatement = false
+	defer func() {
+		session.autoResetStatement = true
+		session.resetStatement()
+	}()
+
+	if session.statement.LastError != nil {
+		return 0, session.statement.LastError
+	}
+
+	sliceValue := reflect.Indirect(reflect.ValueOf(rowsSlicePtr))
+	if sliceValue.Kind() != reflect.Slice {
+		return 0, ErrPtrSliceType
+	}
+	size := sliceValue.Len()
+	if size == 0 {
+		return 0, ErrNoElementsOnSlice
+	}
+
+	beans := make([]interface{}, 0, size)
+	for i := 0; i < size; i++ {
+		elemValue := sliceValue.Index(i)
+		if elemValue.Kind() == reflect.Interface {
+			elemValue = elemValue.Elem()
+		}
+		if elemValue.Kind() != reflect.Ptr {
+			if !elemValue.CanAddr() {
+				return 0, ErrParamsType
+			}
+			elemValue = elemValue.Addr()
+		}
+		if elemValue.IsNil() || elemValue.Elem().Kind() != reflect.Struct {
+			return 0, ErrParamsType
+		}
+		beans = append(beans, elemValue.Interface())
+	}
+
+	if err := session.statement.SetRefBean(beans[0]); err != nil {
+		return 0, err
+	}
+	table := session.statement.RefTable
+	if table.IsView {
+		return 0, ErrViewReadOnly
+	}
+	if len(session.statement.TableName()) == 0 {
+		return 0, ErrTableNotFound
+	}
+	if len(table.PrimaryKeys) == 0 {
+		return 0, ErrUpdateMultiNoPrimaryKey
+	}
+
+	// the updated column is set to the same time for all the rows
+	var colNames []string
+	var args []interface{}
+	hasUpdated := session.statement.UseAutoTime && table.Updated != "" &&
+		!session.statement.OmitColumnMap.Contain(table.Updated)
+	if hasUpdated {
+		col := table.UpdatedColumn()
+		val, t, err := session.engine.nowTime(col)
+		if err != nil {
+			return 0, err
+		}
+		colNames = append(colNames, session.engine.Quote(table.Updated)+" = ?")
+		if session.engine.dialect.URI().DBType == schemas.ORACLE {
+			args = append(args, t)
+		} else {
+			args = append(args, val)
+		}
+
+		colName := col.Name
+		session.afterClosures = append(session.afterClosures, func(bean interface{}) {
+			col := table.GetColumn(colName)
+			setColumnTime(bean, col, t)
+		})
+	}
+
+	doIncVer := table.Version != "" && session.statement.CheckVersion
+	rows := make([]*statements.UpdateMultiRow, 0, size)
+	verValues := make([]*reflect.Value, 0, size)
+	var maxCols int
+	for _, bean := range beans {
+		// handle before update processors
+		for _, closure := range session.beforeClosures {
+			closure(bean)
+		}
+		if processor, ok := bean.(BeforeUpdateProcessor); ok {
+			processor.BeforeUpdate()
+		}
+
+		v := utils.ReflectValue(bean)
+		var cols []string
+		var colArgs []interface{}
+		var err error
+		if session.statement.ColumnStr() == "" {
+			cols, colArgs, err = session.statement.BuildUpdateColumns(v, false, false, false, false, true)
+		} else {
+			// the closures of the updated column have been added
+			lenAfterClosures := len(session.afterClosures)
+			cols, colArgs, err = session.genUpdateColumns(bean)
+			session.afterClosures = session.afterClosures[:lenAfterClosures]
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		row := &statements.UpdateMultiRow{
+			Cols: make([]string, 0, len(cols)),
+			Args: make([]interface{}, 0, len(cols)),
+		}
+		for i, colName := range cols {
+			col := table.GetColumn(colName)
+			if col.IsPrimaryKey || (col.IsVersion && doIncVer) || (col.IsUpdated && hasUpdated) {
+				continue
+			}
+			row.Cols = append(row.Cols, colName)
+			row.Args = append(row.Args, colArgs[i])
+		}
+		if len(row.Cols) > maxCols {
+			maxCols = len(row.Cols)
+		}
+
+		for _, col := range table.PKColumns() {
+			pkValue, err := col.ValueOfV(&v)
+			if err != nil {
+				return 0, err
+			}
+			if utils.IsValueZero(*pkValue) {
+				return 0, ErrUpdateMultiNoPrimaryKey
+			}
+			row.PK = append(row.PK, pkValue.Interface())
+		}
+
+		if doIncVer {
+			verValue, err := table.VersionColumn().ValueOfV(&v)
+			if err != nil {
+				return 0, err
+			}
+			row.Version = verValue.Interface()
+			verValues = append(verValues, verValue)
+		}
+		rows = append(rows, row)
+	}
+	cleanupProcessorsClosures(&session.beforeClosures) // cleanup after used
+
+	if err := session.statement.ProcessIDParam(); err != nil {
+		return 0, err
+	}
+	cond := session.statement.Conds()
+	if col := table.DeletedColumn(); col != nil && !session.statement.GetUnscoped() { // tag "deleted" is enabled
+		cond = cond.And(session.statement.CondDeleted(col))
+	}
+
+	// the rows are updated by chunks in one transaction if there are too many parameters
+	paramsPerRow := len(table.PrimaryKeys)*(maxCols+1) + maxCols
+	if doIncVer {
+		paramsPerRow++
+	}
+	chunkSize := session.insertChunkSize(paramsPerRow)
+	var needCommit bool
+	if (chunkSize < size || doIncVer) && session.isAutoCommit {
+		if err := session.Begin(); err != nil {
+			return 0, err
+		}
+		needCommit = true
+	}
+
+	var affected int64
+	for start := 0; start < size; start += chunkSize {
+		end := start + chunkSize
+		if end > size {
+			end = size
+		}
+
+		// the updated rows cannot be rolled back in the transaction of the caller, so the versions
+		// are read before updating to find the conflicting beans
+		var versions map[string]int64
+		if doIncVer && !needCommit {
+			var err error
+			if versions, err = session.queryVersions(rows[start:end]); err != nil {
+				return 0, err
+			}
+		}
+
+		cnt, err := session.updateMultiChunk(cond, rows[start:end], colNames, args)
+		if err != nil {
+			if needCommit {
+				_ = session.Rollback()
+			}
+			return 0, err
+		}
+		if doIncVer && cnt < int64(end-start) {
+			if !needCommit {
+				return 0, session.updateMultiConflict(beans[start:end], rows[start:end], versions)
+			}
+			if err := session.Rollback(); err != nil {
+				return 0, err
+			}
+			if versions, err = session.queryVersions(rows[start:]); err != nil {
+				return 0, err
+			}
+			return 0, session.updateMultiConflict(beans[start:], rows[start:], versions)
+		}
+		affected += cnt
+	}
+	if needCommit {
+		if err := session.Commit(); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, verValue := range verValues {
+		if verValue != nil && verValue.IsValid() && verValue.CanSet() {
+			session.incrVersionFieldValue(verValue)
+		}
+	}
+
+	tableName := session.statement.TableName()
+	if cacher := session.engine.GetCacher(tableName); cacher != nil && session.statement.UseCache {
+		session.engine.logger.Debugf("[cache] clear table: %v", tableName)
+		cacher.ClearIds(tableName)
+		cacher.ClearBeans(tableName)
+	}
+
+	// handle after update processors
+	lenAfterClosures := len(session.afterClosures)
+	for _, bean := range beans {
+		if session.isAutoCommit {
+			for _, closure := range session.afterClosures {
+				closure(bean)
+			}
+			if processor, ok := bean.(AfterUpdateProcessor); ok {
+				processor.AfterUpdate()
+			}
+		} else {
+			if lenAfterClosures > 0 {
+				if value, has := session.afterUpdateBeans[bean]; has && value != nil {
+					*value = append(*value, session.afterClosures...)
+				} else {
+					afterClosures := make([]func(interface{}), lenAfterClosures)
+					copy(afterClosures, session.afterClosures)
+					session.afterUpdateBeans[bean] = &afterClosures
+				}
+			} else {
+				if _, ok := bean.(AfterUpdateProcessor); ok {
+					session.afterUpdateBeans[bean] = nil
+				}
+			}
+		}
+	}
+	cleanupProcessorsClosures(&session.afterClosures) // cleanup after used
+	// --
+
+	return affected, nil
+}
+
+// updateMultiChunk updates a chunk of the rows with one statement
+func (session *Session) updateMultiChunk(cond builder.Cond, rows []*statements.UpdateMultiRow, colNames []string, args []interface{}) (int64, error) {
+	updateWriter := builder.NewWriter()
+	if err := session.statement.WriteUpdateMulti(updateWriter, cond, rows, colNames, args); err != nil {
+		return 0, err
+	}
+
+	res, err := session.exec(updateWriter.String(), updateWriter.Args()...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// queryVersions returns the versions of the rows by their primary keys
+func (session *Session) queryVersions(rows []*statements.UpdateMultiRow) (map[string]int64, error) {
+	table := session.statement.RefTable
+	quote := session.engine.Quote
+
+	cols := make([]string, 0, len(table.PrimaryKeys)+1)
+	for _, pk := range table.PrimaryKeys {
+		cols = append(cols, quote(pk))
+	}
+	cols = append(cols, quote(table.Version))
+	conds := make([]builder.Cond, 0, len(rows))
+	for _, row := range rows {
+		eq := make(builder.Eq, len(table.PrimaryKeys))
+		for j, pk := range table.PrimaryKeys {
+			eq[quote(pk)] = row.PK[j]
+		}
+		conds = append(conds, eq)
+	}
+	sqlStr, args, err := builder.Select(cols...).From(quote(session.statement.TableName())).Where(builder.Or(conds...)).ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := session.queryRows(sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	versions := make(map[string]int64, len(rows))
+	for rs.Next() {
+		pk := make([]string, len(table.PrimaryKeys))
+		dest := make([]interface{}, 0, len(cols))
+		for i := range pk {
+			dest = append(dest, &pk[i])
+		}
+		var version int64
+		dest = append(dest, &version)
+		if err := rs.Scan(dest...); err != nil {
+			return nil, err
+		}
+		versions[strings.Join(pk, ",")] = version
+	}
+	return versions, rs.Err()
+}
+
+// updateMultiConflict returns ErrUpdateMultiConflict with the beans whose rows are not found or
+// have different versions from the beans
+func (session *Session) updateMultiConflict(beans []interface{}, rows []*statements.UpdateMultiRow, versions map[string]int64) error {
+	conflict := ErrUpdateMultiConflict{TableName: session.statement.TableName()}
+	for i, row := range rows {
+		pk := make([]string, 0, len(row.PK))
+		for _, v := range row.PK {
+			pk = append(pk, fmt.Sprint(v))
+		}
+		expected, err := convert.AsInt64(row.Version)
+		if err != nil {
+			return err
+		}
+		if version, ok := versions[strings.Join(pk, ",")]; !ok || version != expected {
+			conflict.Beans = append(conflict.Beans, beans[i])
+			conflict.PKs = append(conflict.PKs, schemas.PK(row.PK))
+		}
+	}
+	return conflict
+}
@@ -5,8 +5,12 @@
 package xorm
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"xorm.io/xorm/dialects"
 	"xorm.io/xorm/internal/utils"
 	"xorm.io/xorm/schemas"
 )
@@ -19,9 +23,184 @@ type SyncOptions struct {
 	IgnoreIndices bool
 	// IgnoreDropIndices will not delete indices
 	IgnoreDropIndices bool
+	// DryRun will only generate the actions into SyncResult but not execute them
+	DryRun bool
+	// ReconcileColumns will alter the nullability, default value and safely widen
+	// the type of existing columns to match the struct, otherwise they are only warned.
+	// SQLite tables will be rebuilt since the columns cannot be altered in place.
+	ReconcileColumns bool
+	// DropMissingColumns will drop the columns which exist in database but have no related struct field
+	DropMissingColumns bool
+	// DropColumns is the allow list of the columns could be dropped when DropMissingColumns,
+	// the items could be "column" or "table.column", empty means all the missing columns
+	DropColumns []string
+	// ConfirmDropColumn will be invoked before dropping a missing column if it's not nil,
+	// the column will be kept if it returns false
+	ConfirmDropColumn func(tableName, colName string) bool
+	// DropForeignKeys will drop the foreign keys named by xorm which are not in the struct any more
+	// or changed, the foreign keys not named by xorm are always kept.
+	// SQLite tables will be rebuilt since the foreign keys cannot be dropped in place.
+	DropForeignKeys bool
+	// DropChecks will drop the CHECK constraints named by xorm which are not in the struct any more,
+	// the CHECK constraints not named by xorm are always kept.
+	// SQLite tables will be rebuilt since the constraints cannot be dropped in place.
+	DropChecks bool
+	// LockName is the name of a lock held while syncing if it's not empty, so that the syncs
+	// of the replicas started at the same time will run one by one. See Engine.Lock
+	LockName string
+	// LockTimeout is the max duration to wait for the lock, zero means waiting until the context is done
+	LockTimeout time.Duration
 }
 
-type SyncResult struct{}
+// SyncActionType represents the kind of a schema change
+type SyncActionType int
+
+// enumerates all the schema changes Sync could make
+const (
+	SyncCreateSequence SyncActionType = iota + 1
+	SyncCreateTable
+	SyncAddColumn
+	SyncModifyColumn
+	SyncAlterColumnType
+	SyncAlterColumnNullable
+	SyncAlterColumnDefault
+	SyncDropColumn
+	SyncRebuildTable
+	SyncCreateIndex
+	SyncDropIndex
+	SyncAddForeignKey
+	SyncDropForeignKey
+	SyncAddCheck
+	SyncDropCheck
+	SyncRenameTable
+	SyncRenameColumn
+	SyncCreateView
+)
+
+var syncActionTypeNames = map[SyncActionType]string{
+	SyncCreateSequence:      "CREATE SEQUENCE",
+	SyncCreateTable:         "CREATE TABLE",
+	SyncAddColumn:           "ADD COLUMN",
+	SyncModifyColumn:        "MODIFY COLUMN",
+	SyncAlterColumnType:     "ALTER COLUMN TYPE",
+	SyncAlterColumnNullable: "ALTER COLUMN NULLABLE",
+	SyncAlterColumnDefault:  "ALTER COLUMN DEFAULT",
+	SyncDropColumn:          "DROP COLUMN",
+	SyncRebuildTable:        "REBUILD TABLE",
+	SyncCreateIndex:         "CREATE INDEX",
+	SyncDropIndex:           "DROP INDEX",
+	SyncAddForeignKey:       "ADD FOREIGN KEY",
+	SyncDropForeignKey:      "DROP FOREIGN KEY",
+	SyncAddCheck:            "ADD CHECK",
+	SyncDropCheck:           "DROP CHECK",
+	SyncRenameTable:         "RENAME TABLE",
+	SyncRenameColumn:        "RENAME COLUMN",
+	SyncCreateView:          "CREATE VIEW",
+}
+
+func (t SyncActionType) String() string {
+	if name, ok := syncActionTypeNames[t]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// SyncAction represents one schema change made by Sync, or planned when DryRun
+type SyncAction struct {
+	Type   SyncActionType
+	Table  string
+	Column string // blank if the action is not on a column
+	Index  string // blank if the action is not on an index
+	// Constraint is the name of the constraint, blank if the action is not on a constraint
+	Constraint string
+	// OldDefinition is the definition in the database, blank if the object is new
+	OldDefinition string
+	// NewDefinition is the definition from the struct, blank if the object is dropped
+	NewDefinition string
+	SQL           string
+	// RevertSQL undoes the action, blank if the action cannot be reverted automatically,
+	// i.e. the table is rebuilt or the view is replaced
+	RevertSQL string
+}
+
+// SyncResult represents all the schema changes of a sync in order
+type SyncResult struct {
+	Actions []*SyncAction
+}
+
+func (result *SyncResult) add(action *SyncAction) {
+	result.Actions = append(result.Actions, action)
+}
+
+// SQLs returns all the SQLs of the actions in order
+func (result *SyncResult) SQLs() []string {
+	sqls := make([]string, 0, len(result.Actions))
+	for _, action := range result.Actions {
+		sqls = append(sqls, action.SQL)
+	}
+	return sqls
+}
+
+// RevertSQLs returns the SQLs to undo the actions in the reverse order, and the actions which
+// cannot be reverted automatically
+func (result *SyncResult) RevertSQLs() ([]string, []*SyncAction) {
+	var sqls []string
+	var irreversible []*SyncAction
+	for i := len(result.Actions) - 1; i >= 0; i-- {
+		action := result.Actions[i]
+		if action.RevertSQL == "" {
+			irreversible = append(irreversible, action)
+		} else {
+			sqls = append(sqls, action.RevertSQL)
+		}
+	}
+	return sqls, irreversible
+}
+
+func columnDefinition(dialect dialects.Dialect, col *schemas.Column) string {
+	s, _ := dialects.ColumnString(dialect, col, false, false)
+	return s
+}
+
+func indexDefinition(index *schemas.Index) string {
+	var prefix string
+	if index.Type == schemas.UniqueType {
+		prefix = "UNIQUE"
+	} else {
+		prefix = "INDEX"
+	}
+	keys := make([]string, 0, len(index.Cols))
+	for _, col := range index.Cols {
+		if index.IsDesc(col) {
+			col += " DESC"
+		}
+		keys = append(keys, col)
+	}
+	def := prefix + "(" + strings.Join(keys, ",") + ")"
+	if index.Method != "" {
+		def += " USING " + index.Method
+	}
+	if index.Where != "" {
+		def += " WHERE " + index.Where
+	}
+	return def
+}
+
+func foreignKeyDefinition(dialect dialects.Dialect, tableName string, fk *schemas.ForeignKey) string {
+	return dialects.ForeignKeyString(dialect, tableName, fk)
+}
+
+// revertForeignKeySQL returns the SQL to add the dropped foreign key, blank if unsupported
+func revertForeignKeySQL(dialect dialects.Dialect, tableName string, fk *schemas.ForeignKey) string {
+	sql, _ := dialect.AddForeignKeySQL(tableName, fk)
+	return sql
+}
+
+// revertCheckSQL returns the SQL to add the dropped check, blank if unsupported
+func revertCheckSQL(dialect dialects.Dialect, tableName string, check *schemas.Check) string {
+	sql, _ := dialect.AddCheckSQL(tableName, check)
+	return sql
+}
 
 // Sync the new struct changes to database, this method will automatically add
 // table, column, index, unique. but will not delete or change anything.
@@ -62,6 +241,9 @@ func (session *Session) Sync(beans ...interface{}) error {
 	return err
 }
 
+// SyncWithOptions sync the database schemas according options and table structs.
+// All the changes are returned in SyncResult, if opts.DryRun is true, they will
+// not be executed.
 func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{}) (*SyncResult, error) {
 	engine := session.engine
 
@@ -70,6 +252,14 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 		defer session.Close()
 	}
 
+	if opts.LockName != "" {
+		unlock, err := engine.lockWithTimeout(session.ctx, opts.LockName, opts.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
 	tables, err := engine.dialect.GetTables(session.getQueryer(), session.ctx)
 	if err != nil {
 		return nil, err
@@ -83,7 +273,15 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 
 	var syncResult SyncResult
 
-	for _, bean := range beans {
+	// the referenced tables should be synchronized before the tables reference them
+	beans, circularFKs, err := engine.sortBeansByDependencies(beans)
+	if err != nil {
+		return nil, err
+	}
+
+	// the circular foreign keys of the new tables are added after all the tables synchronized
+	var circularFKActions []*SyncAction
+	for i, bean := range beans {
 		v := utils.ReflectValue(bean)
 		table, err := engine.tagParser.ParseWithCache(v)
 		if err != nil {
@@ -105,34 +303,51 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 			}
 		}
 
-		// this is a new table
-		if oriTable == nil {
-			err = session.StoreEngine(session.statement.StoreEngine).createTable(bean)
-			if err != nil {
+		// views are only replaced when the definitions are changed
+		if table.IsView {
+			if err = session.syncView(opts, &syncResult, tbName, table, oriTable); err != nil {
 				return nil, err
 			}
+			continue
+		}
+		if oriTable != nil && oriTable.IsView {
+			return nil, fmt.Errorf("%s is a view in database but not in the struct", tbName)
+		}
+		// the partitions have the same columns with the partitioned tables
+		if oriTable != nil && oriTable.PartitionOf != "" {
+			engine.logger.Warnf("Table %s is a partition of %s and will not be synchronized", tbName, oriTable.PartitionOf)
+			continue
+		}
 
-			if !opts.IgnoreConstrains {
-				err = session.createUniques(bean)
-				if err != nil {
-					return nil, err
-				}
+		if oriTable != nil {
+			// this will modify an old table
+			if err = engine.loadTableInfo(session.ctx, oriTable); err != nil {
+				return nil, err
 			}
+		} else if table.RenamedFrom != "" {
+			// this may be a renamed table
+			if oriTable, err = session.syncRenamedTable(opts, &syncResult, tables, tbName, table.RenamedFrom); err != nil {
+				return nil, err
+			}
+		}
 
-			if !opts.IgnoreIndices {
-				err = session.createIndexes(bean)
+		// this is a new table
+		if oriTable == nil {
+			if err = session.syncNewTable(opts, &syncResult, bean, table, circularFKs[i]); err != nil {
+				return nil, err
+			}
+			for _, name := range circularFKs[i] {
+				action, err := addForeignKeyAction(engine.dialect, tbNameWithSchema, table.ForeignKeys[name])
 				if err != nil {
 					return nil, err
 				}
+				circularFKActions = append(circularFKActions, action)
 			}
-
 			continue
 		}
 
-		// this will modify an old table
-		if err = engine.loadTableInfo(session.ctx, oriTable); err != nil {
-			return nil, err
-		}
+		// columns cannot be altered in place and need the table to be rebuilt
+		alteredCols := make(map[string]*schemas.Column)
 
 		// check columns
 		for _, col := range table.Columns() {
@@ -144,20 +359,61 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 				}
 			}
 
+			// column is renamed
+			if oriCol == nil && col.RenamedFrom != "" {
+				if oriCol = oriTable.GetColumn(col.RenamedFrom); oriCol != nil {
+					sql, revertSQL, err := session.renameColumnSQL(tbNameWithSchema, oriCol, col.Name)
+					if err != nil {
+						return nil, err
+					}
+					if err = session.applySyncAction(opts, &syncResult, &SyncAction{
+						Type:          SyncRenameColumn,
+						Table:         tbNameWithSchema,
+						Column:        col.Name,
+						OldDefinition: oriCol.Name,
+						NewDefinition: col.Name,
+						SQL:           sql,
+						RevertSQL:     revertSQL,
+					}); err != nil {
+						return nil, err
+					}
+					oriTable.RenameColumn(oriCol.Name, col.Name)
+				}
+			}
+
 			// column is not exist on table
 			if oriCol == nil {
-				session.statement.RefTable = table
-				session.statement.SetTableName(tbNameWithSchema)
-				if err = session.addColumn(col.Name); err != nil {
+				if err = session.applySyncAction(opts, &syncResult, &SyncAction{
+					Type:          SyncAddColumn,
+					Table:         tbNameWithSchema,
+					Column:        col.Name,
+					NewDefinition: columnDefinition(engine.dialect, col),
+					SQL:           engine.dialect.AddColumnSQL(tbNameWithSchema, col),
+					RevertSQL:     engine.dialect.DropColumnSQL(tbNameWithSchema, col.Name),
+				}); err != nil {
 					return nil, err
 				}
 				continue
 			}
 
-			err = nil
+			// generated columns are computed by the database and will not be modified
+			if col.IsGenerated() || oriCol.IsGenerated() {
+				if col.IsGenerated() != oriCol.IsGenerated() {
+					engine.logger.Warnf("Table %s column %s db generated is %q, struct generated is %q",
+						tbNameWithSchema, col.Name, oriCol.Generated, col.Generated)
+				}
+				continue
+			}
+
+			var needModify bool
 			expectedType := engine.dialect.SQLType(col)
 			curType := engine.dialect.SQLType(oriCol)
-			if expectedType != curType {
+			if opts.ReconcileColumns && expectedType != curType && isSafeTypeWidening(curType, expectedType, oriCol, col) {
+				if err = session.alterColumn(opts, &syncResult, SyncAlterColumnType, tbNameWithSchema, oriCol, col,
+					engine.dialect.AlterColumnTypeSQL, alteredCols); err != nil {
+					return nil, err
+				}
+			} else if expectedType != curType {
 				if expectedType == schemas.Text &&
 					strings.HasPrefix(curType, schemas.Varchar) {
 					// currently only support mysql & postgres
@@ -165,7 +421,7 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 						engine.dialect.URI().DBType == schemas.POSTGRES {
 						engine.logger.Infof("Table %s column %s change type from %s to %s\n",
 							tbNameWithSchema, col.Name, curType, expectedType)
-						_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
+						needModify = true
 					} else {
 						engine.logger.Warnf("Table %s column %s db type is %s, struct type is %s\n",
 							tbNameWithSchema, col.Name, curType, expectedType)
@@ -175,7 +431,7 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 						if oriCol.Length < col.Length {
 							engine.logger.Infof("Table %s column %s change type from varchar(%d) to varchar(%d)\n",
 								tbNameWithSchema, col.Name, oriCol.Length, col.Length)
-							_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
+							needModify = true
 						}
 					}
 				} else {
@@ -191,35 +447,87 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 					if oriCol.Length < col.Length {
 						engine.logger.Infof("Table %s column %s change type from varchar(%d) to varchar(%d)\n",
 							tbNameWithSchema, col.Name, oriCol.Length, col.Length)
-						_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
+						needModify = true
 					}
 				}
 			} else if col.Comment != oriCol.Comment {
 				if engine.dialect.URI().DBType == schemas.POSTGRES ||
 					engine.dialect.URI().DBType == schemas.MYSQL {
-					_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
+					needModify = true
+				}
+			}
+
+			if needModify {
+				if err = session.applySyncAction(opts, &syncResult, &SyncAction{
+					Type:          SyncModifyColumn,
+					Table:         tbNameWithSchema,
+					Column:        col.Name,
+					OldDefinition: columnDefinition(engine.dialect, oriCol),
+					NewDefinition: columnDefinition(engine.dialect, col),
+					SQL:           engine.dialect.ModifyColumnSQL(tbNameWithSchema, col),
+					RevertSQL:     engine.dialect.ModifyColumnSQL(tbNameWithSchema, oriCol),
+				}); err != nil {
+					return nil, err
 				}
 			}
 
-			if col.Default != oriCol.Default {
+			if col.Default != oriCol.Default || col.DefaultIsEmpty != oriCol.DefaultIsEmpty {
 				switch {
 				case col.IsAutoIncrement: // For autoincrement column, don't check default
 				case (col.SQLType.Name == schemas.Bool || col.SQLType.Name == schemas.Boolean) &&
 					((strings.EqualFold(col.Default, "true") && oriCol.Default == "1") ||
 						(strings.EqualFold(col.Default, "false") && oriCol.Default == "0")):
-				default:
+				case opts.ReconcileColumns:
+					if isDefaultChanged(oriCol, col) {
+						if err = session.alterColumn(opts, &syncResult, SyncAlterColumnDefault, tbNameWithSchema, oriCol, col,
+							engine.dialect.AlterColumnDefaultSQL, alteredCols); err != nil {
+							return nil, err
+						}
+					}
+				case col.Default != oriCol.Default:
 					engine.logger.Warnf("Table %s Column %s db default is %s, struct default is %s",
 						tbName, col.Name, oriCol.Default, col.Default)
 				}
 			}
 			if col.Nullable != oriCol.Nullable {
-				engine.logger.Warnf("Table %s Column %s db nullable is %v, struct nullable is %v",
-					tbName, col.Name, oriCol.Nullable, col.Nullable)
+				switch {
+				case opts.ReconcileColumns && !col.IsPrimaryKey:
+					if err = session.alterColumn(opts, &syncResult, SyncAlterColumnNullable, tbNameWithSchema, oriCol, col,
+						engine.dialect.AlterColumnNullableSQL, alteredCols); err != nil {
+						return nil, err
+					}
+				default:
+					engine.logger.Warnf("Table %s Column %s db nullable is %v, struct nullable is %v",
+						tbName, col.Name, oriCol.Nullable, col.Nullable)
+				}
 			}
+		}
 
+		var addedFKs []*schemas.ForeignKey
+		var addedChecks []*schemas.Check
+		needRebuild := len(alteredCols) > 0
+		if !opts.IgnoreConstrains {
+			var rebuildFKs, rebuildChecks bool
+			addedFKs, rebuildFKs, err = session.syncDroppedForeignKeys(opts, &syncResult, tbNameWithSchema, table, oriTable)
 			if err != nil {
 				return nil, err
 			}
+			addedChecks, rebuildChecks, err = session.syncDroppedChecks(opts, &syncResult, tbNameWithSchema, table, oriTable)
+			if err != nil {
+				return nil, err
+			}
+			needRebuild = needRebuild || rebuildFKs || rebuildChecks
+		}
+
+		if opts.DropMissingColumns {
+			if err = session.dropMissingColumns(opts, &syncResult, tbNameWithSchema, table, oriTable, alteredCols, needRebuild); err != nil {
+				return nil, err
+			}
+		} else if needRebuild {
+			if err = session.rebuildTable(opts, &syncResult, tbNameWithSchema, oriTable,
+				newRebuildTable(oriTable, alteredCols, nil)); err != nil {
+				return nil, err
+			}
 		}
 
 		// indices found in orig table
@@ -244,8 +552,13 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 		}
 
 		// drop all indices that do not exist in new schema or have changed
-		for name2, index2 := range oriTable.Indexes {
+		for _, name2 := range sortedIndexNames(oriTable.Indexes) {
+			index2 := oriTable.Indexes[name2]
 			if _, ok := foundIndexNames[name2]; !ok {
+				// MySQL creates the indices for the foreign keys automatically
+				if _, ok := oriTable.ForeignKeys[name2]; ok {
+					continue
+				}
 				// ignore based on there type
 				if (index2.Type == schemas.IndexType && (opts.IgnoreIndices || opts.IgnoreDropIndices)) ||
 					(index2.Type == schemas.UniqueType && opts.IgnoreConstrains) {
@@ -254,31 +567,71 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 					continue
 				}
 
-				sql := engine.dialect.DropIndexSQL(tbNameWithSchema, index2)
-				_, err = session.exec(sql)
-				if err != nil {
+				if err = session.applySyncAction(opts, &syncResult, &SyncAction{
+					Type:          SyncDropIndex,
+					Table:         tbNameWithSchema,
+					Index:         name2,
+					OldDefinition: indexDefinition(index2),
+					SQL:           engine.dialect.DropIndexSQL(tbNameWithSchema, index2),
+					RevertSQL:     engine.dialect.CreateIndexSQL(tbNameWithSchema, index2),
+				}); err != nil {
 					return nil, err
 				}
 			}
 		}
 
 		// Add new indices because either they did not exist before or were dropped to update them
-		for name, index := range addedNames {
-			if index.Type == schemas.UniqueType && !opts.IgnoreConstrains {
-				session.statement.RefTable = table
-				session.statement.SetTableName(tbNameWithSchema)
-				err = session.addUnique(tbNameWithSchema, name)
-			} else if index.Type == schemas.IndexType && !opts.IgnoreIndices {
-				session.statement.RefTable = table
-				session.statement.SetTableName(tbNameWithSchema)
-				err = session.addIndex(tbNameWithSchema, name)
+		for _, name := range sortedIndexNames(addedNames) {
+			index := addedNames[name]
+			if (index.Type == schemas.UniqueType && opts.IgnoreConstrains) ||
+				(index.Type == schemas.IndexType && opts.IgnoreIndices) {
+				continue
+			}
+			action := &SyncAction{
+				Type:          SyncCreateIndex,
+				Table:         tbNameWithSchema,
+				Index:         name,
+				NewDefinition: indexDefinition(index),
+				SQL:           engine.dialect.CreateIndexSQL(tbNameWithSchema, index),
+				RevertSQL:     engine.dialect.DropIndexSQL(tbNameWithSchema, index),
 			}
+			if oriIndex, ok := oriTable.Indexes[name]; ok {
+				action.OldDefinition = indexDefinition(oriIndex)
+			}
+			if err = session.applySyncAction(opts, &syncResult, action); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, fk := range addedFKs {
+			action, err := addForeignKeyAction(engine.dialect, tbNameWithSchema, fk)
 			if err != nil {
 				return nil, err
 			}
+			if err = session.applySyncAction(opts, &syncResult, action); err != nil {
+				return nil, err
+			}
 		}
 
-		if opts.WarnIfDatabaseColumnMissed {
+		for _, check := range addedChecks {
+			sql, err := engine.dialect.AddCheckSQL(tbNameWithSchema, check)
+			if err != nil {
+				return nil, err
+			}
+			revertSQL, _ := engine.dialect.DropCheckSQL(tbNameWithSchema, check)
+			if err = session.applySyncAction(opts, &syncResult, &SyncAction{
+				Type:          SyncAddCheck,
+				Table:         tbNameWithSchema,
+				Constraint:    check.XName(tbNameWithSchema),
+				NewDefinition: check.Expr,
+				SQL:           sql,
+				RevertSQL:     revertSQL,
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if opts.WarnIfDatabaseColumnMissed && !opts.DropMissingColumns {
 			// check all the columns which removed from struct fields but left on database tables.
 			for _, colName := range oriTable.ColumnsSeq() {
 				if table.GetColumn(colName) == nil {
@@ -288,5 +641,632 @@ func (session *Session) SyncWithOptions(opts SyncOptions, beans ...interface{})
 		}
 	}
 
+	for _, action := range circularFKActions {
+		if err = session.applySyncAction(opts, &syncResult, action); err != nil {
+			return nil, err
+		}
+	}
+
 	return &syncResult, nil
 }
+
+// addForeignKeyAction returns the action to add the foreign key to the table
+func addForeignKeyAction(dialect dialects.Dialect, tableName string, fk *schemas.ForeignKey) (*SyncAction, error) {
+	sql, err := dialect.AddForeignKeySQL(tableName, fk)
+	if err != nil {
+		return nil, err
+	}
+	revertSQL, _ := dialect.DropForeignKeySQL(tableName, fk)
+	return &SyncAction{
+		Type:          SyncAddForeignKey,
+		Table:         tableName,
+		Constraint:    fk.XName(tableName),
+		NewDefinition: foreignKeyDefinition(dialect, tableName, fk),
+		SQL:           sql,
+		RevertSQL:     revertSQL,
+	}, nil
+}
+
+// syncView creates or replaces the view if its definition is changed,
+// a materialized view will be kept if it exists since it cannot be replaced
+func (session *Session) syncView(opts SyncOptions, syncResult *SyncResult, tbName string, view, oriView *schemas.Table) error {
+	var oldDefinition string
+	if oriView != nil {
+		if !oriView.IsView {
+			return fmt.Errorf("%s is a table in database but a view in the struct", tbName)
+		}
+		if view.IsMaterialized || viewDefinitionEqual(oriView.ViewDefinition, view.ViewDefinition) {
+			return nil
+		}
+		oldDefinition = oriView.ViewDefinition
+	}
+
+	sqls := session.engine.dialect.CreateViewSQL(tbName, view)
+	for i, sql := range sqls {
+		action := &SyncAction{
+			Type:          SyncCreateView,
+			Table:         session.engine.tbNameWithSchema(tbName),
+			OldDefinition: oldDefinition,
+			NewDefinition: view.ViewDefinition,
+			SQL:           sql,
+		}
+		// a replaced view cannot be reverted since only the definition of the old one is known
+		if oriView == nil && i == len(sqls)-1 {
+			action.RevertSQL = session.engine.dialect.DropViewSQL(tbName, view.IsMaterialized)
+		}
+		if err := session.applySyncAction(opts, syncResult, action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// viewDefinitionEqual compares the view definitions ignoring the case, spaces and quotes,
+// the databases may still rewrite the definitions so the view will be replaced if not equal
+func viewDefinitionEqual(def1, def2 string) bool {
+	trim := func(s string) string {
+		return schemas.NormalizeIndexExpr(strings.TrimRight(strings.TrimSpace(s), ";"))
+	}
+	return trim(def1) == trim(def2)
+}
+
+// syncNewTable creates the table of the bean with its indices, the circular foreign keys are not
+// created with the table
+func (session *Session) syncNewTable(opts SyncOptions, syncResult *SyncResult, bean interface{}, table *schemas.Table, circularFKs []string) error {
+	seqSQL, createTableSQL, err := session.StoreEngine(session.statement.StoreEngine).genCreateTableSQL(bean, circularFKs...)
+	if err != nil {
+		return err
+	}
+	tableName := session.statement.TableName()
+
+	if seqSQL != "" {
+		revertSQL, _ := session.engine.dialect.DropSequenceSQL(utils.SeqName(tableName))
+		if err := session.applySyncAction(opts, syncResult, &SyncAction{
+			Type:      SyncCreateSequence,
+			Table:     tableName,
+			SQL:       seqSQL,
+			RevertSQL: revertSQL,
+		}); err != nil {
+			return err
+		}
+	}
+
+	dropTableSQL, _ := session.engine.dialect.DropTableSQL(tableName)
+	if err := session.applySyncAction(opts, syncResult, &SyncAction{
+		Type:      SyncCreateTable,
+		Table:     tableName,
+		SQL:       createTableSQL,
+		RevertSQL: dropTableSQL,
+	}); err != nil {
+		return err
+	}
+
+	for _, name := range sortedIndexNames(table.Indexes) {
+		index := table.Indexes[name]
+		if (index.Type == schemas.UniqueType && opts.IgnoreConstrains) ||
+			(index.Type == schemas.IndexType && opts.IgnoreIndices) {
+			continue
+		}
+		if err := session.applySyncAction(opts, syncResult, &SyncAction{
+			Type:          SyncCreateIndex,
+			Table:         tableName,
+			Index:         name,
+			NewDefinition: indexDefinition(index),
+			SQL:           session.engine.dialect.CreateIndexSQL(tableName, index),
+			RevertSQL:     session.engine.dialect.DropIndexSQL(tableName, index),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the first versions of MySQL and MariaDB support ALTER TABLE RENAME COLUMN
+var (
+	mysqlRenameColumnVersion   = []int{8, 0, 0}
+	mariaDBRenameColumnVersion = []int{10, 5, 2}
+)
+
+// renameColumnSQL returns the SQL to rename the column of the table and the one to revert it. MySQL
+// before 8.0 and MariaDB before 10.5.2 have no RENAME COLUMN, the column is renamed by CHANGE COLUMN
+// with its definition in database.
+func (session *Session) renameColumnSQL(tableName string, oriCol *schemas.Column, newName string) (string, string, error) {
+	dialect := session.engine.dialect
+	if changer, ok := dialect.(interface {
+		ChangeColumnSQL(tableName, oldName string, col *schemas.Column) string
+	}); ok {
+		version, err := dialect.Version(session.ctx, session.getQueryer())
+		if err != nil {
+			return "", "", err
+		}
+		renameVersion := mysqlRenameColumnVersion
+		if strings.EqualFold(version.Edition, "MariaDB") {
+			renameVersion = mariaDBRenameColumnVersion
+		}
+		if version.Edition != "TiDB" && versionLessThan(version.Number, renameVersion) {
+			newCol := *oriCol
+			newCol.Name = newName
+			return changer.ChangeColumnSQL(tableName, oriCol.Name, &newCol),
+				changer.ChangeColumnSQL(tableName, newName, oriCol), nil
+		}
+	}
+	return dialect.RenameColumnSQL(tableName, oriCol.Name, newName),
+		dialect.RenameColumnSQL(tableName, newName, oriCol.Name), nil
+}
+
+// syncRenamedTable renames the table from oldName if it exists and returns it with the new name,
+// or returns nil if there is no such table.
+func (session *Session) syncRenamedTable(opts SyncOptions, syncResult *SyncResult, tables []*schemas.Table, tbName, oldName string) (*schemas.Table, error) {
+	engine := session.engine
+
+	var oriTable *schemas.Table
+	for _, tb := range tables {
+		if strings.EqualFold(engine.tbNameWithSchema(tb.Name), engine.tbNameWithSchema(oldName)) {
+			oriTable = tb
+			break
+		}
+	}
+	if oriTable == nil {
+		return nil, nil
+	}
+	if err := engine.loadTableInfo(session.ctx, oriTable); err != nil {
+		return nil, err
+	}
+
+	if err := session.applySyncAction(opts, syncResult, &SyncAction{
+		Type:          SyncRenameTable,
+		Table:         engine.tbNameWithSchema(tbName),
+		OldDefinition: oriTable.Name,
+		NewDefinition: tbName,
+		SQL:           engine.dialect.RenameTableSQL(engine.tbNameWithSchema(oriTable.Name), tbName),
+		RevertSQL:     engine.dialect.RenameTableSQL(engine.tbNameWithSchema(tbName), oriTable.Name),
+	}); err != nil {
+		return nil, err
+	}
+
+	// the indices are still named by the old table name
+	indexes := make(map[string]*schemas.Index, len(oriTable.Indexes))
+	for _, index := range oriTable.Indexes {
+		if index.IsRegular {
+			index.Name = index.XName(oriTable.Name)
+			index.IsRegular = false
+		}
+		indexes[index.Name] = index
+	}
+	oriTable.Indexes = indexes
+	oriTable.Name = tbName
+	return oriTable, nil
+}
+
+// applySyncAction records the action and executes it unless it's a dry run
+func (session *Session) applySyncAction(opts SyncOptions, syncResult *SyncResult, action *SyncAction) error {
+	syncResult.add(action)
+	if opts.DryRun {
+		return nil
+	}
+	_, err := session.exec(action.SQL)
+	return err
+}
+
+// canDropColumn returns true if the missing column is allowed to be dropped by the options
+func (opts *SyncOptions) canDropColumn(tableName, colName string) bool {
+	if len(opts.DropColumns) > 0 {
+		var allowed bool
+		for _, name := range opts.DropColumns {
+			if strings.EqualFold(name, colName) || strings.EqualFold(name, tableName+"."+colName) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return opts.ConfirmDropColumn == nil || opts.ConfirmDropColumn(tableName, colName)
+}
+
+// dropMissingColumns drops the columns of the table which have no related struct fields. The
+// indices on these columns will be dropped at first and removed from oriTable so that they
+// could be recreated according the struct later. If the table has to be rebuilt, the altered
+// columns will be changed at the same time.
+func (session *Session) dropMissingColumns(opts SyncOptions, syncResult *SyncResult, tableName string, table, oriTable *schemas.Table,
+	alteredCols map[string]*schemas.Column, needRebuild bool,
+) error {
+	dialect := session.engine.dialect
+
+	var droppedCols []*schemas.Column
+	for _, col := range oriTable.Columns() {
+		if table.GetColumn(col.Name) == nil && opts.canDropColumn(tableName, col.Name) {
+			droppedCols = append(droppedCols, col)
+		}
+	}
+	if len(droppedCols) == 0 {
+		if needRebuild {
+			return session.rebuildTable(opts, syncResult, tableName, oriTable, newRebuildTable(oriTable, alteredCols, nil))
+		}
+		return nil
+	}
+
+	isSQLite := dialect.URI().DBType == schemas.SQLITE
+	for _, name := range oriTable.ForeignKeyNames() {
+		fk := oriTable.ForeignKeys[name]
+		for _, col := range droppedCols {
+			if !containsColumn(fk.Cols, col.Name) {
+				continue
+			}
+			// SQLite will remove the foreign key when rebuilding the table
+			if !isSQLite {
+				sql, err := dialect.DropForeignKeySQL(tableName, fk)
+				if err != nil {
+					return err
+				}
+				if err := session.applySyncAction(opts, syncResult, &SyncAction{
+					Type:          SyncDropForeignKey,
+					Table:         tableName,
+					Constraint:    fk.XName(tableName),
+					OldDefinition: foreignKeyDefinition(dialect, tableName, fk),
+					SQL:           sql,
+					RevertSQL:     revertForeignKeySQL(dialect, tableName, fk),
+				}); err != nil {
+					return err
+				}
+			}
+			delete(oriTable.ForeignKeys, name)
+			needRebuild = needRebuild || isSQLite
+			break
+		}
+	}
+
+	for _, name := range sortedIndexNames(oriTable.Indexes) {
+		index := oriTable.Indexes[name]
+		for _, col := range droppedCols {
+			if !containsColumn(index.Cols, col.Name) {
+				continue
+			}
+			if err := session.applySyncAction(opts, syncResult, &SyncAction{
+				Type:          SyncDropIndex,
+				Table:         tableName,
+				Index:         name,
+				OldDefinition: indexDefinition(index),
+				SQL:           dialect.DropIndexSQL(tableName, index),
+				RevertSQL:     dialect.CreateIndexSQL(tableName, index),
+			}); err != nil {
+				return err
+			}
+			delete(oriTable.Indexes, name)
+			break
+		}
+	}
+
+	if isSQLite {
+		needRebuildForDrop, err := session.sqliteNeedRebuildForDrop(droppedCols)
+		if err != nil {
+			return err
+		}
+		if needRebuild || needRebuildForDrop {
+			return session.rebuildTable(opts, syncResult, tableName, oriTable, newRebuildTable(oriTable, alteredCols, droppedCols))
+		}
+	}
+
+	for _, col := range droppedCols {
+		if err := session.applySyncAction(opts, syncResult, &SyncAction{
+			Type:          SyncDropColumn,
+			Table:         tableName,
+			Column:        col.Name,
+			OldDefinition: columnDefinition(dialect, col),
+			SQL:           dialect.DropColumnSQL(tableName, col.Name),
+			RevertSQL:     dialect.AddColumnSQL(tableName, col),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsColumn(colNames []string, colName string) bool {
+	for _, name := range colNames {
+		fields := strings.Fields(name)
+		if len(fields) > 0 && strings.EqualFold(strings.Trim(fields[0], "`\"[]"), colName) {
+			return true
+		}
+	}
+	return false
+}
+
+func columnNames(cols []*schemas.Column) []string {
+	names := make([]string, 0, len(cols))
+	for _, col := range cols {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+// syncDroppedForeignKeys drops the foreign keys named by xorm but not in the struct any more or
+// changed if opts.DropForeignKeys, and returns the foreign keys should be added after the columns and
+// indices synchronized. For SQLite, the foreign keys of oriTable will be updated and the table should
+// be rebuilt.
+func (session *Session) syncDroppedForeignKeys(opts SyncOptions, syncResult *SyncResult, tableName string, table, oriTable *schemas.Table) ([]*schemas.ForeignKey, bool, error) {
+	dialect := session.engine.dialect
+
+	var addedFKs []*schemas.ForeignKey
+	var needRebuild bool
+	foundNames := make(map[string]bool)
+	for _, name := range table.ForeignKeyNames() {
+		fk := table.ForeignKeys[name]
+		var found, changed bool
+		for name2, fk2 := range oriTable.ForeignKeys {
+			if fk.Equal(fk2) {
+				foundNames[name2] = true
+				found = true
+				break
+			}
+			changed = changed || strings.EqualFold(fk2.Name, fk.XName(tableName))
+		}
+		if found {
+			continue
+		}
+		if changed && !opts.DropForeignKeys {
+			session.engine.logger.Warnf("Table %s foreign key %s is changed, it will not be recreated unless DropForeignKeys",
+				tableName, fk.XName(tableName))
+			continue
+		}
+		if _, err := dialect.AddForeignKeySQL(tableName, fk); err == dialects.ErrAlterConstraintUnsupported {
+			needRebuild = true
+		} else if err != nil {
+			return nil, false, err
+		}
+		addedFKs = append(addedFKs, fk)
+	}
+
+	for _, name := range oriTable.ForeignKeyNames() {
+		fk := oriTable.ForeignKeys[name]
+		if !opts.DropForeignKeys || foundNames[name] || !fk.IsXName(tableName) {
+			continue
+		}
+		sql, err := dialect.DropForeignKeySQL(tableName, fk)
+		if err == dialects.ErrAlterConstraintUnsupported {
+			needRebuild = true
+		} else if err != nil {
+			return nil, false, err
+		} else if err := session.applySyncAction(opts, syncResult, &SyncAction{
+			Type:          SyncDropForeignKey,
+			Table:         tableName,
+			Constraint:    fk.Name,
+			OldDefinition: foreignKeyDefinition(dialect, tableName, fk),
+			SQL:           sql,
+			RevertSQL:     revertForeignKeySQL(dialect, tableName, fk),
+		}); err != nil {
+			return nil, false, err
+		}
+		delete(oriTable.ForeignKeys, name)
+	}
+
+	if needRebuild {
+		// name the foreign keys by the table since the rebuilt table will be renamed
+		for _, fk := range addedFKs {
+			newFK := *fk
+			newFK.Name = fk.XName(tableName)
+			newFK.IsRegular = false
+			oriTable.AddForeignKey(&newFK)
+		}
+		return nil, true, nil
+	}
+	return addedFKs, false, nil
+}
+
+// syncDroppedChecks drops the CHECK constraints named by xorm but not in the struct any more if
+// opts.DropChecks, and returns the ones should be added. The constraints are compared by name since
+// databases may rewrite the expressions. For SQLite, the checks of oriTable will be updated and the
+// table should be rebuilt.
+func (session *Session) syncDroppedChecks(opts SyncOptions, syncResult *SyncResult, tableName string, table, oriTable *schemas.Table) ([]*schemas.Check, bool, error) {
+	dialect := session.engine.dialect
+
+	oriNames := make(map[string]string, len(oriTable.Checks))
+	for name, check := range oriTable.Checks {
+		if check.Name != "" {
+			oriNames[strings.ToLower(check.Name)] = name
+		}
+	}
+
+	var addedChecks []*schemas.Check
+	var needRebuild bool
+	foundNames := make(map[string]bool)
+	for _, name := range table.CheckNames() {
+		check := table.Checks[name]
+		if oriName, ok := oriNames[strings.ToLower(check.XName(tableName))]; ok {
+			foundNames[oriName] = true
+			continue
+		}
+		if _, err := dialect.AddCheckSQL(tableName, check); err == dialects.ErrAlterConstraintUnsupported {
+			needRebuild = true
+		} else if err != nil {
+			return nil, false, err
+		}
+		addedChecks = append(addedChecks, check)
+	}
+
+	for _, name := range oriTable.CheckNames() {
+		check := oriTable.Checks[name]
+		if !opts.DropChecks || foundNames[name] || !check.IsXName(tableName) {
+			continue
+		}
+		sql, err := dialect.DropCheckSQL(tableName, check)
+		if err == dialects.ErrAlterConstraintUnsupported {
+			needRebuild = true
+		} else if err != nil {
+			return nil, false, err
+		} else if err := session.applySyncAction(opts, syncResult, &SyncAction{
+			Type:          SyncDropCheck,
+			Table:         tableName,
+			Constraint:    check.Name,
+			OldDefinition: check.Expr,
+			SQL:           sql,
+			RevertSQL:     revertCheckSQL(dialect, tableName, check),
+		}); err != nil {
+			return nil, false, err
+		}
+		delete(oriTable.Checks, name)
+	}
+
+	if needRebuild {
+		// name the checks by the table since the rebuilt table will be renamed
+		for _, check := range addedChecks {
+			newCheck := *check
+			newCheck.Name = check.XName(tableName)
+			newCheck.IsRegular = false
+			oriTable.AddCheck(&newCheck)
+		}
+		return nil, true, nil
+	}
+	return addedChecks, false, nil
+}
+
+// alterColumn records and executes one kind of column alteration. For SQLite the column will be
+// put into alteredCols to rebuild the table, other databases which cannot alter columns in place
+// will only be warned
+func (session *Session) alterColumn(opts SyncOptions, syncResult *SyncResult, tp SyncActionType, tableName string,
+	oriCol, col *schemas.Column, genSQL func(string, *schemas.Column) (string, error), alteredCols map[string]*schemas.Column,
+) error {
+	sql, err := genSQL(tableName, col)
+	if err == dialects.ErrAlterColumnUnsupported {
+		if session.engine.dialect.URI().DBType == schemas.SQLITE {
+			alteredCols[strings.ToLower(col.Name)] = col
+			return nil
+		}
+		session.engine.logger.Warnf("Table %s column %s cannot be altered from %s to %s: %v", tableName, col.Name,
+			columnDefinition(session.engine.dialect, oriCol), columnDefinition(session.engine.dialect, col), err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	revertSQL, _ := genSQL(tableName, oriCol)
+	return session.applySyncAction(opts, syncResult, &SyncAction{
+		Type:          tp,
+		Table:         tableName,
+		Column:        col.Name,
+		OldDefinition: columnDefinition(session.engine.dialect, oriCol),
+		NewDefinition: columnDefinition(session.engine.dialect, col),
+		SQL:           sql,
+		RevertSQL:     revertSQL,
+	})
+}
+
+// isDefaultChanged returns true if the column in database has a different default value
+func isDefaultChanged(oriCol, col *schemas.Column) bool {
+	if col.DefaultIsEmpty || oriCol.DefaultIsEmpty {
+		return col.DefaultIsEmpty != oriCol.DefaultIsEmpty
+	}
+	return col.Default != oriCol.Default
+}
+
+var (
+	intTypeRanks = map[string]int{
+		schemas.TinyInt:   1,
+		schemas.SmallInt:  2,
+		schemas.MediumInt: 3,
+		schemas.Int:       4,
+		schemas.Integer:   4,
+		schemas.BigInt:    5,
+	}
+	floatTypeRanks = map[string]int{
+		schemas.Real:       1,
+		schemas.Float:      1,
+		schemas.Double:     2,
+		"DOUBLE PRECISION": 2,
+	}
+	textTypeRanks = map[string]int{
+		schemas.Char:       1,
+		schemas.NChar:      1,
+		schemas.Varchar:    2,
+		schemas.NVarchar:   2,
+		"VARCHAR2":         2,
+		schemas.TinyText:   3,
+		schemas.Text:       4,
+		schemas.NText:      4,
+		schemas.MediumText: 5,
+		schemas.LongText:   6,
+		schemas.Clob:       6,
+	}
+	// the max lengths of the text types without a length
+	textTypeMaxLengths = map[string]int64{
+		schemas.TinyText:   255,
+		schemas.Text:       65535,
+		schemas.NText:      1073741823,
+		schemas.MediumText: 16777215,
+		schemas.LongText:   4294967295,
+		schemas.Clob:       4294967295,
+	}
+	unicodeTextTypes = map[string]bool{
+		schemas.NChar:    true,
+		schemas.NVarchar: true,
+		schemas.NText:    true,
+	}
+)
+
+// textTypeLength returns the max length of the text type, false if it's unknown
+func textTypeLength(sqlType string, col *schemas.Column) (int64, bool) {
+	if length, ok := textTypeMaxLengths[sqlType]; ok {
+		return length, true
+	}
+	return col.Length, col.Length > 0
+}
+
+// isSafeTypeWidening returns true if the column type could be changed from curType to
+// expectedType without losing any data
+func isSafeTypeWidening(curType, expectedType string, oriCol, col *schemas.Column) bool {
+	cur := strings.ToUpper(schemas.SQLTypeName(curType))
+	expected := strings.ToUpper(schemas.SQLTypeName(expectedType))
+	if strings.HasPrefix(cur, "UNSIGNED ") != strings.HasPrefix(expected, "UNSIGNED ") {
+		return false
+	}
+	cur = strings.TrimPrefix(cur, "UNSIGNED ")
+	expected = strings.TrimPrefix(expected, "UNSIGNED ")
+
+	for _, ranks := range []map[string]int{intTypeRanks, floatTypeRanks} {
+		curRank, ok := ranks[cur]
+		if !ok {
+			continue
+		}
+		expectedRank, ok := ranks[expected]
+		if !ok {
+			return false
+		}
+		if expectedRank != curRank {
+			return expectedRank > curRank
+		}
+		return oriCol.Length > 0 && col.Length > oriCol.Length
+	}
+
+	if curRank, ok := textTypeRanks[cur]; ok {
+		expectedRank, ok := textTypeRanks[expected]
+		// the unicode characters will be lost if N-types are changed to the other types
+		if !ok || expectedRank < curRank || (unicodeTextTypes[cur] && !unicodeTextTypes[expected]) {
+			return false
+		}
+		curLength, ok := textTypeLength(cur, oriCol)
+		if !ok {
+			return false
+		}
+		expectedLength, ok := textTypeLength(expected, col)
+		if !ok || expectedLength < curLength {
+			return false
+		}
+		return expectedRank > curRank || expectedLength > curLength || cur != expected
+	}
+
+	if cur == expected && (cur == schemas.Decimal || cur == schemas.Numeric) {
+		return col.Length2 >= oriCol.Length2 &&
+			col.Length-col.Length2 >= oriCol.Length-oriCol.Length2 &&
+			(col.Length > oriCol.Length || col.Length2 > oriCol.Length2)
+	}
+	return false
+}
+
+func sortedIndexNames(indexes map[string]*schemas.Index) []string {
+	names := make([]string, 0, len(indexes))
+	for name := range indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,264 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"xorm.io/xorm/schemas"
+)
+
+// ErrSQLiteRebuildInTx represents a SQLite table cannot be rebuilt in a transaction with foreign keys
+// enabled, since foreign_keys cannot be disabled in a transaction
+var ErrSQLiteRebuildInTx = errors.New("SQLite table cannot be rebuilt in a transaction with foreign keys enabled")
+
+// sqliteDropColumnVersion is the first SQLite version supports ALTER TABLE DROP COLUMN
+var sqliteDropColumnVersion = []int{3, 35, 0}
+
+// versionLessThan compares a dotted version number with the given one
+func versionLessThan(version string, than []int) bool {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	for i, v := range than {
+		if i >= len(parts) {
+			return true
+		}
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return true
+		}
+		if n != v {
+			return n < v
+		}
+	}
+	return false
+}
+
+// sqliteNeedRebuildForDrop returns true if the columns cannot be dropped by ALTER TABLE
+func (session *Session) sqliteNeedRebuildForDrop(cols []*schemas.Column) (bool, error) {
+	for _, col := range cols {
+		if col.IsPrimaryKey {
+			return true, nil
+		}
+	}
+	version, err := session.engine.dialect.Version(session.ctx, session.getQueryer())
+	if err != nil {
+		return false, err
+	}
+	return versionLessThan(version.Number, sqliteDropColumnVersion), nil
+}
+
+// newRebuildTable returns the new definition of the table to be rebuilt from the original one,
+// the altered columns will be replaced and the dropped columns will be removed
+func newRebuildTable(oriTable *schemas.Table, alteredCols map[string]*schemas.Column, droppedCols []*schemas.Column) *schemas.Table {
+	newTable := schemas.NewEmptyTable()
+	newTable.Name = oriTable.Name
+	droppedNames := columnNames(droppedCols)
+	for _, col := range oriTable.Columns() {
+		if containsColumn(droppedNames, col.Name) {
+			continue
+		}
+		if alteredCol, ok := alteredCols[strings.ToLower(col.Name)]; ok {
+			col = alteredCol
+		}
+		newTable.AddColumn(col)
+	}
+	for name, fk := range oriTable.ForeignKeys {
+		var dropped bool
+		for _, colName := range fk.Cols {
+			if containsColumn(droppedNames, colName) {
+				dropped = true
+				break
+			}
+		}
+		if !dropped {
+			// the names read from database are kept as they are, including the empty ones
+			newTable.ForeignKeys[name] = fk
+		}
+	}
+	for name, check := range oriTable.Checks {
+		newTable.Checks[name] = check
+	}
+	return newTable
+}
+
+// rebuildTable replaces the table with a new one has the columns of newTable, it's for the
+// databases which cannot alter the columns in place, i.e. SQLite. The data of the columns
+// both in oriTable and newTable will be copied, and the indices of oriTable on the remaining
+// columns will be recreated. All of these are executed in one transaction.
+func (session *Session) rebuildTable(opts SyncOptions, syncResult *SyncResult, tableName string, oriTable, newTable *schemas.Table) error {
+	dialect := session.engine.dialect
+	quoter := dialect.Quoter()
+	tmpTableName := "xorm_rebuild_" + tableName
+
+	createTableSQL, _, err := dialect.CreateTableSQL(session.ctx, session.getQueryer(), newTable, tmpTableName)
+	if err != nil {
+		return err
+	}
+
+	var oldDefs, newDefs, copyCols []string
+	for _, col := range oriTable.Columns() {
+		oldDefs = append(oldDefs, columnDefinition(dialect, col))
+	}
+	for _, col := range newTable.Columns() {
+		newDefs = append(newDefs, columnDefinition(dialect, col))
+		if oriTable.GetColumn(col.Name) != nil && !col.IsGenerated() {
+			copyCols = append(copyCols, col.Name)
+		}
+	}
+
+	sqls := []string{
+		createTableSQL,
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", quoter.Quote(tmpTableName),
+			quoter.Join(copyCols, ","), quoter.Join(copyCols, ","), quoter.Quote(tableName)),
+		fmt.Sprintf("DROP TABLE %s", quoter.Quote(tableName)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoter.Quote(tmpTableName), quoter.Quote(tableName)),
+	}
+	for _, name := range sortedIndexNames(oriTable.Indexes) {
+		index := oriTable.Indexes[name]
+		var missed bool
+		for _, col := range oriTable.Columns() {
+			if newTable.GetColumn(col.Name) == nil && containsColumn(index.Cols, col.Name) {
+				missed = true
+				break
+			}
+		}
+		if !missed {
+			sqls = append(sqls, dialect.CreateIndexSQL(tableName, index))
+		}
+	}
+
+	var actions []*SyncAction
+	for i, sql := range sqls {
+		action := &SyncAction{
+			Type:  SyncRebuildTable,
+			Table: tableName,
+			SQL:   sql,
+		}
+		if i == 0 {
+			action.OldDefinition = strings.Join(oldDefs, ", ")
+			action.NewDefinition = strings.Join(newDefs, ", ")
+		}
+		actions = append(actions, action)
+	}
+
+	if opts.DryRun {
+		for _, action := range actions {
+			syncResult.add(action)
+		}
+		return nil
+	}
+
+	inTx := session.IsInTx()
+	if dialect.URI().DBType == schemas.SQLITE {
+		if !inTx {
+			return session.sqliteRebuildTable(opts, syncResult, actions)
+		}
+		// foreign_keys cannot be changed in a transaction, and DROP TABLE deletes the rows
+		// referencing the table by the foreign keys if it's enabled
+		var enabled bool
+		if err := session.tx.QueryRowContext(session.ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+			return err
+		}
+		if enabled {
+			return ErrSQLiteRebuildInTx
+		}
+	}
+
+	if !inTx {
+		if err := session.Begin(); err != nil {
+			return err
+		}
+	}
+	for _, action := range actions {
+		if err := session.applySyncAction(opts, syncResult, action); err != nil {
+			if !inTx {
+				_ = session.Rollback()
+			}
+			return err
+		}
+	}
+	if !inTx {
+		return session.Commit()
+	}
+	return nil
+}
+
+// sqliteRebuildTable executes the actions of rebuilding a table in a transaction on one connection
+// with foreign_keys disabled as https://www.sqlite.org/lang_altertable.html#otheralter describes,
+// otherwise DROP TABLE deletes the rows referencing the table by the foreign keys, and the actions
+// of ON DELETE such as CASCADE are performed. The foreign keys are checked before committing.
+func (session *Session) sqliteRebuildTable(opts SyncOptions, syncResult *SyncResult, actions []*SyncAction) (err error) {
+	db := session.DB()
+	conn, err := db.DB.Conn(session.ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var enabled bool
+	if err := conn.QueryRowContext(session.ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+		return err
+	}
+	if enabled {
+		if _, err := conn.ExecContext(session.ctx, "PRAGMA foreign_keys=OFF"); err != nil {
+			return err
+		}
+		defer func() {
+			if _, restoreErr := conn.ExecContext(context.Background(), "PRAGMA foreign_keys=ON"); restoreErr != nil {
+				// the connection should not be reused without foreign keys
+				_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+				if err == nil {
+					err = restoreErr
+				}
+			}
+		}()
+	}
+
+	tx, err := db.BeginConnTx(session.ctx, conn, nil)
+	if err != nil {
+		return err
+	}
+	session.isAutoCommit = false
+	session.isCommitedOrRollbacked = false
+	session.tx = tx
+	session.saveLastSQL("BEGIN TRANSACTION")
+
+	for _, action := range actions {
+		if err := session.applySyncAction(opts, syncResult, action); err != nil {
+			_ = session.Rollback()
+			return err
+		}
+	}
+
+	if err := session.sqliteForeignKeyCheck(); err != nil {
+		_ = session.Rollback()
+		return err
+	}
+	return session.Commit()
+}
+
+// sqliteForeignKeyCheck returns an error if any row violates the foreign keys
+func (session *Session) sqliteForeignKeyCheck() error {
+	rows, err := session.tx.QueryContext(session.ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var tableName, parent string
+		var rowID, fkID interface{}
+		if err := rows.Scan(&tableName, &rowID, &parent, &fkID); err != nil {
+			return err
+		}
+		return fmt.Errorf("the foreign key of table %s referencing %s is violated by row %v", tableName, parent, rowID)
+	}
+	return rows.Err()
+}
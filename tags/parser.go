@@ -37,6 +37,34 @@ type TableCollations interface {
 
 var tpTableCollations = reflect.TypeOf((*TableCollations)(nil)).Elem()
 
+// TableChecks is an interface that describes structs that provide CHECK constraints of the table
+type TableChecks interface {
+	TableChecks() []*schemas.Check
+}
+
+var tpTableChecks = reflect.TypeOf((*TableChecks)(nil)).Elem()
+
+// ViewDefinition is an interface that describes structs mapped to views, it returns the SELECT statement of the view
+type ViewDefinition interface {
+	ViewDefinition() string
+}
+
+var tpViewDefinition = reflect.TypeOf((*ViewDefinition)(nil)).Elem()
+
+// MaterializedView is an interface that describes views should be created as materialized views
+type MaterializedView interface {
+	MaterializedView() bool
+}
+
+var tpMaterializedView = reflect.TypeOf((*MaterializedView)(nil)).Elem()
+
+// TablePartitioning is an interface that describes structs of partitioned tables
+type TablePartitioning interface {
+	TablePartitioning() *schemas.Partitioning
+}
+
+var tpTablePartitioning = reflect.TypeOf((*TablePartitioning)(nil)).Elem()
+
 // Parser represents a parser for xorm tag
 type Parser struct {
 	identifier   string
@@ -281,6 +309,22 @@ func (parser *Parser) parseFieldWithTags(table *schemas.Table, fieldIndex int, f
 		addIndex(indexName, table, col, indexType)
 	}
 
+	if ctx.foreignKey != nil {
+		if ctx.foreignKey.RefTable == "" {
+			return nil, fmt.Errorf("field %s has on_delete or on_update tag but no fk tag", field.Name)
+		}
+		ctx.foreignKey.Name = col.Name
+		ctx.foreignKey.Cols = []string{col.Name}
+		table.AddForeignKey(ctx.foreignKey)
+	}
+
+	for _, check := range ctx.checks {
+		if check.Name == "" {
+			check.Name = col.Name
+		}
+		table.AddCheck(check)
+	}
+
 	return col, nil
 }
 
@@ -328,6 +372,7 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 	table.Type = t
 	table.Name = names.GetTableName(parser.tableMapper, v)
 	table.Comment = names.GetTableComment(v)
+	table.RenamedFrom = names.GetTableRenamedFrom(v)
 
 	for i := 0; i < t.NumField(); i++ {
 		col, err := parser.parseField(table, i, t.Field(i), v.Field(i))
@@ -345,6 +390,9 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 		// Override old information
 		if oldIndex, ok := table.Indexes[index.Name]; ok {
 			for _, colName := range oldIndex.Cols {
+				if schemas.IsIndexExpr(colName) {
+					continue
+				}
 				col := table.GetColumn(colName)
 				if col == nil {
 					return nil, ErrUnsupportedType
@@ -354,6 +402,9 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 		}
 		table.AddIndex(index)
 		for _, colName := range index.Cols {
+			if schemas.IsIndexExpr(colName) {
+				continue
+			}
 			col := table.GetColumn(colName)
 			if col == nil {
 				return nil, ErrUnsupportedType
@@ -378,6 +429,26 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 		}
 	}
 
+	for _, check := range tableChecks(v) {
+		if check.Name == "" || check.Expr == "" {
+			return nil, fmt.Errorf("check constraint of table %s should have a name and an expression", table.Name)
+		}
+		table.AddCheck(check)
+	}
+
+	if definition := viewDefinition(v); definition != "" {
+		table.IsView = true
+		table.ViewDefinition = definition
+		table.IsMaterialized = isMaterializedView(v)
+	}
+
+	if partitioning := tablePartitioning(v); partitioning != nil {
+		if len(partitioning.Keys) == 0 {
+			return nil, fmt.Errorf("partitioning of table %s should have the keys", table.Name)
+		}
+		table.Partitioning = partitioning
+	}
+
 	return table, nil
 }
 
@@ -418,3 +489,79 @@ func tableCollations(v reflect.Value) []*schemas.Collation {
 	}
 	return nil
 }
+
+func tableChecks(v reflect.Value) []*schemas.Check {
+	if v.Type().Implements(tpTableChecks) {
+		return v.Interface().(TableChecks).TableChecks()
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+		if v.Type().Implements(tpTableChecks) {
+			return v.Interface().(TableChecks).TableChecks()
+		}
+	} else if v.CanAddr() {
+		v1 := v.Addr()
+		if v1.Type().Implements(tpTableChecks) {
+			return v1.Interface().(TableChecks).TableChecks()
+		}
+	}
+	return nil
+}
+
+func viewDefinition(v reflect.Value) string {
+	if v.Type().Implements(tpViewDefinition) {
+		return v.Interface().(ViewDefinition).ViewDefinition()
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+		if v.Type().Implements(tpViewDefinition) {
+			return v.Interface().(ViewDefinition).ViewDefinition()
+		}
+	} else if v.CanAddr() {
+		v1 := v.Addr()
+		if v1.Type().Implements(tpViewDefinition) {
+			return v1.Interface().(ViewDefinition).ViewDefinition()
+		}
+	}
+	return ""
+}
+
+func isMaterializedView(v reflect.Value) bool {
+	if v.Type().Implements(tpMaterializedView) {
+		return v.Interface().(MaterializedView).MaterializedView()
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+		if v.Type().Implements(tpMaterializedView) {
+			return v.Interface().(MaterializedView).MaterializedView()
+		}
+	} else if v.CanAddr() {
+		v1 := v.Addr()
+		if v1.Type().Implements(tpMaterializedView) {
+			return v1.Interface().(MaterializedView).MaterializedView()
+		}
+	}
+	return false
+}
+
+func tablePartitioning(v reflect.Value) *schemas.Partitioning {
+	if v.Type().Implements(tpTablePartitioning) {
+		return v.Interface().(TablePartitioning).TablePartitioning()
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+		if v.Type().Implements(tpTablePartitioning) {
+			return v.Interface().(TablePartitioning).TablePartitioning()
+		}
+	} else if v.CanAddr() {
+		v1 := v.Addr()
+		if v1.Type().Implements(tpTablePartitioning) {
+			return v1.Interface().(TablePartitioning).TablePartitioning()
+		}
+	}
+	return nil
+}
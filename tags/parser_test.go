@@ -617,3 +617,228 @@ func TestParseWithSQLType(t *testing.T) {
 	assert.EqualValues(t, "DATETIME", table.Columns()[3].SQLType.Name)
 	assert.EqualValues(t, "UUID", table.Columns()[4].SQLType.Name)
 }
+
+func TestParseWithForeignKey(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+
+	type StructWithForeignKey struct {
+		Id       int64
+		UserId   int64 `db:"fk(user.id) on_delete(cascade)"`
+		GroupId  int64 `db:"on_update(set_null) fk(group.id) null"`
+		ParentId int64 `db:"'parent' fk(struct_with_foreign_key.id) on_delete('set null')"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithForeignKey)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"group_id", "parent", "user_id"}, table.ForeignKeyNames())
+
+	fk := table.ForeignKeys["user_id"]
+	assert.EqualValues(t, []string{"user_id"}, fk.Cols)
+	assert.EqualValues(t, "user", fk.RefTable)
+	assert.EqualValues(t, []string{"id"}, fk.RefCols)
+	assert.EqualValues(t, schemas.Cascade, fk.OnDelete)
+	assert.EqualValues(t, "", fk.OnUpdate)
+
+	fk = table.ForeignKeys["group_id"]
+	assert.EqualValues(t, "group", fk.RefTable)
+	assert.EqualValues(t, schemas.SetNull, fk.OnUpdate)
+
+	fk = table.ForeignKeys["parent"]
+	assert.EqualValues(t, []string{"parent"}, fk.Cols)
+	assert.EqualValues(t, schemas.SetNull, fk.OnDelete)
+
+	type StructWithBadForeignKey struct {
+		UserId int64 `db:"fk(user)"`
+	}
+	_, err = parser.Parse(reflect.ValueOf(new(StructWithBadForeignKey)))
+	assert.Error(t, err)
+
+	type StructWithNoForeignKey struct {
+		UserId int64 `db:"on_delete(cascade)"`
+	}
+	_, err = parser.Parse(reflect.ValueOf(new(StructWithNoForeignKey)))
+	assert.Error(t, err)
+}
+
+type StructWithTableChecks struct {
+	Id    int64
+	Price int64 `db:"check('price >= 0')"`
+	Count int64 `db:"check('count < 100', max_count)"`
+}
+
+func (StructWithTableChecks) TableChecks() []*schemas.Check {
+	return []*schemas.Check{
+		schemas.NewCheck("price_count", "price > count"),
+	}
+}
+
+func TestParseWithCheck(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithTableChecks)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"max_count", "price", "price_count"}, table.CheckNames())
+	assert.EqualValues(t, "price >= 0", table.Checks["price"].Expr)
+	assert.EqualValues(t, "count < 100", table.Checks["max_count"].Expr)
+	assert.EqualValues(t, "price > count", table.Checks["price_count"].Expr)
+
+	type StructWithQuotedCheck struct {
+		Status string `db:"check('status IN (''a'', ''b'')')"`
+	}
+	table, err = parser.Parse(reflect.ValueOf(new(StructWithQuotedCheck)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "status IN ('a', 'b')", table.Checks["status"].Expr)
+
+	type StructWithBadCheck struct {
+		Price int64 `db:"check"`
+	}
+	_, err = parser.Parse(reflect.ValueOf(new(StructWithBadCheck)))
+	assert.Error(t, err)
+}
+
+type StructWithRenamedFrom struct {
+	Id       int64
+	Nickname string `db:"renamed_from(name)"`
+}
+
+func (StructWithRenamedFrom) TableRenamedFrom() string {
+	return "struct_with_old_name"
+}
+
+func TestParseWithRenamedFrom(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithRenamedFrom)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "struct_with_old_name", table.RenamedFrom)
+	assert.EqualValues(t, "name", table.GetColumn("nickname").RenamedFrom)
+	assert.EqualValues(t, "", table.GetColumn("id").RenamedFrom)
+}
+
+type StructView struct {
+	Id   int64
+	Name string
+}
+
+func (StructView) ViewDefinition() string {
+	return "SELECT id, name FROM struct_user"
+}
+
+type StructMaterializedView struct {
+	StructView `xorm:"extends"`
+}
+
+func (StructMaterializedView) MaterializedView() bool {
+	return true
+}
+
+func TestParseView(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("postgres"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructView)))
+	assert.NoError(t, err)
+	assert.True(t, table.IsView)
+	assert.False(t, table.IsMaterialized)
+	assert.EqualValues(t, "SELECT id, name FROM struct_user", table.ViewDefinition)
+	assert.EqualValues(t, 2, len(table.Columns()))
+
+	table, err = parser.Parse(reflect.ValueOf(new(StructMaterializedView)))
+	assert.NoError(t, err)
+	assert.True(t, table.IsView)
+	assert.True(t, table.IsMaterialized)
+
+	table, err = parser.Parse(reflect.ValueOf(new(StructWithRenamedFrom)))
+	assert.NoError(t, err)
+	assert.False(t, table.IsView)
+}
+
+type StructWithGenerated struct {
+	Id    int64
+	Price int64
+	Qty   int64
+	Total int64  `db:"generated('price * qty') stored"`
+	Title string `db:"generated('upper(name)') virtual notnull"`
+}
+
+func TestParseWithGenerated(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithGenerated)))
+	assert.NoError(t, err)
+
+	total := table.GetColumn("total")
+	assert.EqualValues(t, "price * qty", total.Generated)
+	assert.True(t, total.GeneratedStored)
+	assert.EqualValues(t, schemas.ONLYFROMDB, total.MapType)
+
+	title := table.GetColumn("title")
+	assert.EqualValues(t, "upper(name)", title.Generated)
+	assert.False(t, title.GeneratedStored)
+	assert.False(t, title.Nullable)
+	assert.EqualValues(t, schemas.ONLYFROMDB, title.MapType)
+
+	assert.False(t, table.GetColumn("price").IsGenerated())
+}
+
+type StructPartitioned struct {
+	Id      int64
+	Created time.Time
+}
+
+func (StructPartitioned) TablePartitioning() *schemas.Partitioning {
+	partitioning := schemas.NewPartitioning(schemas.RangePartition, "created")
+	partitioning.AddPartition(schemas.NewRangePartition("struct_partitioned_2023", "'2023-01-01'", "'2024-01-01'"))
+	return partitioning
+}
+
+func TestParseWithPartitioning(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("postgres"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructPartitioned)))
+	assert.NoError(t, err)
+	if assert.NotNil(t, table.Partitioning) {
+		assert.EqualValues(t, schemas.RangePartition, table.Partitioning.Type)
+		assert.EqualValues(t, []string{"created"}, table.Partitioning.Keys)
+		assert.Len(t, table.Partitioning.Partitions, 1)
+	}
+
+	table, err = parser.Parse(reflect.ValueOf(new(StructWithRenamedFrom)))
+	assert.NoError(t, err)
+	assert.Nil(t, table.Partitioning)
+}
@@ -94,6 +94,8 @@ type Context struct {
 	hasNoCacheTag   bool
 	ignoreNext      bool
 	isUnsigned      bool
+	foreignKey      *schemas.ForeignKey
+	checks          []*schemas.Check
 }
 
 // Handler describes tag handler for XORM
@@ -124,6 +126,14 @@ var defaultTagHandlers = map[string]Handler{
 	"EXTENDS":  ExtendsTagHandler,
 	"UNSIGNED": UnsignedTagHandler,
 	"COLLATE":  CollateTagHandler,
+
+	"FK":        ForeignKeyTagHandler,
+	"ON_DELETE": OnDeleteTagHandler,
+	"ON_UPDATE": OnUpdateTagHandler,
+	"CHECK":     CheckTagHandler,
+
+	"GENERATED":    GeneratedTagHandler,
+	"RENAMED_FROM": RenamedFromTagHandler,
 }
 
 func init() {
@@ -283,6 +293,15 @@ func CommentTagHandler(ctx *Context) error {
 	return nil
 }
 
+// RenamedFromTagHandler describes renamed_from tag handler, the param is the old column name
+func RenamedFromTagHandler(ctx *Context) error {
+	if len(ctx.params) == 0 {
+		return fmt.Errorf("renamed_from tag of field %s should have the old column name", ctx.col.FieldName)
+	}
+	ctx.col.RenamedFrom = strings.Trim(ctx.params[0], "'` ")
+	return nil
+}
+
 func CollateTagHandler(ctx *Context) error {
 	if len(ctx.params) > 0 {
 		ctx.col.Collation = ctx.params[0]
@@ -293,6 +312,87 @@ func CollateTagHandler(ctx *Context) error {
 	return nil
 }
 
+// getForeignKey returns the foreign key of the column, it will be created if not exist
+func (ctx *Context) getForeignKey() *schemas.ForeignKey {
+	if ctx.foreignKey == nil {
+		ctx.foreignKey = schemas.NewForeignKey("", "")
+	}
+	return ctx.foreignKey
+}
+
+// ForeignKeyTagHandler describes foreign key tag handler, the param should be table.column
+func ForeignKeyTagHandler(ctx *Context) error {
+	if len(ctx.params) == 0 {
+		return fmt.Errorf("fk tag of field %s should be fk(table.column)", ctx.col.FieldName)
+	}
+	ref := strings.Trim(strings.TrimSpace(ctx.params[0]), "'")
+	idx := strings.LastIndex(ref, ".")
+	if idx <= 0 || idx == len(ref)-1 {
+		return fmt.Errorf("fk tag of field %s should be fk(table.column) but got fk(%s)", ctx.col.FieldName, ref)
+	}
+	fk := ctx.getForeignKey()
+	fk.RefTable = ref[:idx]
+	fk.RefCols = []string{ref[idx+1:]}
+	return nil
+}
+
+// OnDeleteTagHandler describes the delete action of foreign key tag handler
+func OnDeleteTagHandler(ctx *Context) error {
+	if len(ctx.params) > 0 {
+		ctx.getForeignKey().OnDelete = strings.ToUpper(strings.ReplaceAll(strings.Trim(ctx.params[0], "' "), "_", " "))
+	}
+	return nil
+}
+
+// OnUpdateTagHandler describes the update action of foreign key tag handler
+func OnUpdateTagHandler(ctx *Context) error {
+	if len(ctx.params) > 0 {
+		ctx.getForeignKey().OnUpdate = strings.ToUpper(strings.ReplaceAll(strings.Trim(ctx.params[0], "' "), "_", " "))
+	}
+	return nil
+}
+
+// CheckTagHandler describes CHECK constraint tag handler, the params are the expression
+// and an optional name, the column name will be used if the name is omitted
+func CheckTagHandler(ctx *Context) error {
+	if len(ctx.params) == 0 {
+		return fmt.Errorf("check tag of field %s should be check('expression')", ctx.col.FieldName)
+	}
+	expr := strings.TrimSpace(ctx.params[0])
+	if len(expr) > 1 && strings.HasPrefix(expr, "'") && strings.HasSuffix(expr, "'") {
+		expr = strings.ReplaceAll(expr[1:len(expr)-1], "''", "'")
+	}
+	var name string
+	if len(ctx.params) > 1 {
+		name = strings.Trim(strings.TrimSpace(ctx.params[1]), "'")
+	}
+	ctx.checks = append(ctx.checks, schemas.NewCheck(name, expr))
+	return nil
+}
+
+// GeneratedTagHandler describes generated tag handler, the param is the expression which should be
+// quoted if it has spaces or brackets, and the next tag could be stored or virtual.
+// The generated column is read only like <-
+func GeneratedTagHandler(ctx *Context) error {
+	if len(ctx.params) == 0 {
+		return fmt.Errorf("generated tag of field %s should be generated('expression')", ctx.col.FieldName)
+	}
+	expr := strings.TrimSpace(ctx.params[0])
+	if len(expr) > 1 && strings.HasPrefix(expr, "'") && strings.HasSuffix(expr, "'") {
+		expr = strings.ReplaceAll(expr[1:len(expr)-1], "''", "'")
+	}
+	ctx.col.Generated = expr
+	switch strings.ToUpper(ctx.nextTag) {
+	case "STORED":
+		ctx.col.GeneratedStored = true
+		ctx.ignoreNext = true
+	case "VIRTUAL":
+		ctx.ignoreNext = true
+	}
+	ctx.col.MapType = schemas.ONLYFROMDB
+	return nil
+}
+
 // SQLTypeTagHandler describes SQL Type tag handler
 func SQLTypeTagHandler(ctx *Context) error {
 	ctx.col.SQLType = schemas.SQLType{Name: ctx.tagUname}
@@ -360,7 +460,9 @@ func ExtendsTagHandler(ctx *Context) error {
 		if err != nil {
 			return err
 		}
+		colNames := make(map[string]string)
 		for _, col := range parentTable.Columns() {
+			oriColName := col.Name
 			col.FieldName = fmt.Sprintf("%v.%v", ctx.col.FieldName, col.FieldName)
 			col.FieldIndex = append(ctx.col.FieldIndex, col.FieldIndex...)
 
@@ -385,6 +487,17 @@ func ExtendsTagHandler(ctx *Context) error {
 			for indexName, indexType := range col.Indexes {
 				addIndex(indexName, ctx.table, col, indexType)
 			}
+			colNames[oriColName] = col.Name
+		}
+		for _, fk := range parentTable.ForeignKeys {
+			for i, colName := range fk.Cols {
+				fk.Cols[i] = colNames[colName]
+			}
+			fk.Name = strings.Join(fk.Cols, "_")
+			ctx.table.AddForeignKey(fk)
+		}
+		for _, check := range parentTable.Checks {
+			ctx.table.AddCheck(check)
 		}
 	default:
 		// TODO: warning
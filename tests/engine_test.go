@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"xorm.io/xorm"
+	"xorm.io/xorm/dialects"
 	"xorm.io/xorm/schemas"
 
 	_ "gitee.com/travelliu/dm"
@@ -361,3 +362,84 @@ func TestGetColumnsLength(t *testing.T) {
 		}
 	}
 }
+
+func TestNamedLock(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	lock, err := testEngine.Lock(context.Background(), "xorm_test_lock")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = testEngine.Lock(ctx, "xorm_test_lock")
+	assert.ErrorIs(t, err, xorm.ErrLockTimeout)
+
+	assert.NoError(t, lock.Unlock())
+
+	lock, err = testEngine.Lock(context.Background(), "xorm_test_lock")
+	assert.NoError(t, err)
+	assert.NoError(t, lock.Unlock())
+
+	if _, ok := testEngine.Dialect().(dialects.LockDialect); !ok {
+		lockTable := testEngine.Quote(testEngine.TableName("xorm_lock", true))
+
+		// the lock not refreshed in the TTL is taken over by others
+		staleLock, err := testEngine.Lock(context.Background(), "xorm_test_lock")
+		assert.NoError(t, err)
+		_, err = testEngine.Exec("UPDATE "+lockTable+" SET acquired = ? WHERE name = ?",
+			time.Now().Add(-2*xorm.DefaultLockTTL).UnixMilli(), "xorm_test_lock")
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		lock, err = testEngine.Lock(ctx, "xorm_test_lock")
+		assert.NoError(t, err)
+
+		// the expired lock doesn't release the lock taken over by others
+		assert.NoError(t, staleLock.Unlock())
+		ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_, err = testEngine.Lock(ctx, "xorm_test_lock")
+		assert.ErrorIs(t, err, xorm.ErrLockTimeout)
+		assert.NoError(t, lock.Unlock())
+
+		// the lock held is refreshed and not taken over
+		testEngine.SetLockTTL(300 * time.Millisecond)
+		lock, err = testEngine.Lock(context.Background(), "xorm_test_lock")
+		assert.NoError(t, err)
+		time.Sleep(500 * time.Millisecond)
+		ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_, err = testEngine.Lock(ctx, "xorm_test_lock")
+		assert.ErrorIs(t, err, xorm.ErrLockTimeout)
+		assert.NoError(t, lock.Unlock())
+		testEngine.SetLockTTL(xorm.DefaultLockTTL)
+
+		// the errors except the lock held by others are returned without waiting
+		_, err = testEngine.Exec("DROP TABLE " + lockTable)
+		assert.NoError(t, err)
+		_, err = testEngine.Exec("CREATE TABLE " + lockTable + " (name VARCHAR(255) PRIMARY KEY, owner VARCHAR(255), acquired BIGINT, holder VARCHAR(255) NOT NULL)")
+		assert.NoError(t, err)
+
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err = testEngine.Lock(ctx, "xorm_test_lock")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, xorm.ErrLockTimeout)
+		assert.NoError(t, ctx.Err())
+
+		_, err = testEngine.Exec("DROP TABLE " + lockTable)
+		assert.NoError(t, err)
+	}
+
+	type SyncWithLock struct {
+		Id   int64
+		Name string
+	}
+
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{
+		LockName:    "xorm_test_sync",
+		LockTimeout: 10 * time.Second,
+	}, new(SyncWithLock))
+	assert.NoError(t, err)
+}
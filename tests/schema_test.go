@@ -7,6 +7,7 @@ package tests
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -726,6 +727,926 @@ func TestSyncWithOptions(t *testing.T) {
 	assert.ElementsMatch(t, getKeysFromMap(tableInfoFromStruct.Indexes), getKeysFromMap(getIndicesOfBeanFromDB(t, &SyncWithOpts1{})))
 }
 
+type SyncDryRun1 struct {
+	Id   int64
+	Name string `xorm:"index"`
+}
+
+func (*SyncDryRun1) TableName() string {
+	return "sync_dry_run"
+}
+
+type SyncDryRun2 struct {
+	Id    int64
+	Name  string
+	Email string `xorm:"unique"`
+}
+
+func (*SyncDryRun2) TableName() string {
+	return "sync_dry_run"
+}
+
+func TestSyncWithOptionsDryRun(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{DryRun: true}, &SyncDryRun1{})
+	assert.NoError(t, err)
+	var types []xorm.SyncActionType
+	for _, action := range result.Actions {
+		if action.Type != xorm.SyncCreateSequence {
+			types = append(types, action.Type)
+		}
+	}
+	assert.EqualValues(t, []xorm.SyncActionType{xorm.SyncCreateTable, xorm.SyncCreateIndex}, types)
+
+	exist, err := testEngine.IsTableExist(&SyncDryRun1{})
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	result, err = testEngine.SyncWithOptions(xorm.SyncOptions{}, &SyncDryRun1{})
+	assert.NoError(t, err)
+	assert.Len(t, result.SQLs(), len(result.Actions))
+
+	exist, err = testEngine.IsTableExist(&SyncDryRun1{})
+	assert.NoError(t, err)
+	assert.True(t, exist)
+
+	result, err = testEngine.SyncWithOptions(xorm.SyncOptions{DryRun: true}, &SyncDryRun2{})
+	assert.NoError(t, err)
+	if assert.Len(t, result.Actions, 3) {
+		assert.EqualValues(t, xorm.SyncAddColumn, result.Actions[0].Type)
+		assert.EqualValues(t, "email", result.Actions[0].Column)
+		assert.EqualValues(t, xorm.SyncDropIndex, result.Actions[1].Type)
+		assert.EqualValues(t, "name", result.Actions[1].Index)
+		assert.EqualValues(t, xorm.SyncCreateIndex, result.Actions[2].Type)
+		assert.EqualValues(t, "email", result.Actions[2].Index)
+		assert.EqualValues(t, "UNIQUE(email)", result.Actions[2].NewDefinition)
+	}
+
+	// nothing has been changed by the dry run
+	assert.Len(t, getIndicesOfBeanFromDB(t, &SyncDryRun1{}), 1)
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if table.Name == "sync_dry_run" {
+			assert.Nil(t, table.GetColumn("email"))
+		}
+	}
+
+	result, err = testEngine.SyncWithOptions(xorm.SyncOptions{}, &SyncDryRun2{})
+	assert.NoError(t, err)
+	assert.Len(t, result.Actions, 3)
+	indices := getIndicesOfBeanFromDB(t, &SyncDryRun2{})
+	assert.ElementsMatch(t, []string{"email"}, getKeysFromMap(indices))
+}
+
+type SyncReconcile1 struct {
+	Id   int64
+	Name string `xorm:"varchar(50) null"`
+	Age  int    `xorm:"null"`
+}
+
+func (*SyncReconcile1) TableName() string {
+	return "sync_reconcile"
+}
+
+type SyncReconcile2 struct {
+	Id   int64
+	Name string `xorm:"varchar(100) notnull default('unknown')"`
+	Age  int    `xorm:"null"`
+}
+
+func (*SyncReconcile2) TableName() string {
+	return "sync_reconcile"
+}
+
+func TestSyncReconcileColumns(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SyncReconcile1))
+
+	_, err := testEngine.Insert(&SyncReconcile1{Name: "a", Age: 1})
+	assert.NoError(t, err)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{ReconcileColumns: true, DryRun: true}, &SyncReconcile2{})
+	assert.NoError(t, err)
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		assert.NotEmpty(t, result.Actions)
+		for _, action := range result.Actions {
+			assert.EqualValues(t, xorm.SyncRebuildTable, action.Type)
+		}
+	} else {
+		var types []xorm.SyncActionType
+		for _, action := range result.Actions {
+			assert.EqualValues(t, "name", action.Column)
+			types = append(types, action.Type)
+		}
+		assert.Contains(t, types, xorm.SyncAlterColumnDefault)
+		assert.Contains(t, types, xorm.SyncAlterColumnNullable)
+	}
+
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{ReconcileColumns: true}, &SyncReconcile2{})
+	assert.NoError(t, err)
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if table.Name != "sync_reconcile" {
+			continue
+		}
+		col := table.GetColumn("name")
+		if assert.NotNil(t, col) {
+			assert.False(t, col.Nullable)
+			assert.False(t, col.DefaultIsEmpty)
+		}
+	}
+
+	var beans []SyncReconcile2
+	assert.NoError(t, testEngine.Find(&beans))
+	if assert.Len(t, beans, 1) {
+		assert.EqualValues(t, "a", beans[0].Name)
+		assert.EqualValues(t, 1, beans[0].Age)
+	}
+}
+
+type SyncRebuild1 struct {
+	Id   int64
+	Name string `xorm:"null index"`
+	Age  int    `xorm:"null unique"`
+}
+
+func (*SyncRebuild1) TableName() string {
+	return "sync_rebuild"
+}
+
+type SyncRebuild2 struct {
+	Id   int64
+	Name string `xorm:"notnull default('') index"`
+	Age  int    `xorm:"notnull default(0) unique"`
+}
+
+func (*SyncRebuild2) TableName() string {
+	return "sync_rebuild"
+}
+
+func TestSyncRebuildTable(t *testing.T) {
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SyncRebuild1))
+
+	_, err := testEngine.Insert(&SyncRebuild1{Name: "a", Age: 1})
+	assert.NoError(t, err)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{ReconcileColumns: true}, &SyncRebuild2{})
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, result.Actions) {
+		assert.EqualValues(t, xorm.SyncRebuildTable, result.Actions[0].Type)
+	}
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	var found bool
+	for _, table := range tables {
+		if table.Name != "sync_rebuild" {
+			continue
+		}
+		found = true
+		assert.False(t, table.GetColumn("name").Nullable)
+		assert.False(t, table.GetColumn("age").Nullable)
+		assert.Len(t, table.Indexes, 2)
+	}
+	assert.True(t, found)
+
+	var beans []SyncRebuild2
+	assert.NoError(t, testEngine.Find(&beans))
+	if assert.Len(t, beans, 1) {
+		assert.EqualValues(t, "a", beans[0].Name)
+		assert.EqualValues(t, 1, beans[0].Age)
+	}
+
+	_, err = testEngine.Insert(&SyncRebuild2{Name: "b", Age: 1})
+	assert.Error(t, err)
+
+	result, err = testEngine.SyncWithOptions(xorm.SyncOptions{ReconcileColumns: true}, &SyncRebuild2{})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Actions)
+}
+
+type SyncRebuildChild struct {
+	Id       int64
+	ParentId int64 `xorm:"fk(sync_rebuild.id) on_delete(cascade)"`
+}
+
+func (*SyncRebuildChild) TableName() string {
+	return "sync_rebuild_child"
+}
+
+func TestSyncRebuildTableWithForeignKeys(t *testing.T) {
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		t.Skip()
+		return
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncRebuildChild), new(SyncRebuild1)))
+
+	// the foreign keys are enforced by all the connections of the engine
+	fkConnString := connString
+	if strings.Contains(fkConnString, "?") {
+		fkConnString += "&"
+	} else {
+		fkConnString += "?"
+	}
+	if dbType == "sqlite" {
+		fkConnString += "_pragma=foreign_keys(1)"
+	} else {
+		fkConnString += "_foreign_keys=1"
+	}
+	engine, err := xorm.NewEngine(dbType, fkConnString)
+	assert.NoError(t, err)
+	defer engine.Close()
+
+	assert.NoError(t, engine.Sync(new(SyncRebuild1), new(SyncRebuildChild)))
+	parent := SyncRebuild1{Name: "a", Age: 1}
+	_, err = engine.Insert(&parent)
+	assert.NoError(t, err)
+	_, err = engine.Insert(&SyncRebuildChild{ParentId: parent.Id})
+	assert.NoError(t, err)
+
+	result, err := engine.SyncWithOptions(xorm.SyncOptions{ReconcileColumns: true}, new(SyncRebuild2))
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, result.Actions) {
+		assert.EqualValues(t, xorm.SyncRebuildTable, result.Actions[0].Type)
+	}
+
+	// the rows referencing the rebuilt table are not deleted by the cascades
+	count, err := engine.Count(new(SyncRebuildChild))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	// the foreign keys are enabled again
+	_, err = engine.Insert(&SyncRebuildChild{ParentId: parent.Id + 100})
+	assert.Error(t, err)
+
+	// the foreign keys cannot be disabled in a transaction
+	session := engine.NewSession()
+	defer session.Close()
+	assert.NoError(t, session.Begin())
+	_, err = session.SyncWithOptions(xorm.SyncOptions{ReconcileColumns: true}, new(SyncRebuild1))
+	assert.EqualValues(t, xorm.ErrSQLiteRebuildInTx, err)
+	assert.NoError(t, session.Rollback())
+}
+
+type SyncDropColumn1 struct {
+	Id       int64
+	Name     string `xorm:"index"`
+	Nickname string `xorm:"unique"`
+	Age      int
+}
+
+func (*SyncDropColumn1) TableName() string {
+	return "sync_drop_column"
+}
+
+type SyncDropColumn2 struct {
+	Id   int64
+	Name string `xorm:"index"`
+}
+
+func (*SyncDropColumn2) TableName() string {
+	return "sync_drop_column"
+}
+
+func TestSyncDropMissingColumns(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SyncDropColumn1))
+
+	_, err := testEngine.Insert(&SyncDropColumn1{Name: "a", Nickname: "b", Age: 1})
+	assert.NoError(t, err)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{DropMissingColumns: true, DryRun: true}, &SyncDropColumn2{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.Actions)
+	for _, action := range result.Actions {
+		assert.NotEqualValues(t, "id", action.Column)
+	}
+	tableInfo, err := testEngine.TableInfo(new(SyncDropColumn1))
+	assert.NoError(t, err)
+	assert.Len(t, getIndicesOfBeanFromDB(t, new(SyncDropColumn1)), len(tableInfo.Indexes))
+
+	var confirmed []string
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{
+		DropMissingColumns: true,
+		DropColumns:        []string{"nickname", "sync_drop_column.age"},
+		ConfirmDropColumn: func(tableName, colName string) bool {
+			confirmed = append(confirmed, colName)
+			return colName != "age"
+		},
+	}, &SyncDropColumn2{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"nickname", "age"}, confirmed)
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if table.Name != "sync_drop_column" {
+			continue
+		}
+		assert.Nil(t, table.GetColumn("nickname"))
+		assert.NotNil(t, table.GetColumn("age"))
+		assert.Len(t, table.Indexes, 1)
+	}
+
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{DropMissingColumns: true}, &SyncDropColumn2{})
+	assert.NoError(t, err)
+
+	var beans []SyncDropColumn2
+	assert.NoError(t, testEngine.Find(&beans))
+	if assert.Len(t, beans, 1) {
+		assert.EqualValues(t, "a", beans[0].Name)
+	}
+}
+
+type SyncForeignKeyUser struct {
+	Id   int64
+	Name string
+}
+
+func (*SyncForeignKeyUser) TableName() string {
+	return "sync_fk_user"
+}
+
+type SyncForeignKeyOrder1 struct {
+	Id     int64
+	UserId int64 `xorm:"fk(sync_fk_user.id) on_delete(cascade)"`
+}
+
+func (*SyncForeignKeyOrder1) TableName() string {
+	return "sync_fk_order"
+}
+
+type SyncForeignKeyOrder2 struct {
+	Id     int64
+	UserId int64 `xorm:"index"`
+}
+
+func (*SyncForeignKeyOrder2) TableName() string {
+	return "sync_fk_order"
+}
+
+func getForeignKeysOfTableFromDB(t *testing.T, tableName string) map[string]*schemas.ForeignKey {
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if table.Name == tableName {
+			return table.ForeignKeys
+		}
+	}
+	assert.Fail(t, "table not found", tableName)
+	return nil
+}
+
+func TestSyncForeignKeys(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncForeignKeyOrder1), new(SyncForeignKeyUser)))
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncForeignKeyOrder1), new(SyncForeignKeyUser))
+	assert.NoError(t, err)
+	var createdTables []string
+	for _, action := range result.Actions {
+		if action.Type == xorm.SyncCreateTable {
+			createdTables = append(createdTables, action.Table)
+		}
+	}
+	assert.EqualValues(t, []string{
+		testEngine.TableName("sync_fk_user", true),
+		testEngine.TableName("sync_fk_order", true),
+	}, createdTables)
+
+	fks := getForeignKeysOfTableFromDB(t, "sync_fk_order")
+	assert.Len(t, fks, 1)
+	for _, fk := range fks {
+		assert.EqualValues(t, []string{"user_id"}, fk.Cols)
+		assert.EqualValues(t, "sync_fk_user", fk.RefTable)
+		assert.EqualValues(t, []string{"id"}, fk.RefCols)
+		assert.EqualValues(t, schemas.Cascade, fk.OnDelete)
+	}
+
+	user := SyncForeignKeyUser{Name: "a"}
+	_, err = testEngine.Insert(&user)
+	assert.NoError(t, err)
+	_, err = testEngine.Insert(&SyncForeignKeyOrder1{UserId: user.Id})
+	assert.NoError(t, err)
+
+	result, err = testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncForeignKeyUser), new(SyncForeignKeyOrder1))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Actions)
+
+	// the foreign keys are only dropped if DropForeignKeys
+	result, err = testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncForeignKeyOrder2))
+	assert.NoError(t, err)
+	for _, action := range result.Actions {
+		assert.NotEqualValues(t, xorm.SyncDropForeignKey, action.Type)
+		assert.NotEqualValues(t, xorm.SyncRebuildTable, action.Type)
+	}
+	assert.Len(t, getForeignKeysOfTableFromDB(t, "sync_fk_order"), 1)
+
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{DropForeignKeys: true}, new(SyncForeignKeyOrder2))
+	assert.NoError(t, err)
+	assert.Len(t, getForeignKeysOfTableFromDB(t, "sync_fk_order"), 0)
+
+	count, err := testEngine.Count(new(SyncForeignKeyOrder2))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncForeignKeyOrder1))
+	assert.NoError(t, err)
+	assert.Len(t, getForeignKeysOfTableFromDB(t, "sync_fk_order"), 1)
+}
+
+type SyncForeignKeyNative struct {
+	Id      int64
+	UserId  int64
+	OwnerId int64
+	Name    string `xorm:"notnull default('')"`
+}
+
+func (*SyncForeignKeyNative) TableName() string {
+	return "sync_fk_native"
+}
+
+func TestSyncNativeForeignKeys(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncForeignKeyNative), new(SyncForeignKeyUser)))
+	assert.NoError(t, testEngine.Sync(new(SyncForeignKeyUser)))
+
+	// the foreign keys are named by database or the user but not xorm
+	quote := testEngine.Quote
+	userTable := quote(testEngine.TableName("sync_fk_user", true))
+	defs := []string{
+		"id INTEGER PRIMARY KEY",
+		"user_id INTEGER",
+		"owner_id INTEGER",
+		"name VARCHAR(255)",
+		"FOREIGN KEY (user_id) REFERENCES " + userTable + " (id) ON DELETE CASCADE",
+		"CONSTRAINT native_owner FOREIGN KEY (owner_id) REFERENCES " + userTable + " (id)",
+	}
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		defs = append(defs[:1], append([]string{"parent_id INTEGER REFERENCES sync_fk_user(id) ON DELETE CASCADE"}, defs[1:]...)...)
+	}
+	_, err := testEngine.Exec(fmt.Sprintf("CREATE TABLE %s (%s)",
+		quote(testEngine.TableName("sync_fk_native", true)), strings.Join(defs, ", ")))
+	assert.NoError(t, err)
+	oriFKs := getForeignKeysOfTableFromDB(t, "sync_fk_native")
+	assert.Len(t, oriFKs, len(defs)-4)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncForeignKeyNative))
+	assert.NoError(t, err)
+	for _, action := range result.Actions {
+		assert.NotEqualValues(t, xorm.SyncDropForeignKey, action.Type)
+		assert.NotEqualValues(t, xorm.SyncRebuildTable, action.Type)
+	}
+	assert.EqualValues(t, oriFKs, getForeignKeysOfTableFromDB(t, "sync_fk_native"))
+
+	// the foreign keys not named by xorm are kept even if the table is rebuilt
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{
+		DropForeignKeys:  true,
+		ReconcileColumns: true,
+	}, new(SyncForeignKeyNative))
+	assert.NoError(t, err)
+	assert.EqualValues(t, oriFKs, getForeignKeysOfTableFromDB(t, "sync_fk_native"))
+}
+
+func TestDumpForeignKeys(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncForeignKeyOrder1), new(SyncForeignKeyUser)))
+	assert.NoError(t, testEngine.CreateTables(new(SyncForeignKeyOrder1), new(SyncForeignKeyUser)))
+
+	fp := fmt.Sprintf("%v-fk.sql", testEngine.Dialect().URI().DBType)
+	os.Remove(fp)
+	assert.NoError(t, testEngine.DumpAllToFile(fp))
+	bs, err := os.ReadFile(fp)
+	assert.NoError(t, err)
+	dump := string(bs)
+	assert.Contains(t, dump, "FOREIGN KEY")
+	// the referenced table should be dumped at first
+	assert.Less(t, strings.Index(dump, "sync_fk_user"), strings.Index(dump, "sync_fk_order"))
+}
+
+type SyncCycleA struct {
+	Id  int64
+	BId int64 `xorm:"fk(sync_cycle_b.id)"`
+}
+
+func (*SyncCycleA) TableName() string {
+	return "sync_cycle_a"
+}
+
+type SyncCycleB struct {
+	Id  int64
+	AId int64 `xorm:"fk(sync_cycle_a.id)"`
+}
+
+func (*SyncCycleB) TableName() string {
+	return "sync_cycle_b"
+}
+
+func dropCycleTables(t *testing.T) {
+	// break the circle at first, the foreign key may not exist
+	fk := schemas.NewForeignKey("a_id", "sync_cycle_a")
+	if sql, err := testEngine.Dialect().DropForeignKeySQL(testEngine.TableName("sync_cycle_b", true), fk); err == nil {
+		_, _ = testEngine.Exec(sql)
+	}
+	assert.NoError(t, testEngine.DropTables(new(SyncCycleA), new(SyncCycleB)))
+}
+
+func TestCircularForeignKeys(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	dropCycleTables(t)
+
+	assert.NoError(t, testEngine.CreateTables(new(SyncCycleA), new(SyncCycleB)))
+	assert.Len(t, getForeignKeysOfTableFromDB(t, "sync_cycle_a"), 1)
+	assert.Len(t, getForeignKeysOfTableFromDB(t, "sync_cycle_b"), 1)
+	dropCycleTables(t)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncCycleA), new(SyncCycleB))
+	assert.NoError(t, err)
+	assert.Len(t, getForeignKeysOfTableFromDB(t, "sync_cycle_a"), 1)
+	assert.Len(t, getForeignKeysOfTableFromDB(t, "sync_cycle_b"), 1)
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		// the foreign key closing the circle is added after the tables created
+		last := result.Actions[len(result.Actions)-1]
+		assert.EqualValues(t, xorm.SyncAddForeignKey, last.Type)
+		assert.EqualValues(t, testEngine.TableName("sync_cycle_b", true), last.Table)
+	}
+
+	result, err = testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncCycleA), new(SyncCycleB))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Actions)
+	dropCycleTables(t)
+}
+
+type SyncCheck1 struct {
+	Id    int64
+	Price int64 `xorm:"check('price >= 0')"`
+}
+
+func (SyncCheck1) TableName() string {
+	return "sync_check"
+}
+
+type SyncCheck2 struct {
+	Id    int64
+	Price int64
+}
+
+func (SyncCheck2) TableName() string {
+	return "sync_check"
+}
+
+func getChecksOfTableFromDB(t *testing.T, tableName string) map[string]*schemas.Check {
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if table.Name == tableName {
+			return table.Checks
+		}
+	}
+	assert.Fail(t, "table not found", tableName)
+	return nil
+}
+
+func TestSyncChecks(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncCheck1)))
+	assert.NoError(t, testEngine.Sync(new(SyncCheck1)))
+
+	checks := getChecksOfTableFromDB(t, "sync_check")
+	assert.Len(t, checks, 1)
+	for name := range checks {
+		assert.EqualValues(t, "chk_sync_check_price", strings.ToLower(name))
+	}
+
+	_, err := testEngine.Insert(&SyncCheck1{Price: 1})
+	assert.NoError(t, err)
+	_, err = testEngine.Insert(&SyncCheck1{Price: -1})
+	assert.Error(t, err)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncCheck1))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Actions)
+
+	// the checks are only dropped if DropChecks
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncCheck2))
+	assert.NoError(t, err)
+	assert.Len(t, getChecksOfTableFromDB(t, "sync_check"), 1)
+
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{DropChecks: true}, new(SyncCheck2))
+	assert.NoError(t, err)
+	assert.Len(t, getChecksOfTableFromDB(t, "sync_check"), 0)
+
+	_, err = testEngine.Insert(&SyncCheck2{Price: -1})
+	assert.NoError(t, err)
+	count, err := testEngine.Count(new(SyncCheck2))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
+type SyncCheckNative struct {
+	Id    int64
+	Price int64
+	Count int64
+	Name  string `xorm:"notnull default('')"`
+}
+
+func (SyncCheckNative) TableName() string {
+	return "sync_check_native"
+}
+
+func TestSyncNativeChecks(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncCheckNative)))
+
+	// the checks are named by database or the user but not xorm
+	_, err := testEngine.Exec(fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY, price INTEGER CHECK (price < 1000), count INTEGER, "+
+		"name VARCHAR(255), CHECK (price >= 0), CONSTRAINT native_count CHECK (count >= 0))",
+		testEngine.Quote(testEngine.TableName("sync_check_native", true))))
+	assert.NoError(t, err)
+	assert.Len(t, getChecksOfTableFromDB(t, "sync_check_native"), 3)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncCheckNative))
+	assert.NoError(t, err)
+	for _, action := range result.Actions {
+		assert.NotEqualValues(t, xorm.SyncDropCheck, action.Type)
+		assert.NotEqualValues(t, xorm.SyncRebuildTable, action.Type)
+	}
+
+	// the checks not named by xorm are kept even if the table is rebuilt
+	_, err = testEngine.SyncWithOptions(xorm.SyncOptions{
+		DropChecks:       true,
+		ReconcileColumns: true,
+	}, new(SyncCheckNative))
+	assert.NoError(t, err)
+	assert.Len(t, getChecksOfTableFromDB(t, "sync_check_native"), 3)
+
+	_, err = testEngine.Insert(&SyncCheckNative{Price: -1})
+	assert.Error(t, err)
+	_, err = testEngine.Insert(&SyncCheckNative{Price: 1000})
+	assert.Error(t, err)
+	_, err = testEngine.Insert(&SyncCheckNative{Count: -1})
+	assert.Error(t, err)
+}
+
+type SyncRenameOld struct {
+	Id   int64
+	Name string `xorm:"index"`
+}
+
+func (SyncRenameOld) TableName() string {
+	return "sync_rename_old"
+}
+
+type SyncRenameNew struct {
+	Id       int64
+	Nickname string `xorm:"index renamed_from(name)"`
+}
+
+func (SyncRenameNew) TableName() string {
+	return "sync_rename_new"
+}
+
+func (SyncRenameNew) TableRenamedFrom() string {
+	return "sync_rename_old"
+}
+
+func TestSyncRenamed(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncRenameOld), new(SyncRenameNew)))
+	assert.NoError(t, testEngine.Sync(new(SyncRenameOld)))
+
+	_, err := testEngine.Insert(&SyncRenameOld{Name: "a"})
+	assert.NoError(t, err)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncRenameNew))
+	assert.NoError(t, err)
+	var types []xorm.SyncActionType
+	for _, action := range result.Actions {
+		types = append(types, action.Type)
+	}
+	assert.EqualValues(t, []xorm.SyncActionType{xorm.SyncRenameTable, xorm.SyncRenameColumn}, types)
+
+	exist, err := testEngine.IsTableExist("sync_rename_old")
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	var beans []SyncRenameNew
+	assert.NoError(t, testEngine.Find(&beans))
+	if assert.Len(t, beans, 1) {
+		assert.EqualValues(t, "a", beans[0].Nickname)
+	}
+
+	result, err = testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncRenameNew))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Actions)
+}
+
+type SyncViewBase struct {
+	Id      int64
+	Name    string
+	Deleted bool
+}
+
+type SyncView struct {
+	Id   int64
+	Name string
+}
+
+func (SyncView) ViewDefinition() string {
+	return "SELECT id, name FROM sync_view_base WHERE deleted = 0"
+}
+
+func TestSyncView(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncView), new(SyncViewBase)))
+	// the view is sorted behind the table it selects from
+	assert.NoError(t, testEngine.Sync(new(SyncView), new(SyncViewBase)))
+
+	_, err := testEngine.Insert([]SyncViewBase{{Name: "a"}, {Name: "b", Deleted: true}})
+	assert.NoError(t, err)
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	var view *schemas.Table
+	for _, table := range tables {
+		if table.Name == "sync_view" {
+			view = table
+		}
+	}
+	if assert.NotNil(t, view) {
+		assert.True(t, view.IsView)
+	}
+
+	var beans []SyncView
+	assert.NoError(t, testEngine.Find(&beans))
+	if assert.Len(t, beans, 1) {
+		assert.EqualValues(t, "a", beans[0].Name)
+	}
+
+	_, err = testEngine.Insert(&SyncView{Name: "c"})
+	assert.ErrorIs(t, err, xorm.ErrViewReadOnly)
+	_, err = testEngine.ID(1).Update(&SyncView{Name: "c"})
+	assert.ErrorIs(t, err, xorm.ErrViewReadOnly)
+	_, err = testEngine.ID(1).Delete(new(SyncView))
+	assert.ErrorIs(t, err, xorm.ErrViewReadOnly)
+
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncView), new(SyncViewBase))
+		assert.NoError(t, err)
+		assert.Empty(t, result.Actions)
+
+		assert.ErrorIs(t, testEngine.RefreshMaterializedView(new(SyncView), false), xorm.ErrMaterializedViewUnsupported)
+	}
+
+	assert.NoError(t, testEngine.DropTables(new(SyncView)))
+	exist, err := testEngine.IsTableExist(new(SyncViewBase))
+	assert.NoError(t, err)
+	assert.True(t, exist)
+}
+
+type SyncGenerated struct {
+	Id    int64
+	Price int64
+	Qty   int64
+	Total int64 `xorm:"generated('price * qty') stored"`
+}
+
+type SyncGenerated2 struct {
+	Id    int64
+	Price int64
+	Qty   int64
+	Total int64 `xorm:"generated('price * qty') stored"`
+	Half  int64 `xorm:"generated('price / 2')"`
+}
+
+func (SyncGenerated2) TableName() string {
+	return "sync_generated"
+}
+
+func TestSyncGenerated(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncGenerated)))
+	assert.NoError(t, testEngine.Sync(new(SyncGenerated)))
+
+	bean := SyncGenerated{Price: 3, Qty: 2, Total: 100}
+	_, err := testEngine.Insert(&bean)
+	assert.NoError(t, err)
+
+	var result SyncGenerated
+	has, err := testEngine.ID(bean.Id).Get(&result)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 6, result.Total)
+
+	_, err = testEngine.ID(bean.Id).AllCols().Update(&SyncGenerated{Price: 4, Qty: 2, Total: 100})
+	assert.NoError(t, err)
+	var result1 SyncGenerated
+	has, err = testEngine.ID(bean.Id).Get(&result1)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 8, result1.Total)
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if table.Name == "sync_generated" {
+			col := table.GetColumn("total")
+			if assert.NotNil(t, col) {
+				assert.True(t, col.IsGenerated())
+			}
+			assert.False(t, table.GetColumn("price").IsGenerated())
+		}
+	}
+
+	// the virtual column can be added to an existing table
+	syncResult, err := testEngine.SyncWithOptions(xorm.SyncOptions{ReconcileColumns: true}, new(SyncGenerated2))
+	assert.NoError(t, err)
+	if assert.Len(t, syncResult.Actions, 1) {
+		assert.EqualValues(t, xorm.SyncAddColumn, syncResult.Actions[0].Type)
+	}
+
+	var result2 SyncGenerated2
+	has, err = testEngine.ID(bean.Id).Get(&result2)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 2, result2.Half)
+
+	syncResult, err = testEngine.SyncWithOptions(xorm.SyncOptions{ReconcileColumns: true}, new(SyncGenerated2))
+	assert.NoError(t, err)
+	assert.Empty(t, syncResult.Actions)
+}
+
+type SyncPartitioned struct {
+	Id      int64
+	Created time.Time `xorm:"pk"`
+}
+
+func (SyncPartitioned) TablePartitioning() *schemas.Partitioning {
+	partitioning := schemas.NewPartitioning(schemas.RangePartition, "created")
+	partitioning.AddPartition(schemas.NewRangePartition("sync_partitioned_2023", "'2023-01-01'", "'2024-01-01'"))
+	return partitioning
+}
+
+func TestSyncPartitioned(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.DropTables(new(SyncPartitioned)))
+	assert.NoError(t, testEngine.Sync(new(SyncPartitioned)))
+
+	_, err := testEngine.Insert(&SyncPartitioned{Id: 1, Created: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, new(SyncPartitioned))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Actions)
+
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.POSTGRES, schemas.MYSQL:
+		partitions, err := testEngine.GetPartitions(new(SyncPartitioned))
+		assert.NoError(t, err)
+		if assert.Len(t, partitions, 1) {
+			assert.EqualValues(t, "sync_partitioned_2023", partitions[0].Name)
+		}
+	default:
+		_, err = testEngine.GetPartitions(new(SyncPartitioned))
+		assert.ErrorIs(t, err, xorm.ErrPartitionUnsupported)
+		assert.ErrorIs(t, testEngine.CreatePartition(new(SyncPartitioned), schemas.NewRangePartition("sync_partitioned_2024", "'2024-01-01'", "'2025-01-01'")),
+			xorm.ErrPartitionUnsupported)
+	}
+}
+
+func TestSyncRevertSQLs(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.Sync(new(SyncDryRun1)))
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, &SyncDryRun2{})
+	assert.NoError(t, err)
+	sqls, irreversible := result.RevertSQLs()
+	assert.Len(t, sqls, 3)
+	assert.Empty(t, irreversible)
+
+	for _, sql := range sqls {
+		_, err = testEngine.Exec(sql)
+		assert.NoError(t, err)
+	}
+	indices := getIndicesOfBeanFromDB(t, &SyncDryRun1{})
+	assert.ElementsMatch(t, []string{"name"}, getKeysFromMap(indices))
+	result, err = testEngine.SyncWithOptions(xorm.SyncOptions{DryRun: true}, &SyncDryRun1{})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Actions)
+}
+
 func getIndicesOfBeanFromDB(t *testing.T, bean interface{}) map[string]*schemas.Index {
 	dbm, err := testEngine.DBMetas()
 	assert.NoError(t, err)
@@ -0,0 +1,88 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+	"xorm.io/xorm/schemas"
+)
+
+type CompoundUser struct {
+	Id      int64
+	Name    string
+	Age     int
+	Deleted time.Time `xorm:"deleted"`
+}
+
+func TestCompound(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(CompoundUser))
+
+	users := []CompoundUser{
+		{Name: "a", Age: 10},
+		{Name: "b", Age: 20},
+		{Name: "c", Age: 30},
+		{Name: "d", Age: 40},
+		{Name: "e", Age: 50},
+	}
+	for i := range users {
+		_, err := testEngine.Insert(&users[i])
+		assert.NoError(t, err)
+	}
+	_, err := testEngine.ID(users[4].Id).Delete(new(CompoundUser))
+	assert.NoError(t, err)
+
+	young := func() *xorm.Session { return testEngine.Table(new(CompoundUser)).Where("age < ?", 25) }
+	old := func() *xorm.Session { return testEngine.Table(new(CompoundUser)).Where("age > ?", 35) }
+
+	// the soft deleted rows are excluded by the queries
+	var got []CompoundUser
+	assert.NoError(t, testEngine.Union(young(), old()).Desc("id").Find(&got))
+	assert.Len(t, got, 3)
+	assert.EqualValues(t, []string{"d", "b", "a"}, []string{got[0].Name, got[1].Name, got[2].Name})
+
+	// the pagination is applied to the combined rows
+	got = nil
+	assert.NoError(t, testEngine.UnionAll(young(), old(), young()).Asc("age").Limit(2, 1).Find(&got))
+	assert.Len(t, got, 2)
+	assert.EqualValues(t, []int{10, 20}, []int{got[0].Age, got[1].Age})
+
+	cnt, err := testEngine.UnionAll(young(), old(), young()).Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, cnt)
+
+	// the conditions are applied to the combined rows, and the queries with pagination are queried as tables
+	got = nil
+	assert.NoError(t, testEngine.Union(young().Desc("age").Limit(1), old()).Where("name <> ?", "d").Find(&got))
+	assert.Len(t, got, 1)
+	assert.EqualValues(t, "b", got[0].Name)
+
+	var user CompoundUser
+	has, err := testEngine.Union(young(), old()).Asc("age").Get(&user)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "a", user.Name)
+
+	if testEngine.Dialect().URI().DBType == schemas.MYSQL {
+		// MySQL 8.0.31+ is required for INTERSECT and EXCEPT
+		t.Skip()
+	}
+
+	all := func() *xorm.Session { return testEngine.Table(new(CompoundUser)) }
+	got = nil
+	assert.NoError(t, testEngine.Except(all(), young()).Intersect(old()).Find(&got))
+	assert.Len(t, got, 1)
+	assert.EqualValues(t, "d", got[0].Name)
+
+	// the queries are combined from left to right whatever the precedences of the operators are
+	got = nil
+	assert.NoError(t, testEngine.Union(young(), old()).Intersect(old()).Find(&got))
+	assert.Len(t, got, 1)
+	assert.EqualValues(t, "d", got[0].Name)
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/builder"
+	"xorm.io/xorm/schemas"
+)
+
+type CteCategory struct {
+	Id       int64
+	ParentId int64
+	Name     string
+	Deleted  time.Time `xorm:"deleted"`
+}
+
+func TestWithCTE(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(CteCategory))
+
+	categories := []CteCategory{
+		{Id: 1, ParentId: 0, Name: "root"},
+		{Id: 2, ParentId: 1, Name: "a"},
+		{Id: 3, ParentId: 1, Name: "b"},
+		{Id: 4, ParentId: 2, Name: "a1"},
+		{Id: 5, ParentId: 0, Name: "other"},
+	}
+	for i := range categories {
+		_, err := testEngine.Insert(&categories[i])
+		assert.NoError(t, err)
+	}
+	_, err := testEngine.ID(3).Delete(new(CteCategory))
+	assert.NoError(t, err)
+
+	// the soft deleted rows are excluded from the expression
+	children := testEngine.Table(new(CteCategory)).Where("parent_id = ?", 1)
+	var got []CteCategory
+	assert.NoError(t, testEngine.With("children", children).Table("children").Find(&got))
+	assert.Len(t, got, 1)
+	assert.EqualValues(t, "a", got[0].Name)
+
+	cnt, err := testEngine.With("children", testEngine.Table(new(CteCategory)).Where("parent_id = ?", 1)).
+		Table("children").Count(new(CteCategory))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	has, err := testEngine.With("children", testEngine.Table(new(CteCategory)).Where("parent_id = ?", 1)).
+		Table("children").Where("name = ?", "b").Exist(new(CteCategory))
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	// the expression could be joined
+	got = nil
+	assert.NoError(t, testEngine.With("children", builder.Select("id").From(testEngine.TableName(new(CteCategory), true)).Where(builder.Eq{"parent_id": 2})).
+		Join("INNER", "children", "children.id = cte_category.id").
+		Find(&got))
+	assert.Len(t, got, 1)
+	assert.EqualValues(t, "a1", got[0].Name)
+
+	if testEngine.Dialect().URI().DBType == schemas.MYSQL {
+		// MySQL 8.0+ is required for the recursive query
+		t.Skip()
+	}
+
+	tableName := testEngine.Quote(testEngine.TableName(new(CteCategory), true))
+	tree := testEngine.SQL("SELECT id, parent_id, name, deleted FROM "+tableName+" WHERE id = ? UNION ALL "+
+		"SELECT c.id, c.parent_id, c.name, c.deleted FROM "+tableName+" c INNER JOIN tree ON c.parent_id = tree.id", 1)
+	got = nil
+	assert.NoError(t, testEngine.WithRecursive("tree(id,parent_id,name,deleted)", tree).
+		Table("tree").Asc("id").Find(&got))
+	assert.Len(t, got, 3)
+	assert.EqualValues(t, []string{"root", "a", "a1"}, []string{got[0].Name, got[1].Name, got[2].Name})
+}
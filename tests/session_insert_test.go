@@ -1372,3 +1372,196 @@ func TestAutoTimeFields(t *testing.T) {
 	assert.NoError(t, testEngine.Find(&res))
 	assert.EqualValues(t, 2, len(res))
 }
+
+type UpsertUser struct {
+	Id      int64
+	Name    string `xorm:"unique"`
+	Email   string
+	Created time.Time `xorm:"created"`
+	Updated time.Time `xorm:"updated"`
+	Version int       `xorm:"version"`
+}
+
+func TestUpsert(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(UpsertUser))
+
+	user := UpsertUser{Name: "lunny", Email: "lunny@example.com"}
+	cnt, err := testEngine.OnConflict("name").Insert(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	assert.Greater(t, user.Id, int64(0))
+
+	var before UpsertUser
+	has, err := testEngine.ID(user.Id).Get(&before)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 1, before.Version)
+
+	_, err = testEngine.OnConflict("name").Insert(&UpsertUser{Name: "lunny", Email: "lunny@xorm.io"})
+	assert.NoError(t, err)
+
+	var after UpsertUser
+	has, err = testEngine.ID(user.Id).Get(&after)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "lunny@xorm.io", after.Email)
+	assert.EqualValues(t, 2, after.Version)
+	assert.EqualValues(t, before.Created.Unix(), after.Created.Unix())
+
+	cnt, err = testEngine.OnConflict("name").DoNothing().Insert(&UpsertUser{Name: "lunny", Email: "nobody@xorm.io"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	_, err = testEngine.OnConflict("name").Insert([]UpsertUser{
+		{Name: "lunny", Email: "lunny@gitea.io"},
+		{Name: "xiaolunwen", Email: "xiaolunwen@gitea.io"},
+	})
+	assert.NoError(t, err)
+
+	var users []UpsertUser
+	assert.NoError(t, testEngine.Asc("name").Find(&users))
+	assert.Len(t, users, 2)
+	assert.EqualValues(t, "lunny@gitea.io", users[0].Email)
+	assert.EqualValues(t, 3, users[0].Version)
+	assert.EqualValues(t, "xiaolunwen@gitea.io", users[1].Email)
+}
+
+type InsertMultiID struct {
+	Id       int64
+	Name     string
+	insertID int64 `xorm:"-"`
+}
+
+func (i *InsertMultiID) AfterInsert() {
+	i.insertID = i.Id
+}
+
+func TestInsertMultiIDs(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(InsertMultiID))
+
+	assertIDs := func(beans []*InsertMultiID) {
+		for _, bean := range beans {
+			assert.Greater(t, bean.Id, int64(0))
+			assert.EqualValues(t, bean.Id, bean.insertID)
+
+			var record InsertMultiID
+			has, err := testEngine.ID(bean.Id).Get(&record)
+			assert.NoError(t, err)
+			assert.True(t, has)
+			assert.EqualValues(t, bean.Name, record.Name)
+		}
+	}
+
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.ORACLE, schemas.DAMENG:
+		t.Skip()
+	case schemas.MYSQL:
+		// the ids may be not consecutive in the interleaved lock mode, so they are not assigned
+		var lockMode int
+		_, err := testEngine.SQL("SELECT @@innodb_autoinc_lock_mode").Get(&lockMode)
+		assert.NoError(t, err)
+		if lockMode == 2 {
+			beans := []InsertMultiID{{Name: "a"}, {Name: "b"}}
+			cnt, err := testEngine.Insert(&beans)
+			assert.NoError(t, err)
+			assert.EqualValues(t, 2, cnt)
+			assert.EqualValues(t, 0, beans[0].Id)
+			assert.EqualValues(t, 0, beans[1].Id)
+			return
+		}
+	}
+
+	// the ids are returned by OUTPUT only if Returning is called on MSSQL
+	isMSSQL := testEngine.Dialect().URI().DBType == schemas.MSSQL
+	newSession := func() *xorm.Session {
+		if isMSSQL {
+			return testEngine.Returning("id")
+		}
+		return testEngine.NewSession()
+	}
+
+	beans := []InsertMultiID{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	cnt, err := newSession().Insert(&beans)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+	assertIDs([]*InsertMultiID{&beans[0], &beans[1], &beans[2]})
+
+	// the returning of the slice is not kept for the following bean
+	beans = []InsertMultiID{{Name: "x"}, {Name: "y"}}
+	bean := InsertMultiID{Name: "z"}
+	cnt, err = newSession().Insert(&beans, &bean)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+	assertIDs([]*InsertMultiID{&beans[0], &beans[1], &bean})
+
+	session := newSession()
+	defer session.Close()
+
+	ptrs := []*InsertMultiID{{Name: "d"}, {Name: "e"}}
+	cnt, err = session.InsertMulti(&ptrs)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+	assertIDs(ptrs)
+	assert.EqualValues(t, bean.Id+1, ptrs[0].Id)
+
+	assert.NoError(t, session.Begin())
+	ptrs = []*InsertMultiID{{Name: "f"}, {Name: "g"}}
+	if isMSSQL {
+		session.Returning("id")
+	}
+	cnt, err = session.Insert(ptrs)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+	assert.NoError(t, session.Commit())
+	assertIDs(ptrs)
+}
+
+type InsertMultiChunk struct {
+	Id    int64
+	Name  string
+	Age   int
+	Score int
+}
+
+func TestInsertMultiChunks(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(InsertMultiChunk))
+
+	// there are more parameters than the limit of any database
+	const size = 25000
+	beans := make([]*InsertMultiChunk, 0, size)
+	for i := 0; i < size; i++ {
+		beans = append(beans, &InsertMultiChunk{Name: fmt.Sprintf("name%d", i), Age: i % 100, Score: i})
+	}
+	cnt, err := testEngine.Insert(&beans)
+	assert.NoError(t, err)
+	assert.EqualValues(t, size, cnt)
+
+	total, err := testEngine.Count(new(InsertMultiChunk))
+	assert.NoError(t, err)
+	assert.EqualValues(t, size, total)
+
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.ORACLE, schemas.DAMENG:
+	default:
+		var last InsertMultiChunk
+		has, err := testEngine.ID(beans[size-1].Id).Get(&last)
+		assert.NoError(t, err)
+		assert.True(t, has)
+		assert.EqualValues(t, size-1, last.Score)
+	}
+
+	maps := make([]map[string]interface{}, 0, size)
+	for i := 0; i < size; i++ {
+		maps = append(maps, map[string]interface{}{"name": fmt.Sprintf("map%d", i), "age": 1, "score": i})
+	}
+	cnt, err = testEngine.Table(new(InsertMultiChunk)).Insert(maps)
+	assert.NoError(t, err)
+	assert.EqualValues(t, size, cnt)
+
+	total, err = testEngine.Where("age = ?", 1).Count(new(InsertMultiChunk))
+	assert.NoError(t, err)
+	assert.EqualValues(t, size/100+size, total)
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+)
+
+type KeysetUser struct {
+	Id    int64
+	Name  string
+	Score int
+}
+
+func TestFindPage(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(KeysetUser))
+
+	for i := 0; i < 25; i++ {
+		_, err := testEngine.Insert(&KeysetUser{Name: "user", Score: i % 4})
+		assert.NoError(t, err)
+	}
+
+	// the rows are ordered by the primary key by default
+	var users []KeysetUser
+	var cursor *xorm.Cursor
+	pages := 0
+	for {
+		var page []KeysetUser
+		var err error
+		cursor, err = testEngine.Where("name = ?", "user").FindPage(&page, 10, cursor)
+		assert.NoError(t, err)
+		users = append(users, page...)
+		pages++
+		if cursor == nil {
+			break
+		}
+		// the cursor could be passed as a string
+		cursor, err = xorm.ParseCursor(cursor.String())
+		assert.NoError(t, err)
+	}
+	assert.EqualValues(t, 3, pages)
+	assert.Len(t, users, 25)
+	for i, user := range users {
+		assert.EqualValues(t, i+1, user.Id)
+	}
+
+	// the primary key is appended to the orders which are not unique
+	var first []*KeysetUser
+	cursor, err := testEngine.Desc("score").FindPage(&first, 10, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, cursor)
+	assert.Len(t, first, 10)
+	assert.EqualValues(t, 3, first[0].Score)
+	assert.EqualValues(t, 4, first[0].Id)
+
+	// the rows of the previous pages don't change the next page
+	_, err = testEngine.ID(first[0].Id).Delete(new(KeysetUser))
+	assert.NoError(t, err)
+	var second []*KeysetUser
+	cursor, err = testEngine.Desc("score").FindPage(&second, 10, cursor)
+	assert.NoError(t, err)
+	assert.NotNil(t, cursor)
+	assert.Len(t, second, 10)
+	assert.EqualValues(t, 2, second[0].Score)
+	assert.EqualValues(t, 19, second[0].Id)
+
+	// the cursor is bound to the orders
+	_, err = testEngine.Asc("score").FindPage(&second, 10, cursor)
+	assert.EqualValues(t, xorm.ErrInvalidCursor, err)
+	_, err = xorm.ParseCursor("invalid")
+	assert.EqualValues(t, xorm.ErrInvalidCursor, err)
+
+	_, err = testEngine.OrderBy("score DESC").FindPage(&second, 10, nil)
+	assert.EqualValues(t, xorm.ErrKeysetUnsupported, err)
+}
+
+func TestBufferIterateKeyset(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(KeysetUser))
+
+	for i := 0; i < 20; i++ {
+		_, err := testEngine.Insert(&KeysetUser{Name: "user", Score: i})
+		assert.NoError(t, err)
+	}
+
+	// the pages are queried by keyset, so deleting the iterated rows doesn't skip any rows
+	var ids []int64
+	err := testEngine.Desc("id").BufferSize(3).Iterate(new(KeysetUser), func(i int, bean interface{}) error {
+		user := bean.(*KeysetUser)
+		ids = append(ids, user.Id)
+		_, err := testEngine.ID(user.Id).Delete(new(KeysetUser))
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ids, 20)
+	for i, id := range ids {
+		assert.EqualValues(t, 20-i, id)
+	}
+}
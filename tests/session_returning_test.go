@@ -0,0 +1,95 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+	"xorm.io/xorm/schemas"
+)
+
+type ReturningUser struct {
+	Id      int64
+	Name    string
+	Status  int `xorm:"<- notnull default 3"`
+	Version int `xorm:"version"`
+}
+
+func TestReturning(t *testing.T) {
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.MYSQL, schemas.ORACLE, schemas.DAMENG:
+		t.Skip()
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(ReturningUser))
+
+	user := ReturningUser{Name: "lunny"}
+	cnt, err := testEngine.Returning().Insert(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	assert.Greater(t, user.Id, int64(0))
+	assert.EqualValues(t, 3, user.Status)
+	assert.EqualValues(t, 1, user.Version)
+
+	users := []ReturningUser{{Name: "a"}, {Name: "b"}}
+	cnt, err = testEngine.Returning("status").Insert(&users)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+	for _, u := range users {
+		assert.Greater(t, u.Id, user.Id)
+		assert.EqualValues(t, 3, u.Status)
+	}
+	assert.NotEqual(t, users[0].Id, users[1].Id)
+
+	_, err = testEngine.Exec("UPDATE returning_user SET status = 5 WHERE id = ?", user.Id)
+	assert.NoError(t, err)
+
+	updated := ReturningUser{Name: "xiaolunwen", Version: 1}
+	cnt, err = testEngine.ID(user.Id).Returning().Update(&updated)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	assert.EqualValues(t, user.Id, updated.Id)
+	assert.EqualValues(t, 5, updated.Status)
+	assert.EqualValues(t, 2, updated.Version)
+
+	// the version is not increased if the row of the version is not updated
+	stale := ReturningUser{Name: "stale", Version: 1}
+	cnt, err = testEngine.ID(user.Id).Returning("status").Update(&stale)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+	assert.EqualValues(t, 1, stale.Version)
+
+	var renamed []*ReturningUser
+	cnt, err = testEngine.Table(new(ReturningUser)).Where("status = ?", 3).ReturningInto(&renamed).Update(map[string]interface{}{"name": "c"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+	assert.Len(t, renamed, 2)
+	for _, u := range renamed {
+		assert.EqualValues(t, "c", u.Name)
+	}
+
+	var deleted []ReturningUser
+	cnt, err = testEngine.Where("status = ?", 3).Returning("id", "name").ReturningInto(&deleted).Delete(new(ReturningUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+	assert.Len(t, deleted, 2)
+	for _, u := range deleted {
+		assert.EqualValues(t, "c", u.Name)
+		assert.EqualValues(t, 0, u.Status)
+	}
+
+	var one ReturningUser
+	cnt, err = testEngine.ID(user.Id).Returning().Delete(&one)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	assert.EqualValues(t, "xiaolunwen", one.Name)
+	assert.EqualValues(t, 5, one.Status)
+
+	_, err = testEngine.Table(new(ReturningUser)).Returning().Insert(map[string]interface{}{"name": "d"})
+	assert.ErrorIs(t, err, xorm.ErrReturningMap)
+}
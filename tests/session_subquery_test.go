@@ -0,0 +1,95 @@
+// Copyright 2023 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
+)
+
+type SubQueryUser struct {
+	Id      int64
+	Name    string
+	Deleted time.Time `xorm:"deleted"`
+}
+
+type SubQueryOrder struct {
+	Id      int64
+	UserId  int64
+	Amount  int
+	Deleted time.Time `xorm:"deleted"`
+}
+
+func TestSubQuery(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SubQueryUser), new(SubQueryOrder))
+
+	users := []SubQueryUser{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	for i := range users {
+		_, err := testEngine.Insert(&users[i])
+		assert.NoError(t, err)
+	}
+	orders := []SubQueryOrder{
+		{UserId: users[0].Id, Amount: 5},
+		{UserId: users[0].Id, Amount: 50},
+		{UserId: users[1].Id, Amount: 20},
+		{UserId: users[2].Id, Amount: 30},
+	}
+	for i := range orders {
+		_, err := testEngine.Insert(&orders[i])
+		assert.NoError(t, err)
+	}
+	// the soft deleted rows are excluded by the subqueries
+	_, err := testEngine.ID(orders[3].Id).Delete(new(SubQueryOrder))
+	assert.NoError(t, err)
+
+	bigOrders := func() *xorm.Session {
+		return testEngine.Table(new(SubQueryOrder)).Cols("user_id").Where("amount > ?", 10)
+	}
+
+	// the arguments are merged in the order of the SQL
+	var got []SubQueryUser
+	assert.NoError(t, testEngine.Where("name <> ?", "x").In("id", bigOrders()).Where("name <> ?", "y").Asc("id").Find(&got))
+	assert.Len(t, got, 2)
+	assert.EqualValues(t, []string{"a", "b"}, []string{got[0].Name, got[1].Name})
+
+	got = nil
+	assert.NoError(t, testEngine.NotIn("id", bigOrders()).Find(&got))
+	assert.Len(t, got, 1)
+	assert.EqualValues(t, "c", got[0].Name)
+
+	got = nil
+	assert.NoError(t, testEngine.WhereExists(testEngine.Table(new(SubQueryOrder)).Select("1").
+		Where("amount < ?", 10).And("sub_query_order.user_id = sub_query_user.id")).Find(&got))
+	assert.Len(t, got, 1)
+	assert.EqualValues(t, "a", got[0].Name)
+
+	cnt, err := testEngine.WhereNotExists(testEngine.Table(new(SubQueryOrder)).Select("1").
+		Where("sub_query_order.user_id = sub_query_user.id")).Count(new(SubQueryUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	// the subquery is named by its alias
+	cnt, err = testEngine.Table(new(SubQueryUser)).
+		Join("INNER", testEngine.Table(new(SubQueryOrder)).Alias("o").Where("amount > ?", 10), "o.user_id = sub_query_user.id").
+		Where("sub_query_user.name = ?", "a").Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	// the subquery is named by its table name
+	var gotOrders []SubQueryOrder
+	assert.NoError(t, testEngine.Table(testEngine.Table(new(SubQueryOrder)).Where("amount > ?", 10)).
+		Where("amount < ?", 30).Find(&gotOrders))
+	assert.Len(t, gotOrders, 1)
+	assert.EqualValues(t, 20, gotOrders[0].Amount)
+
+	has, err := testEngine.Table(testEngine.Table(new(SubQueryOrder)).Where("amount > ?", 10)).
+		Where("amount = ?", 30).Exist()
+	assert.NoError(t, err)
+	assert.False(t, has)
+}
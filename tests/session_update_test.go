@@ -1505,3 +1505,114 @@ func TestUpdateWithJoin(t *testing.T) {
 		Update(&TestUpdateWithJoin{Name: "test2"})
 	assert.NoError(t, err)
 }
+
+type UpdateMultiUser struct {
+	Id      int64
+	Name    string
+	Email   string
+	Age     int
+	Updated time.Time `xorm:"updated"`
+	Version int       `xorm:"version"`
+}
+
+func TestUpdateMulti(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(UpdateMultiUser))
+
+	users := []UpdateMultiUser{
+		{Name: "a", Email: "a@example.com", Age: 1},
+		{Name: "b", Email: "b@example.com", Age: 2},
+		{Name: "c", Email: "c@example.com", Age: 3},
+	}
+	for i := range users {
+		_, err := testEngine.Insert(&users[i])
+		assert.NoError(t, err)
+	}
+
+	session := testEngine.NewSession()
+	defer session.Close()
+
+	// the empty fields are not updated
+	cnt, err := session.UpdateMulti([]*UpdateMultiUser{
+		{Id: users[0].Id, Name: "a1", Version: users[0].Version},
+		{Id: users[1].Id, Email: "b1@example.com", Version: users[1].Version},
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var got []UpdateMultiUser
+	assert.NoError(t, testEngine.Asc("id").Find(&got))
+	assert.Len(t, got, 3)
+	assert.EqualValues(t, "a1", got[0].Name)
+	assert.EqualValues(t, "a@example.com", got[0].Email)
+	assert.EqualValues(t, "b", got[1].Name)
+	assert.EqualValues(t, "b1@example.com", got[1].Email)
+	assert.EqualValues(t, users[0].Version+1, got[0].Version)
+	assert.EqualValues(t, users[1].Version+1, got[1].Version)
+	assert.EqualValues(t, users[2].Version, got[2].Version)
+
+	// no rows are updated if any of the versions is stale, and the stale beans are reported
+	got[0].Age = 0
+	got[1].Age = 20
+	got[2].Age = 30
+	got[2].Version--
+	cnt, err = session.Cols("age").UpdateMulti(got)
+	assert.EqualValues(t, 0, cnt)
+	var conflict xorm.ErrUpdateMultiConflict
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.EqualValues(t, []interface{}{&got[2]}, conflict.Beans)
+		assert.EqualValues(t, []schemas.PK{{got[2].Id}}, conflict.PKs)
+	}
+	assert.EqualValues(t, users[0].Version+1, got[0].Version)
+	assert.EqualValues(t, users[2].Version-1, got[2].Version)
+
+	var got2 []UpdateMultiUser
+	assert.NoError(t, testEngine.Asc("id").Find(&got2))
+	assert.EqualValues(t, 1, got2[0].Age)
+	assert.EqualValues(t, 2, got2[1].Age)
+	assert.EqualValues(t, 3, got2[2].Age)
+
+	// the versions of the beans are increased
+	got[2].Version++
+	cnt, err = session.Cols("age").UpdateMulti(got)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+	assert.EqualValues(t, users[0].Version+2, got[0].Version)
+	assert.EqualValues(t, users[2].Version+1, got[2].Version)
+	assert.False(t, got[0].Updated.IsZero())
+
+	got2 = nil
+	assert.NoError(t, testEngine.Asc("id").Find(&got2))
+	assert.EqualValues(t, 0, got2[0].Age)
+	assert.EqualValues(t, 20, got2[1].Age)
+	assert.EqualValues(t, 30, got2[2].Age)
+	assert.EqualValues(t, "a1", got2[0].Name)
+
+	// the stale beans are also reported in the transaction of the session
+	assert.NoError(t, session.Begin())
+	stale := []UpdateMultiUser{got2[0], got2[1]}
+	stale[1].Version--
+	_, err = session.Cols("age").UpdateMulti(stale)
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.EqualValues(t, []interface{}{&stale[1]}, conflict.Beans)
+	}
+	assert.NoError(t, session.Rollback())
+
+	// omitted columns are not updated
+	got2[0].Name = "a2"
+	got2[0].Email = "a2@example.com"
+	got2[1].Name = "b2"
+	cnt, err = session.Omit("email").UpdateMulti(got2[:2])
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var user UpdateMultiUser
+	has, err := testEngine.ID(got2[0].Id).Get(&user)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "a2", user.Name)
+	assert.EqualValues(t, "a@example.com", user.Email)
+
+	_, err = session.UpdateMulti([]UpdateMultiUser{{Name: "d"}})
+	assert.EqualValues(t, xorm.ErrUpdateMultiNoPrimaryKey, err)
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"xorm.io/xorm"
 	"xorm.io/xorm/convert"
 	"xorm.io/xorm/internal/utils"
 	"xorm.io/xorm/names"
@@ -1454,3 +1455,51 @@ func TestTableIndices(t *testing.T) {
 		assert.EqualValues(t, []string{"f_two", "f_one"}, index.Cols)
 	}
 }
+
+type TestTableExprIndicesStruct struct {
+	Id      int64
+	Email   string
+	Created int64
+	Deleted int64 `xorm:"null"`
+}
+
+func (t *TestTableExprIndicesStruct) TableIndices() []*schemas.Index {
+	emailIndex := schemas.NewIndex("email", schemas.UniqueType)
+	emailIndex.AddColumn("lower(email)")
+	emailIndex.Where = "deleted IS NULL"
+
+	createdIndex := schemas.NewIndex("created", schemas.IndexType)
+	createdIndex.AddDescColumn("created")
+
+	return []*schemas.Index{emailIndex, createdIndex}
+}
+
+func TestTableExprIndices(t *testing.T) {
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE && testEngine.Dialect().URI().DBType != schemas.POSTGRES {
+		t.Skip("partial and expression indexes are tested on SQLite and Postgres")
+		return
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(TestTableExprIndicesStruct))
+
+	tableName := testEngine.TableName(new(TestTableExprIndicesStruct))
+	indexes := getIndicesOfBeanFromDB(t, new(TestTableExprIndicesStruct))
+	if assert.Len(t, indexes, 2) {
+		assert.True(t, indexes["email"].Equal(new(TestTableExprIndicesStruct).TableIndices()[0]))
+		assert.True(t, indexes["created"].IsDesc("created"))
+	}
+
+	_, err := testEngine.Insert(&TestTableExprIndicesStruct{Email: "a@example.com", Deleted: 1})
+	assert.NoError(t, err)
+	_, err = testEngine.Exec("UPDATE " + testEngine.Quote(tableName) + " SET deleted = NULL")
+	assert.NoError(t, err)
+	_, err = testEngine.Insert(&TestTableExprIndicesStruct{Email: "A@example.com", Deleted: 1})
+	assert.NoError(t, err)
+	_, err = testEngine.Exec("UPDATE " + testEngine.Quote(tableName) + " SET deleted = NULL")
+	assert.Error(t, err)
+
+	result, err := testEngine.SyncWithOptions(xorm.SyncOptions{}, new(TestTableExprIndicesStruct))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Actions)
+}